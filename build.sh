@@ -89,7 +89,8 @@ BuildDev() {
 }
 
 BuildDocker() {
-  go build -o ./bin/alist -ldflags="$ldflags" -tags=jsoniter .
+  # the image is linked dynamically, so the mount command can load libfuse
+  go build -o ./bin/alist -ldflags="$ldflags" -tags=jsoniter,fuse .
 }
 
 PrepareBuildDockerMusl() {
@@ -184,6 +185,19 @@ BuildReleaseLinuxMusl() {
   done
 }
 
+# the static musl builds can't load libfuse, the mount command is only in this dynamically linked build
+BuildReleaseLinuxFuse() {
+  rm -rf .git/
+  mkdir -p "build"
+  sudo apt-get update
+  sudo apt-get install -y gcc libfuse-dev
+  echo building for linux-fuse-amd64
+  export GOOS=linux
+  export GOARCH=amd64
+  export CGO_ENABLED=1
+  go build -o ./build/$appName-linux-fuse-amd64 -ldflags="$ldflags" -tags=jsoniter,fuse .
+}
+
 BuildReleaseLinuxMuslArm() {
   rm -rf .git/
   mkdir -p "build"
@@ -323,6 +337,9 @@ elif [ "$1" = "release" -o "$1" = "beta" ]; then
   elif [ "$2" = "linux_musl" ]; then
     BuildReleaseLinuxMusl
     MakeRelease "md5-linux-musl.txt"
+  elif [ "$2" = "linux_fuse" ]; then
+    BuildReleaseLinuxFuse
+    MakeRelease "md5-linux-fuse.txt"
   elif [ "$2" = "android" ]; then
     BuildReleaseAndroid
     MakeRelease "md5-android.txt"
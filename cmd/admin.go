@@ -77,6 +77,9 @@ func setAdminPassword(pwd string) {
 		utils.Log.Errorf("failed update admin user: %+v", err)
 		return
 	}
+	if err := op.DeleteSessionsByUser(admin.ID); err != nil {
+		utils.Log.Errorf("failed logout admin user: %+v", err)
+	}
 	utils.Log.Infof("admin user has been updated:")
 	utils.Log.Infof("username: %s", admin.Username)
 	utils.Log.Infof("password: %s", pwd)
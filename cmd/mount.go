@@ -0,0 +1,77 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fuse"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mountUser        string
+	mountReadOnly    bool
+	mountAttrTimeout time.Duration
+	mountOptions     []string
+)
+
+// MountCmd represents the mount command
+var MountCmd = &cobra.Command{
+	Use:   "mount <src> <dst>",
+	Short: "Mount a path of AList to a local directory",
+	Long: `Mount a path of AList to a local directory with FUSE,
+src is a path in AList relative to the base path of the user,
+dst is a local directory (or a drive letter on Windows)`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		bootstrap.LoadStorages()
+		bootstrap.InitTaskManager()
+		user, err := op.GetAdmin()
+		if mountUser != "" {
+			user, err = op.GetUserByName(mountUser)
+		}
+		if err != nil {
+			utils.Log.Fatalf("failed get user: %+v", err)
+		}
+		if user.Disabled {
+			utils.Log.Fatalf("user [%s] is disabled", user.Username)
+		}
+		// wait for storages, otherwise the mount point looks empty at first
+		for !conf.StoragesLoaded {
+			time.Sleep(100 * time.Millisecond)
+		}
+		host, err := fuse.Mount(args[0], args[1], fuse.MountArgs{
+			User:        user,
+			ReadOnly:    mountReadOnly,
+			AttrTimeout: mountAttrTimeout,
+			Options:     mountOptions,
+		})
+		if err != nil {
+			utils.Log.Fatalf("%+v", err)
+		}
+		utils.Log.Infof("mounted [%s] to [%s] as user [%s]", args[0], args[1], user.Username)
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		utils.Log.Println("Unmount...")
+		host.Unmount()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().StringVar(&mountUser, "user", "", "mount as the user, default is admin")
+	MountCmd.Flags().BoolVar(&mountReadOnly, "read-only", false, "mount as read only")
+	MountCmd.Flags().DurationVar(&mountAttrTimeout, "attr-timeout", 5*time.Second, "how long to cache attributes and directory listings")
+	MountCmd.Flags().StringArrayVarP(&mountOptions, "option", "o", nil, "extra FUSE mount options, e.g. -o allow_other")
+}
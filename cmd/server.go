@@ -38,6 +38,13 @@ the address is defined in config file`,
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
 		bootstrap.InitTaskManager()
+		bootstrap.InitSyncJobs()
+		bootstrap.InitTus()
+		bootstrap.InitTrash()
+		bootstrap.InitS3Uploads()
+		bootstrap.InitChangeFeeds()
+		bootstrap.InitAudit()
+		bootstrap.InitRateLimits()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
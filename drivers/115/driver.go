@@ -248,4 +248,19 @@ func (d *Pan115) DeleteOfflineTasks(ctx context.Context, hashes []string, delete
 	return d.client.DeleteOfflineTasks(hashes, deleteFiles)
 }
 
+func (d *Pan115) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.WaitLimit(ctx); err != nil {
+		return nil, err
+	}
+	info, err := d.client.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: info.SpaceInfo.AllTotal.Size,
+		UsedSpace:  info.SpaceInfo.AllUse.Size,
+		FreeSpace:  info.SpaceInfo.AllRemain.Size,
+	}, nil
+}
+
 var _ driver.Driver = (*Pan115)(nil)
@@ -230,6 +230,22 @@ func (d *AliyundriveOpen) Other(ctx context.Context, args model.OtherArgs) (inte
 	return resp, nil
 }
 
+func (d *AliyundriveOpen) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var resp SpaceInfoResp
+	_, err := d.request("/adrive/v1.0/user/getSpaceInfo", http.MethodPost, func(req *resty.Request) {
+		req.SetResult(&resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := resp.PersonalSpaceInfo
+	return &model.StorageDetails{
+		TotalSpace: info.TotalSize,
+		UsedSpace:  info.UsedSize,
+		FreeSpace:  max(info.TotalSize-info.UsedSize, 0),
+	}, nil
+}
+
 var _ driver.Driver = (*AliyundriveOpen)(nil)
 var _ driver.MkdirResult = (*AliyundriveOpen)(nil)
 var _ driver.MoveResult = (*AliyundriveOpen)(nil)
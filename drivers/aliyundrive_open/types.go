@@ -82,3 +82,10 @@ type MoveOrCopyResp struct {
 	DriveID string `json:"drive_id"`
 	FileID  string `json:"file_id"`
 }
+
+type SpaceInfoResp struct {
+	PersonalSpaceInfo struct {
+		TotalSize int64 `json:"total_size"`
+		UsedSize  int64 `json:"used_size"`
+	} `json:"personal_space_info"`
+}
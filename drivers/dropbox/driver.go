@@ -237,4 +237,46 @@ func (d *Dropbox) Put(ctx context.Context, dstDir model.Obj, stream model.FileSt
 	return err
 }
 
+func (d *Dropbox) GetChanges(ctx context.Context, cursor string) ([]model.Change, string, error) {
+	if cursor == "" {
+		root := utils.FixAndCleanPath(d.RootFolderPath)
+		if root == "/" {
+			root = ""
+		}
+		res, err := d.request("/2/files/list_folder/get_latest_cursor", http.MethodPost, func(req *resty.Request) {
+			req.SetContext(ctx).SetBody(base.Json{
+				"path":            root,
+				"recursive":       true,
+				"include_deleted": true,
+			})
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, utils.Json.Get(res, "cursor").ToString(), nil
+	}
+	var changes []model.Change
+	for {
+		resp, err := d.list(ctx, base.Json{"cursor": cursor}, true)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, f := range resp.Entries {
+			p, ok := d.toActualPath(f.PathDisplay)
+			if !ok {
+				continue
+			}
+			changes = append(changes, model.Change{
+				Path:    p,
+				IsDir:   f.Tag == "folder",
+				Deleted: f.Tag == "deleted",
+			})
+		}
+		cursor = resp.Cursor
+		if !resp.HasMore {
+			return changes, cursor, nil
+		}
+	}
+}
+
 var _ driver.Driver = (*Dropbox)(nil)
@@ -207,3 +207,20 @@ func (d *Dropbox) startUploadSession(ctx context.Context) (string, error) {
 	_ = res.Body.Close()
 	return sessionId, nil
 }
+
+// toActualPath converts the path in the dropbox to the path in the storage
+func (d *Dropbox) toActualPath(dropboxPath string) (string, bool) {
+	root := utils.FixAndCleanPath(d.RootFolderPath)
+	dropboxPath = utils.FixAndCleanPath(dropboxPath)
+	if root == "/" {
+		return dropboxPath, true
+	}
+	// the paths of dropbox are case-insensitive
+	if strings.EqualFold(dropboxPath, root) {
+		return "/", true
+	}
+	if len(dropboxPath) > len(root) && strings.EqualFold(dropboxPath[:len(root)+1], root+"/") {
+		return dropboxPath[len(root):], true
+	}
+	return "", false
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	stdpath "path"
 	"strconv"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -166,4 +167,76 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *GoogleDrive) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var resp AboutResp
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "storageQuota")
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	// the limit is not returned if the storage is unlimited
+	details := &model.StorageDetails{
+		TotalSpace: resp.StorageQuota.Limit,
+		UsedSpace:  resp.StorageQuota.Usage,
+	}
+	if details.TotalSpace > 0 {
+		details.FreeSpace = max(details.TotalSpace-details.UsedSpace, 0)
+	}
+	return details, nil
+}
+
+func (d *GoogleDrive) GetChanges(ctx context.Context, cursor string) ([]model.Change, string, error) {
+	if cursor == "" {
+		var resp StartPageTokenResp
+		_, err := d.request("https://www.googleapis.com/drive/v3/changes/startPageToken", http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx)
+		}, &resp)
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, resp.StartPageToken, nil
+	}
+	var changes []model.Change
+	paths := make(map[string]string)
+	pageToken := cursor
+	for {
+		var resp ChangesResp
+		_, err := d.request("https://www.googleapis.com/drive/v3/changes", http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx).SetQueryParams(map[string]string{
+				"pageToken": pageToken,
+				"pageSize":  "1000",
+				"fields":    "nextPageToken,newStartPageToken,changes(fileId,removed,file(name,mimeType,parents,trashed))",
+			})
+		}, &resp)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, c := range resp.Changes {
+			// the parents of the removed files are unknown
+			if c.Removed || c.File == nil || len(c.File.Parents) == 0 {
+				changes = append(changes, model.Change{Path: "/", IsDir: true})
+				continue
+			}
+			parent, ok, err := d.getPath(ctx, c.File.Parents[0], paths)
+			if err != nil {
+				changes = append(changes, model.Change{Path: "/", IsDir: true})
+				continue
+			}
+			if !ok {
+				continue
+			}
+			changes = append(changes, model.Change{
+				Path:    stdpath.Join(parent, c.File.Name),
+				IsDir:   c.File.MimeType == "application/vnd.google-apps.folder",
+				Deleted: c.File.Trashed,
+			})
+		}
+		if resp.NextPageToken == "" {
+			return changes, resp.NewStartPageToken, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
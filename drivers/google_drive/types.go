@@ -78,3 +78,36 @@ type Error struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
+
+type AboutResp struct {
+	StorageQuota struct {
+		Limit int64 `json:"limit,string"`
+		Usage int64 `json:"usage,string"`
+	} `json:"storageQuota"`
+}
+
+type StartPageTokenResp struct {
+	StartPageToken string `json:"startPageToken"`
+}
+
+type ChangesResp struct {
+	NextPageToken     string   `json:"nextPageToken"`
+	NewStartPageToken string   `json:"newStartPageToken"`
+	Changes           []Change `json:"changes"`
+}
+
+type Change struct {
+	FileId  string `json:"fileId"`
+	Removed bool   `json:"removed"`
+	File    *struct {
+		Name     string   `json:"name"`
+		MimeType string   `json:"mimeType"`
+		Parents  []string `json:"parents"`
+		Trashed  bool     `json:"trashed"`
+	} `json:"file"`
+}
+
+type ParentsResp struct {
+	Name    string   `json:"name"`
+	Parents []string `json:"parents"`
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	stdpath "path"
 	"regexp"
 	"strconv"
 	"time"
@@ -242,3 +243,43 @@ func (d *GoogleDrive) chunkUpload(ctx context.Context, stream model.FileStreamer
 	}
 	return nil
 }
+
+// getPath resolves the path of the folder relative to the root folder by walking up the parents,
+// the resolved paths are memorized in paths, ok is false if the folder is not under the root folder
+func (d *GoogleDrive) getPath(ctx context.Context, id string, paths map[string]string) (p string, ok bool, err error) {
+	if len(paths) == 0 {
+		// the root folder may be an alias such as "root", the parents are the real ids
+		var root File
+		_, err = d.request("https://www.googleapis.com/drive/v3/files/"+d.RootFolderID, http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx).SetQueryParam("fields", "id")
+		}, &root)
+		if err != nil {
+			return "", false, err
+		}
+		paths[root.Id] = "/"
+	}
+	if p, ok := paths[id]; ok {
+		return p, p != "", nil
+	}
+	var resp ParentsResp
+	_, err = d.request("https://www.googleapis.com/drive/v3/files/"+id, http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("fields", "id,name,parents")
+	}, &resp)
+	if err != nil {
+		return "", false, err
+	}
+	if len(resp.Parents) == 0 {
+		// reached the top of the drive outside the root folder
+		paths[id] = ""
+		return "", false, nil
+	}
+	parent, ok, err := d.getPath(ctx, resp.Parents[0], paths)
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		p = stdpath.Join(parent, resp.Name)
+	}
+	paths[id] = p
+	return p, ok, nil
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/OpenListTeam/times"
@@ -23,6 +24,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	cp "github.com/otiai10/copy"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -35,6 +37,10 @@ type Local struct {
 	// zero means no limit
 	thumbConcurrency int
 	thumbTokenBucket TokenBucket
+
+	// the watcher is started by the first poll of the changes
+	watchMu sync.Mutex
+	watcher *watcher
 }
 
 func (d *Local) Config() driver.Config {
@@ -83,6 +89,13 @@ func (d *Local) Init(ctx context.Context) error {
 }
 
 func (d *Local) Drop(ctx context.Context) error {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+	if d.watcher != nil {
+		err := d.watcher.close()
+		d.watcher = nil
+		return err
+	}
 	return nil
 }
 
@@ -328,4 +341,38 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+func (d *Local) SetModified(ctx context.Context, obj model.Obj, modified time.Time) error {
+	return os.Chtimes(obj.GetPath(), modified, modified)
+}
+
+func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: int64(usage.Total),
+		UsedSpace:  int64(usage.Used),
+		FreeSpace:  int64(usage.Free),
+	}, nil
+}
+
+func (d *Local) GetChanges(ctx context.Context, cursor string) ([]model.Change, string, error) {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+	if d.watcher == nil {
+		w, err := newWatcher(d.GetRootPath())
+		if err != nil {
+			return nil, "", err
+		}
+		d.watcher = w
+	}
+	changes := d.watcher.drain()
+	if cursor == "" {
+		// the changes before the cursor is got are not wanted
+		changes = nil
+	}
+	return changes, "watching", nil
+}
+
 var _ driver.Driver = (*Local)(nil)
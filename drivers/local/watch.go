@@ -0,0 +1,124 @@
+package local
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxPendingChanges limits the changes kept between the polls, the changes become unknown if exceeded
+const maxPendingChanges = 10000
+
+// watcher collects the changes under the root by fsnotify, the dirs are watched one by one
+type watcher struct {
+	root string
+	w    *fsnotify.Watcher
+
+	mu       sync.Mutex
+	changes  []model.Change
+	overflow bool
+}
+
+func newWatcher(root string) (*watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	wt := &watcher{root: root, w: w}
+	if err = wt.addTree(root); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	go wt.run()
+	return wt, nil
+}
+
+// addTree watches the dir and its sub dirs, the ones can't be watched are skipped,
+// e.g. when the limit of the watches is reached, their changes are only found by refreshing
+func (wt *watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// skip the dirs can't be read
+			return nil
+		}
+		if d.IsDir() {
+			if err := wt.w.Add(path); err != nil {
+				log.Warnf("failed watch %s: %+v", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (wt *watcher) run() {
+	for {
+		select {
+		case e, ok := <-wt.w.Events:
+			if !ok {
+				return
+			}
+			if e.Op == fsnotify.Chmod {
+				continue
+			}
+			rel, err := filepath.Rel(wt.root, e.Name)
+			if err != nil {
+				continue
+			}
+			c := model.Change{
+				Path:    "/" + filepath.ToSlash(rel),
+				Deleted: e.Has(fsnotify.Remove) || e.Has(fsnotify.Rename),
+			}
+			if e.Has(fsnotify.Create) {
+				if fi, err := os.Stat(e.Name); err == nil && fi.IsDir() {
+					c.IsDir = true
+					if err = wt.addTree(e.Name); err != nil {
+						log.Warnf("failed watch %s: %+v", e.Name, err)
+					}
+				}
+			}
+			wt.add(c)
+		case err, ok := <-wt.w.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("local watcher of %s: %+v", wt.root, err)
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				wt.mu.Lock()
+				wt.overflow = true
+				wt.mu.Unlock()
+			}
+		}
+	}
+}
+
+func (wt *watcher) add(c model.Change) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	if len(wt.changes) >= maxPendingChanges {
+		wt.overflow = true
+		return
+	}
+	wt.changes = append(wt.changes, c)
+}
+
+// drain returns the changes since the last call
+func (wt *watcher) drain() []model.Change {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	changes := wt.changes
+	if wt.overflow {
+		changes = []model.Change{{Path: "/", IsDir: true}}
+	}
+	wt.changes, wt.overflow = nil, false
+	return changes
+}
+
+func (wt *watcher) close() error {
+	return wt.w.Close()
+}
@@ -72,3 +72,36 @@ type Files struct {
 	Value    []File `json:"value"`
 	NextLink string `json:"@odata.nextLink"`
 }
+
+type DriveResp struct {
+	Quota struct {
+		Total     int64 `json:"total"`
+		Used      int64 `json:"used"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
+}
+
+type DeltaItem struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name"`
+	Folder  *struct{} `json:"folder"`
+	Deleted *struct{} `json:"deleted"`
+	// the path is not returned by the delta
+	ParentReference struct {
+		Id string `json:"id"`
+	} `json:"parentReference"`
+}
+
+type DeltaResp struct {
+	Value     []DeltaItem `json:"value"`
+	NextLink  string      `json:"@odata.nextLink"`
+	DeltaLink string      `json:"@odata.deltaLink"`
+}
+
+type ItemResp struct {
+	Name            string `json:"name"`
+	ParentReference struct {
+		// e.g. /drive/root:/folder, empty for the root
+		Path string `json:"path"`
+	} `json:"parentReference"`
+}
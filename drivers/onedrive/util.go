@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	stdpath "path"
 	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -207,3 +209,44 @@ func (d *Onedrive) upBig(ctx context.Context, dstDir model.Obj, stream model.Fil
 	}
 	return nil
 }
+
+// getDrivePath returns the path of the item in the drive by the id, the resolved paths are kept in the paths
+func (d *Onedrive) getDrivePath(ctx context.Context, id string, paths map[string]string) (string, error) {
+	if p, ok := paths[id]; ok {
+		return p, nil
+	}
+	var item ItemResp
+	// the items of the drive, e.g. {api}/v1.0/me/drive/items/{id}
+	u := strings.TrimSuffix(d.GetMetaUrl(false, "/"), "/root") + "/items/" + id
+	_, err := d.Request(u, http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("$select", "name,parentReference")
+	}, &item)
+	if err != nil {
+		return "", err
+	}
+	p := "/"
+	if item.ParentReference.Path != "" {
+		_, parent, _ := strings.Cut(item.ParentReference.Path, "root:")
+		if unescaped, err := url.PathUnescape(parent); err == nil {
+			parent = unescaped
+		}
+		p = stdpath.Join("/", parent, item.Name)
+	}
+	paths[id] = p
+	return p, nil
+}
+
+// toActualPath converts the path in the drive to the path in the storage
+func (d *Onedrive) toActualPath(drivePath string) (string, bool) {
+	root := utils.FixAndCleanPath(d.RootFolderPath)
+	if root == "/" {
+		return drivePath, true
+	}
+	if drivePath == root {
+		return "/", true
+	}
+	if strings.HasPrefix(drivePath, root+"/") {
+		return strings.TrimPrefix(drivePath, root), true
+	}
+	return "", false
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -206,4 +207,69 @@ func (d *OnedriveAPP) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *OnedriveAPP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var resp DriveResp
+	// the drive of the root, e.g. {api}/v1.0/me/drive
+	api := strings.TrimSuffix(d.GetMetaUrl(false, "/"), "/root")
+	_, err := d.Request(api, http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("$select", "quota")
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: resp.Quota.Total,
+		UsedSpace:  resp.Quota.Used,
+		FreeSpace:  resp.Quota.Remaining,
+	}, nil
+}
+
+func (d *OnedriveAPP) GetChanges(ctx context.Context, cursor string) ([]model.Change, string, error) {
+	link := cursor
+	if link == "" {
+		link = d.GetMetaUrl(false, "/") + "/delta?token=latest"
+	}
+	var changes []model.Change
+	paths := make(map[string]string)
+	for {
+		var resp DeltaResp
+		_, err := d.Request(link, http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx)
+		}, &resp)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, item := range resp.Value {
+			// the root
+			if item.ParentReference.Id == "" {
+				continue
+			}
+			parent, err := d.getDrivePath(ctx, item.ParentReference.Id, paths)
+			if err != nil {
+				// the parent may be deleted too
+				changes = append(changes, model.Change{Path: "/", IsDir: true})
+				continue
+			}
+			name := item.Name
+			if name == "" {
+				// the name of a deleted item may be missing, only the parent is needed then
+				name = item.Id
+			}
+			p, ok := d.toActualPath(path.Join(parent, name))
+			if !ok {
+				continue
+			}
+			changes = append(changes, model.Change{
+				Path:    p,
+				IsDir:   item.Folder != nil,
+				Deleted: item.Deleted != nil,
+			})
+		}
+		if resp.NextLink == "" {
+			return changes, resp.DeltaLink, nil
+		}
+		link = resp.NextLink
+	}
+}
+
 var _ driver.Driver = (*OnedriveAPP)(nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -115,4 +116,29 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	return err
 }
 
+func (d *SFTP) SetModified(ctx context.Context, obj model.Obj, modified time.Time) error {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return err
+	}
+	return d.client.Chtimes(obj.GetPath(), modified, modified)
+}
+
+// GetDetails needs the statvfs@openssh.com extension of the server
+func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return nil, err
+	}
+	stat, err := d.client.StatVFS(d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	total := int64(stat.TotalSpace())
+	return &model.StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  total - int64(stat.FreeSpace()),
+		// the space available to unprivileged users
+		FreeSpace: int64(stat.Bavail * stat.Frsize),
+	}, nil
+}
+
 var _ driver.Driver = (*SFTP)(nil)
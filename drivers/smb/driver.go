@@ -5,6 +5,7 @@ import (
 	"errors"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -197,4 +198,35 @@ func (d *SMB) Put(ctx context.Context, dstDir model.Obj, stream model.FileStream
 //	return nil, errs.NotSupport
 //}
 
+func (d *SMB) SetModified(ctx context.Context, obj model.Obj, modified time.Time) error {
+	if err := d.checkConn(); err != nil {
+		return err
+	}
+	if err := d.fs.Chtimes(obj.GetPath(), modified, modified); err != nil {
+		d.cleanLastConnTime()
+		return err
+	}
+	d.updateLastConnTime()
+	return nil
+}
+
+func (d *SMB) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.checkConn(); err != nil {
+		return nil, err
+	}
+	stat, err := d.fs.Statfs(d.GetRootPath())
+	if err != nil {
+		d.cleanLastConnTime()
+		return nil, err
+	}
+	d.updateLastConnTime()
+	unit := stat.BlockSize() * stat.FragmentSize()
+	total := int64(stat.TotalBlockCount() * unit)
+	return &model.StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  total - int64(stat.FreeBlockCount()*unit),
+		FreeSpace:  int64(stat.AvailableBlockCount() * unit),
+	}, nil
+}
+
 var _ driver.Driver = (*SMB)(nil)
@@ -0,0 +1,8 @@
+package archive
+
+import (
+	_ "github.com/alist-org/alist/v3/internal/archive/rardecode"
+	_ "github.com/alist-org/alist/v3/internal/archive/sevenzip"
+	_ "github.com/alist-org/alist/v3/internal/archive/tar"
+	_ "github.com/alist-org/alist/v3/internal/archive/zip"
+)
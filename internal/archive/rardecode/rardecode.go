@@ -0,0 +1,177 @@
+package rardecode
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/nwaples/rardecode/v2"
+	"github.com/pkg/errors"
+)
+
+// RarDecode handles rar archives, which are read sequentially as tar,
+// the solid ones can't be read in any other way
+type RarDecode struct {
+}
+
+func (*RarDecode) AcceptedExtensions() []string {
+	return []string{".rar"}
+}
+
+func (*RarDecode) GetMeta(ss *stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	rr, err := getReader(ss, args.Password, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := tool.NewTreeBuilder()
+	encrypted := false
+	for {
+		hdr, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if hdr.Encrypted {
+			encrypted = true
+		}
+		b.Add(hdr.Name, fileInfo{hdr})
+	}
+	return &model.ArchiveMetaInfo{Encrypted: encrypted, Tree: b.Tree()}, nil
+}
+
+func (*RarDecode) Extract(ss *stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	rr, err := getReader(ss, args.Password, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	innerPath := tool.CleanInnerPath(args.InnerPath)
+	for {
+		hdr, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, wrapError(err)
+		}
+		if !hdr.IsDir && tool.CleanInnerPath(hdr.Name) == innerPath {
+			if hdr.Encrypted && args.Password == "" {
+				return nil, 0, errors.WithStack(errs.WrongArchivePassword)
+			}
+			return io.NopCloser(errorReader{rr}), hdr.UnPackedSize, nil
+		}
+	}
+	return nil, 0, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (*RarDecode) Decompress(ss *stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	rr, err := getReader(ss, args.Password, up)
+	if err != nil {
+		return err
+	}
+	found := false
+	for {
+		hdr, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return wrapError(err)
+		}
+		if !tool.IsInnerPathMatched(args.InnerPath, hdr.Name) {
+			continue
+		}
+		found = true
+		dst := tool.LocalPath(outputPath, args.InnerPath, hdr.Name)
+		if hdr.IsDir {
+			err = os.MkdirAll(dst, 0777)
+		} else {
+			err = tool.DecompressFile(errorReader{rr}, dst, fileInfo{hdr})
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "failed decompress %s", hdr.Name)
+		}
+	}
+	if !found {
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	return nil
+}
+
+type progressReader struct {
+	io.Reader
+	read, size int64
+	up         model.UpdateProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if r.size > 0 {
+		r.up(float64(r.read) / float64(r.size) * 100)
+	}
+	return n, err
+}
+
+func getReader(ss *stream.SeekableStream, password string, up model.UpdateProgress) (*rardecode.Reader, error) {
+	var r io.Reader = ss
+	if up != nil {
+		r = &progressReader{Reader: ss, size: ss.GetSize(), up: up}
+	}
+	var opts []rardecode.Option
+	if password != "" {
+		opts = append(opts, rardecode.Password(password))
+	}
+	rr, err := rardecode.NewReader(r, opts...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return rr, nil
+}
+
+func wrapError(err error) error {
+	if errors.Is(err, rardecode.ErrArchiveEncrypted) ||
+		errors.Is(err, rardecode.ErrArchivedFileEncrypted) ||
+		errors.Is(err, rardecode.ErrBadPassword) {
+		return errors.WithStack(errs.WrongArchivePassword)
+	}
+	return err
+}
+
+// errorReader reports the wrong password while reading the content
+type errorReader struct {
+	r io.Reader
+}
+
+func (r errorReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = wrapError(err)
+	}
+	return n, err
+}
+
+// fileInfo is the fs.FileInfo of an entry
+type fileInfo struct {
+	h *rardecode.FileHeader
+}
+
+func (i fileInfo) Name() string       { return stdpath.Base(i.h.Name) }
+func (i fileInfo) Size() int64        { return i.h.UnPackedSize }
+func (i fileInfo) Mode() fs.FileMode  { return i.h.Mode() }
+func (i fileInfo) ModTime() time.Time { return i.h.ModificationTime }
+func (i fileInfo) IsDir() bool        { return i.h.IsDir }
+func (i fileInfo) Sys() any           { return nil }
+
+var _ tool.Tool = (*RarDecode)(nil)
+
+func init() {
+	tool.RegisterTool(&RarDecode{})
+}
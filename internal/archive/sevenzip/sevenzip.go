@@ -0,0 +1,140 @@
+package sevenzip
+
+import (
+	"io"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/bodgit/sevenzip"
+	"github.com/pkg/errors"
+)
+
+// SevenZip handles 7z archives, the headers may be encrypted as well as the content
+type SevenZip struct {
+}
+
+func (*SevenZip) AcceptedExtensions() []string {
+	return []string{".7z"}
+}
+
+func (*SevenZip) GetMeta(ss *stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	zr, err := getReader(ss, args.Password)
+	if err != nil {
+		return nil, err
+	}
+	b := tool.NewTreeBuilder()
+	for _, f := range zr.File {
+		b.Add(f.Name, f.FileInfo())
+	}
+	// it's only known when a file is opened, so try the first one,
+	// the archive opened with a password is regarded as encrypted as it can't be told then
+	encrypted := false
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			encrypted = isEncrypted(err)
+		} else {
+			_ = rc.Close()
+		}
+		break
+	}
+	return &model.ArchiveMetaInfo{
+		Encrypted: encrypted || args.Password != "",
+		Tree:      b.Tree(),
+	}, nil
+}
+
+func (*SevenZip) Extract(ss *stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	zr, err := getReader(ss, args.Password)
+	if err != nil {
+		return nil, 0, err
+	}
+	innerPath := tool.CleanInnerPath(args.InnerPath)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || tool.CleanInnerPath(f.Name) != innerPath {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, 0, wrapError(err)
+		}
+		return rc, int64(f.UncompressedSize), nil
+	}
+	return nil, 0, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (*SevenZip) Decompress(ss *stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	zr, err := getReader(ss, args.Password)
+	if err != nil {
+		return err
+	}
+	var files []*sevenzip.File
+	for _, f := range zr.File {
+		if tool.IsInnerPathMatched(args.InnerPath, f.Name) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	for i, f := range files {
+		dst := tool.LocalPath(outputPath, args.InnerPath, f.Name)
+		if f.FileInfo().IsDir() {
+			if err = os.MkdirAll(dst, 0777); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return wrapError(err)
+		}
+		err = tool.DecompressFile(rc, dst, f.FileInfo())
+		_ = rc.Close()
+		if err != nil {
+			return errors.WithMessagef(wrapError(err), "failed decompress %s", f.Name)
+		}
+		if up != nil {
+			up(float64(i+1) / float64(len(files)) * 100)
+		}
+	}
+	return nil
+}
+
+func getReader(ss *stream.SeekableStream, password string) (*sevenzip.Reader, error) {
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := sevenzip.NewReaderWithPassword(reader, ss.GetSize(), password)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return zr, nil
+}
+
+func isEncrypted(err error) bool {
+	var e *sevenzip.ReadError
+	return errors.As(err, &e) && e.Encrypted
+}
+
+// wrapError reports the failures of the encrypted archives as the wrong password,
+// the library can't tell a wrong password from a broken archive
+func wrapError(err error) error {
+	if isEncrypted(err) {
+		return errors.WithStack(errs.WrongArchivePassword)
+	}
+	return err
+}
+
+var _ tool.Tool = (*SevenZip)(nil)
+
+func init() {
+	tool.RegisterTool(&SevenZip{})
+}
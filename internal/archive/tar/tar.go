@@ -0,0 +1,142 @@
+package tar
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+)
+
+// Tar handles tar archives, which can only be read sequentially,
+// so the whole archive is read up to the wanted entry.
+type Tar struct {
+}
+
+func (*Tar) AcceptedExtensions() []string {
+	return []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2"}
+}
+
+func (*Tar) GetMeta(ss *stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	tr, err := getReader(ss, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := tool.NewTreeBuilder()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeDir {
+			b.Add(hdr.Name, hdr.FileInfo())
+		}
+	}
+	return &model.ArchiveMetaInfo{Tree: b.Tree()}, nil
+}
+
+func (*Tar) Extract(ss *stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	tr, err := getReader(ss, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	innerPath := tool.CleanInnerPath(args.InnerPath)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if hdr.Typeflag == tar.TypeReg && tool.CleanInnerPath(hdr.Name) == innerPath {
+			return io.NopCloser(tr), hdr.Size, nil
+		}
+	}
+	return nil, 0, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (*Tar) Decompress(ss *stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	tr, err := getReader(ss, up)
+	if err != nil {
+		return err
+	}
+	found := false
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !tool.IsInnerPathMatched(args.InnerPath, hdr.Name) {
+			continue
+		}
+		found = true
+		dst := tool.LocalPath(outputPath, args.InnerPath, hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dst, 0777)
+		case tar.TypeReg:
+			err = tool.DecompressFile(tr, dst, hdr.FileInfo())
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "failed decompress %s", hdr.Name)
+		}
+	}
+	if !found {
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	return nil
+}
+
+type progressReader struct {
+	io.Reader
+	read, size int64
+	up         model.UpdateProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if r.size > 0 {
+		r.up(float64(r.read) / float64(r.size) * 100)
+	}
+	return n, err
+}
+
+func getReader(ss *stream.SeekableStream, up model.UpdateProgress) (*tar.Reader, error) {
+	var r io.Reader = ss
+	if up != nil {
+		r = &progressReader{Reader: ss, size: ss.GetSize(), up: up}
+	}
+	name := strings.ToLower(ss.GetName())
+	switch {
+	case strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".tgz"):
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r = gr
+	case strings.HasSuffix(name, ".bz2") || strings.HasSuffix(name, ".tbz2"):
+		r = bzip2.NewReader(r)
+	}
+	return tar.NewReader(r), nil
+}
+
+var _ tool.Tool = (*Tar)(nil)
+
+func init() {
+	tool.RegisterTool(&Tar{})
+}
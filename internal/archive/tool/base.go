@@ -0,0 +1,19 @@
+package tool
+
+import (
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+type Tool interface {
+	// AcceptedExtensions returns the lower-case suffixes of the archive names the tool handles, e.g. ".tar.gz"
+	AcceptedExtensions() []string
+	// GetMeta returns the meta-info of the archive, the tree of it should always be filled
+	GetMeta(ss *stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error)
+	// Extract returns a reader of the file args.InnerPath in the archive and its size
+	Extract(ss *stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error)
+	// Decompress extracts args.InnerPath of the archive into the local folder outputPath
+	Decompress(ss *stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error
+}
@@ -0,0 +1,105 @@
+package tool
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// TreeBuilder generates the folder structure of an archive from its entries,
+// folders which are not stored in the archive explicitly are created as well
+type TreeBuilder struct {
+	root model.ObjectTree
+	dirs map[string]*model.ObjectTree
+}
+
+func NewTreeBuilder() *TreeBuilder {
+	b := &TreeBuilder{dirs: make(map[string]*model.ObjectTree)}
+	b.dirs["/"] = &b.root
+	return b
+}
+
+func (b *TreeBuilder) dir(p string) *model.ObjectTree {
+	if d, ok := b.dirs[p]; ok {
+		return d
+	}
+	parent := b.dir(stdpath.Dir(p))
+	d := &model.ObjectTree{Object: model.Object{
+		Path:     p,
+		Name:     stdpath.Base(p),
+		IsFolder: true,
+		Modified: parent.Modified,
+	}}
+	parent.Children = append(parent.Children, d)
+	b.dirs[p] = d
+	return d
+}
+
+// Add adds an entry with the name stored in the archive
+func (b *TreeBuilder) Add(name string, info fs.FileInfo) {
+	p := CleanInnerPath(name)
+	if p == "/" {
+		return
+	}
+	if info.IsDir() {
+		b.dir(p).Modified = info.ModTime()
+		return
+	}
+	parent := b.dir(stdpath.Dir(p))
+	parent.Children = append(parent.Children, &model.ObjectTree{Object: model.Object{
+		Path:     p,
+		Name:     stdpath.Base(p),
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+	}})
+}
+
+func (b *TreeBuilder) Tree() []model.ObjTree {
+	return b.root.Children
+}
+
+// CleanInnerPath converts the name of an entry to an absolute inner path
+func CleanInnerPath(name string) string {
+	return utils.FixAndCleanPath(strings.ReplaceAll(name, "\\", "/"))
+}
+
+// IsInnerPathMatched reports whether the entry is args.InnerPath itself or inside it
+func IsInnerPathMatched(innerPath, name string) bool {
+	innerPath = CleanInnerPath(innerPath)
+	name = CleanInnerPath(name)
+	return innerPath == "/" || name == innerPath || strings.HasPrefix(name, innerPath+"/")
+}
+
+// LocalPath returns where the entry should be decompressed to, when decompressing innerPath into outputPath.
+// the entries are put relative to the parent of innerPath, so that a folder is decompressed with itself.
+func LocalPath(outputPath, innerPath, name string) string {
+	innerPath = CleanInnerPath(innerPath)
+	name = CleanInnerPath(name)
+	if innerPath != "/" {
+		name = strings.TrimPrefix(name, stdpath.Dir(innerPath))
+	}
+	return filepath.Join(outputPath, filepath.FromSlash(name))
+}
+
+// DecompressFile writes the content of an entry to dst
+func DecompressFile(r io.Reader, dst string, info fs.FileInfo) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = utils.CopyWithBuffer(f, r)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,35 @@
+package tool
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+)
+
+var (
+	Tools    = map[string]Tool{}
+	toolExts []string
+)
+
+func RegisterTool(tool Tool) {
+	for _, ext := range tool.AcceptedExtensions() {
+		Tools[ext] = tool
+		toolExts = append(toolExts, ext)
+	}
+	// match the longest suffix first, so that .tar.gz wins over .gz
+	sort.Slice(toolExts, func(i, j int) bool {
+		return len(toolExts[i]) > len(toolExts[j])
+	})
+}
+
+// GetArchiveTool returns the tool handling the archive with the file name
+func GetArchiveTool(name string) (string, Tool, error) {
+	name = strings.ToLower(name)
+	for _, ext := range toolExts {
+		if strings.HasSuffix(name, ext) {
+			return ext, Tools[ext], nil
+		}
+	}
+	return "", nil, errs.UnknownArchiveFormat
+}
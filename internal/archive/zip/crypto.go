@@ -0,0 +1,56 @@
+package zip
+
+import (
+	"hash/crc32"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+)
+
+// zipCryptoReader decrypts the traditional PKWARE encryption (ZipCrypto)
+type zipCryptoReader struct {
+	r          io.Reader
+	k0, k1, k2 uint32
+}
+
+func newZipCryptoReader(r io.Reader, password string, check byte) (io.Reader, error) {
+	z := &zipCryptoReader{r: r, k0: 0x12345678, k1: 0x23456789, k2: 0x34567890}
+	for i := 0; i < len(password); i++ {
+		z.update(password[i])
+	}
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	z.decrypt(header)
+	if header[11] != check {
+		return nil, errors.WithStack(errs.WrongArchivePassword)
+	}
+	return z, nil
+}
+
+func crc32update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ (crc >> 8)
+}
+
+func (z *zipCryptoReader) update(b byte) {
+	z.k0 = crc32update(z.k0, b)
+	z.k1 = (z.k1+z.k0&0xff)*134775813 + 1
+	z.k2 = crc32update(z.k2, byte(z.k1>>24))
+}
+
+func (z *zipCryptoReader) decrypt(buf []byte) {
+	for i, c := range buf {
+		t := z.k2 | 2
+		p := c ^ byte((t*(t^1))>>8)
+		buf[i] = p
+		z.update(p)
+	}
+}
+
+func (z *zipCryptoReader) Read(p []byte) (int, error) {
+	n, err := z.r.Read(p)
+	z.decrypt(p[:n])
+	return n, err
+}
@@ -0,0 +1,166 @@
+package zip
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"io"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type Zip struct {
+}
+
+func (*Zip) AcceptedExtensions() []string {
+	return []string{".zip"}
+}
+
+func (*Zip) GetMeta(ss *stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	zr, err := getReader(ss)
+	if err != nil {
+		return nil, err
+	}
+	b := tool.NewTreeBuilder()
+	encrypted := false
+	for _, f := range zr.File {
+		if isEncrypted(f) {
+			encrypted = true
+		}
+		b.Add(decodeName(f.Name, f.NonUTF8), f.FileInfo())
+	}
+	return &model.ArchiveMetaInfo{
+		Comment:   decodeName(zr.Comment, true),
+		Encrypted: encrypted,
+		Tree:      b.Tree(),
+	}, nil
+}
+
+func (*Zip) Extract(ss *stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	zr, err := getReader(ss)
+	if err != nil {
+		return nil, 0, err
+	}
+	innerPath := tool.CleanInnerPath(args.InnerPath)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || tool.CleanInnerPath(decodeName(f.Name, f.NonUTF8)) != innerPath {
+			continue
+		}
+		rc, err := open(f, args.Password)
+		if err != nil {
+			return nil, 0, err
+		}
+		return rc, int64(f.UncompressedSize64), nil
+	}
+	return nil, 0, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (*Zip) Decompress(ss *stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	zr, err := getReader(ss)
+	if err != nil {
+		return err
+	}
+	var files []*zip.File
+	for _, f := range zr.File {
+		if tool.IsInnerPathMatched(args.InnerPath, decodeName(f.Name, f.NonUTF8)) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	for i, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := open(f, args.Password)
+		if err != nil {
+			return err
+		}
+		dst := tool.LocalPath(outputPath, args.InnerPath, decodeName(f.Name, f.NonUTF8))
+		err = tool.DecompressFile(rc, dst, f.FileInfo())
+		_ = rc.Close()
+		if err != nil {
+			return errors.WithMessagef(err, "failed decompress %s", f.Name)
+		}
+		if up != nil {
+			up(float64(i+1) / float64(len(files)) * 100)
+		}
+	}
+	return nil
+}
+
+func getReader(ss *stream.SeekableStream) (*zip.Reader, error) {
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(reader, ss.GetSize())
+	// insecure names are cleaned by tool.CleanInnerPath, so the reader is still usable
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return nil, err
+	}
+	return zr, nil
+}
+
+func isEncrypted(f *zip.File) bool {
+	return f.Flags&0x1 != 0
+}
+
+// open opens the file in the archive, traditional PKWARE encryption is supported with password
+func open(f *zip.File, password string) (io.ReadCloser, error) {
+	if !isEncrypted(f) {
+		return f.Open()
+	}
+	// WinZip AES encryption
+	if f.Method == 99 {
+		return nil, errs.NewErr(errs.NotSupport, "AES encrypted zip")
+	}
+	if password == "" {
+		return nil, errors.WithStack(errs.WrongArchivePassword)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	check := byte(f.CRC32 >> 24)
+	// with a data descriptor, the crc is unknown when the header is written
+	if f.Flags&0x8 != 0 {
+		check = byte(f.ModifiedTime >> 8)
+	}
+	r, err := newZipCryptoReader(raw, password, check)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Method {
+	case zip.Store:
+		return io.NopCloser(r), nil
+	case zip.Deflate:
+		return flate.NewReader(r), nil
+	default:
+		return nil, zip.ErrAlgorithm
+	}
+}
+
+// decodeName decodes names written by tools not using utf-8, which are usually gbk encoded
+func decodeName(name string, nonUTF8 bool) string {
+	if !nonUTF8 || utf8.ValidString(name) {
+		return name
+	}
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().String(name)
+	if err != nil {
+		return name
+	}
+	return decoded
+}
+
+var _ tool.Tool = (*Zip)(nil)
+
+func init() {
+	tool.RegisterTool(&Zip{})
+}
@@ -0,0 +1,84 @@
+package zip
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+// dir/a.txt with the content "hello alist\n", encrypted by `zip -P secret`
+const encryptedZip = "UEsDBAoACQAAAHykUV35hofnGAAAAAwAAAAJAAAAZGlyL2EudHh0lfckNthCksKNVhZ57AVucQBpQTRaKVSTUEsHCPmGh+cYAAAADAAAAFBLAQIeAwoACQAAAHykUV35hofnGAAAAAwAAAAJAAAAAAAAAAEAAACkgQAAAABkaXIvYS50eHRQSwUGAAAAAAEAAQA3AAAATwAAAAAA"
+
+func newStream(t *testing.T) *stream.SeekableStream {
+	data, err := base64.StdEncoding.DecodeString(encryptedZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Ctx: context.Background(),
+		Obj: &model.Object{Name: "enc.zip", Size: int64(len(data))},
+	}, &model.Link{MFile: model.NewNopMFile(bytes.NewReader(data))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ss
+}
+
+func TestGetMeta(t *testing.T) {
+	meta, err := (&Zip{}).GetMeta(newStream(t), model.ArchiveArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsEncrypted() {
+		t.Errorf("expected encrypted")
+	}
+	tree := meta.GetTree()
+	if len(tree) != 1 || tree[0].GetName() != "dir" || !tree[0].IsDir() {
+		t.Fatalf("unexpected tree: %+v", tree)
+	}
+	children := tree[0].GetChildren()
+	if len(children) != 1 || children[0].GetName() != "a.txt" || children[0].GetSize() != 12 {
+		t.Fatalf("unexpected children: %+v", children)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+		wrong    bool
+	}{
+		{password: "secret", want: "hello alist\n"},
+		{password: "", wrong: true},
+		{password: "wrong", wrong: true},
+	}
+	for _, tt := range tests {
+		rc, _, err := (&Zip{}).Extract(newStream(t), model.ArchiveInnerArgs{
+			ArchiveArgs: model.ArchiveArgs{Password: tt.password},
+			InnerPath:   "/dir/a.txt",
+		})
+		if tt.wrong {
+			if !errs.IsWrongArchivePassword(err) {
+				t.Errorf("password %q: expected wrong password, got %v", tt.password, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("password %q: %v", tt.password, err)
+		}
+		got, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("password %q: %v", tt.password, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("password %q: got %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
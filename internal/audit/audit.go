@@ -0,0 +1,126 @@
+// Package audit records who did what to the files and the settings
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queueSize = 1024
+	batchSize = 100
+)
+
+// the logs are saved in batches by the writer, so that the requests don't wait for the database
+var queue = make(chan *model.AuditLog, queueSize)
+
+// WithClient sets the address and the protocol of the client to the context of the non-web requests
+func WithClient(ctx context.Context, ip, protocol string) context.Context {
+	ctx = context.WithValue(ctx, "client_ip", ip)
+	return context.WithValue(ctx, "protocol", protocol)
+}
+
+// Entry is what's done, the rest of the log is filled from the context
+type Entry struct {
+	Operation string
+	SrcPath   string
+	DstPath   string
+	Size      int64
+}
+
+// Record logs the operation done by the user of the context, err is the result of it
+func Record(ctx context.Context, e Entry, err error) {
+	user, _ := ctx.Value("user").(*model.User)
+	c, isRequest := ctx.(*gin.Context)
+	if user == nil && !isRequest {
+		// done by the server itself, e.g. the indexer
+		return
+	}
+	l := &model.AuditLog{
+		Time:      time.Now(),
+		Operation: e.Operation,
+		SrcPath:   e.SrcPath,
+		DstPath:   e.DstPath,
+		Size:      e.Size,
+		Success:   err == nil,
+		Protocol:  model.AuditProtocolWeb,
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	// the downloads by the signed links are anonymous
+	if user != nil {
+		l.UserID, l.Username = user.ID, user.Username
+	}
+	if isRequest {
+		l.IP = c.ClientIP()
+	} else if ip, ok := ctx.Value("client_ip").(string); ok {
+		l.IP = ip
+	}
+	if protocol, ok := ctx.Value("protocol").(string); ok && protocol != "" {
+		l.Protocol = protocol
+	}
+	select {
+	case queue <- l:
+	default:
+		// the writer falls behind
+		save([]*model.AuditLog{l})
+	}
+}
+
+// Download records the file served by a request, file is nil if err isn't,
+// it's recorded by the handlers as the links are also taken by the server itself, e.g. to copy or hash
+func Download(ctx context.Context, path string, file model.Obj, err error) {
+	e := Entry{Operation: model.AuditDownload, SrcPath: path}
+	if file != nil {
+		e.Size = file.GetSize()
+	}
+	Record(ctx, e, err)
+}
+
+func save(logs []*model.AuditLog) {
+	if err := db.CreateAuditLogs(logs); err != nil {
+		log.Errorf("failed save %d audit logs: %+v", len(logs), err)
+	}
+}
+
+func write() {
+	for l := range queue {
+		logs := []*model.AuditLog{l}
+		// take what's queued at the moment
+	more:
+		for len(logs) < batchSize {
+			select {
+			case l := <-queue:
+				logs = append(logs, l)
+			default:
+				break more
+			}
+		}
+		save(logs)
+	}
+}
+
+// Init starts the writer and purges the expired logs every day
+func Init() {
+	go write()
+	cron.NewCron(24 * time.Hour).Do(purgeExpired)
+}
+
+func purgeExpired() {
+	days := setting.GetInt(conf.AuditRetention, 90)
+	if days <= 0 {
+		return
+	}
+	if err := db.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days)); err != nil {
+		log.Errorf("failed purge expired audit logs: %+v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestRecord(t *testing.T) {
+	Record(context.Background(), Entry{Operation: model.AuditDownload, SrcPath: "/a"}, nil)
+	if len(queue) != 0 {
+		t.Fatalf("expect the operation without user not to be recorded")
+	}
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 2, Username: "audit"})
+	ctx = WithClient(ctx, "127.0.0.1", model.AuditProtocolWebdav)
+	Record(ctx, Entry{Operation: model.AuditMove, SrcPath: "/a", DstPath: "/b"}, errors.New("failed"))
+	l := <-queue
+	if l.Username != "audit" || l.IP != "127.0.0.1" || l.Protocol != model.AuditProtocolWebdav {
+		t.Errorf("expect the client from the context, got %+v", l)
+	}
+	if l.Success || l.Error != "failed" || l.DstPath != "/b" {
+		t.Errorf("expect the failed move, got %+v", l)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/s3upload"
+	"github.com/alist-org/alist/v3/internal/tus"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/caarlos0/env/v9"
 	log "github.com/sirupsen/logrus"
@@ -68,14 +70,12 @@ func InitConfig() {
 		}
 		conf.Conf.TempDir = absPath
 	}
-	err := os.RemoveAll(filepath.Join(conf.Conf.TempDir))
-	if err != nil {
-		log.Errorln("failed delete temp file:", err)
-	}
-	err = os.MkdirAll(conf.Conf.TempDir, 0o777)
+	err := os.MkdirAll(conf.Conf.TempDir, 0o777)
 	if err != nil {
 		log.Fatalf("create temp dir error: %+v", err)
 	}
+	// the staged uploads are kept, they are resumed after restart
+	CleanTempDir()
 	log.Debugf("config: %+v", conf.Conf)
 	base.InitClient()
 	initURL()
@@ -111,6 +111,10 @@ func CleanTempDir() {
 		log.Errorln("failed list temp file: ", err)
 	}
 	for _, file := range files {
+		// the unfinished resumable and multipart uploads are resumed after restart
+		if file.Name() == tus.DirName || file.Name() == s3upload.DirName {
+			continue
+		}
 		if err := os.RemoveAll(filepath.Join(conf.Conf.TempDir, file.Name())); err != nil {
 			log.Errorln("failed delete temp file: ", err)
 		}
@@ -1,9 +1,12 @@
 package data
 
 import (
+	"strings"
+
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/notify"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -146,6 +149,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.IgnoreDirectLinkParams, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.StorageGroups, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.WebauthnLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.TusExpiration, Value: "24", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `hours to keep an unfinished resumable upload after it receives the last chunk`},
+		{Key: conf.TrashStorage, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `mount path of the storage to keep the removed objs of the storages that can't move`},
+		{Key: conf.TrashRetention, Value: "30", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `days to keep the objs in the trash, 0 means forever`},
+		{Key: conf.DownloadLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `KB/s of all the downloads proxied by the server, 0 means unlimited`},
+		{Key: conf.UploadLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `KB/s of all the uploads, 0 means unlimited`},
+		{Key: conf.AuditRetention, Value: "90", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `days to keep the audit logs, 0 means forever`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
@@ -153,6 +162,9 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
+		{Key: conf.IndexContent, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `index the content of the text, markdown, pdf, docx and odt files, only for bleve and meilisearch`},
+		{Key: conf.IndexContentMaxSize, Value: "10", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `the content of the larger files is not indexed, in MB`},
+		{Key: conf.ChangeFeedInterval, Value: "0", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `minutes between the polls of the changes of the storages supporting it, 0 to disable`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 
 		// SSO settings
@@ -172,13 +184,18 @@ func InitialSettings() []model.SettingItem {
 
 		// NOTIFY settings
 		{Key: conf.NotifyEnabled, Value: "false", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
-		{Key: conf.NotifyPlatform, Type: conf.TypeSelect, Options: "gotify,goCqHttpBot,serverChan,pushDeer,bark,telegramBot,dingtalkBot,weWorkBot,weWorkApp,aibotk,iGot,pushPlus,chat,email,lark,pushMe,chronocat,webhook,closed", Group: model.NOTIFICATION, Flag: model.PUBLIC},
-		{Key: conf.NotifyValue, Type: conf.TypeText, Group: model.NOTIFICATION, Flag: model.PUBLIC},
+		{Key: conf.NotifyChannels, Value: "", Type: conf.TypeText, Group: model.NOTIFICATION, Flag: model.PRIVATE,
+			Help: `JSON array of channels, e.g. [{"name":"tg","type":"telegramBot","enabled":true,"config":{...}}], notify_platform and notify_value are used if empty`},
+		{Key: conf.NotifyPlatform, Type: conf.TypeSelect, Options: strings.Join(append(notify.Types(), "closed"), ","), Group: model.NOTIFICATION, Flag: model.PUBLIC},
+		{Key: conf.NotifyValue, Type: conf.TypeText, Group: model.NOTIFICATION, Flag: model.PRIVATE},
 		{Key: conf.NotifyOnLogin, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
 		{Key: conf.NotifyOnCopySucceeded, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
 		{Key: conf.NotifyOnCopyFailed, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
 		{Key: conf.NotifyOnDownloadSucceeded, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
 		{Key: conf.NotifyOnDownloadFailed, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
+		{Key: conf.NotifyOnUploadSucceeded, Value: "false", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
+		{Key: conf.NotifyOnStorageError, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
+		{Key: conf.NotifyOnIndexBuilt, Value: "true", Type: conf.TypeBool, Group: model.NOTIFICATION, Flag: model.PUBLIC},
 
 		// ldap settings
 		{Key: conf.LdapLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.LDAP, Flag: model.PUBLIC},
@@ -197,6 +214,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.S3Buckets, Value: "[]", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 	}
 	initialSettingItems = append(initialSettingItems, tool.Tools.Items()...)
+	for _, event := range notify.Events() {
+		initialSettingItems = append(initialSettingItems, model.SettingItem{
+			Key: notify.TemplateKey(event), Value: notify.DefaultTemplate(event), Type: conf.TypeText, Group: model.NOTIFICATION, Flag: model.PRIVATE,
+			Help: `go template, the first line is the title`,
+		})
+	}
 	if flags.Dev {
 		initialSettingItems = append(initialSettingItems, []model.SettingItem{
 			{Key: "test_deprecated", Value: "test_value", Type: conf.TypeString, Flag: model.DEPRECATED},
@@ -1,6 +1,10 @@
 package bootstrap
 
 import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/search"
 	log "github.com/sirupsen/logrus"
 )
@@ -15,4 +19,17 @@ func InitIndex() {
 		progress.IsDone = true
 		search.WriteProgress(progress)
 	}
+	// the index built by an older version lacks the new fields
+	if progress.Version < search.IndexVersion && (progress.ObjCount > 0 || progress.LastDoneTime != nil) {
+		go func() {
+			// wait for storages, otherwise there is nothing to index
+			for !conf.StoragesLoaded {
+				time.Sleep(time.Second)
+			}
+			log.Infof("the search index is outdated, rebuilding")
+			if err := search.Rebuild(context.Background()); err != nil {
+				log.Errorf("rebuild index error: %+v", err)
+			}
+		}()
+	}
 }
@@ -0,0 +1,7 @@
+package bootstrap
+
+import "github.com/alist-org/alist/v3/internal/s3upload"
+
+func InitS3Uploads() {
+	s3upload.Init()
+}
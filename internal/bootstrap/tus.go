@@ -0,0 +1,7 @@
+package bootstrap
+
+import "github.com/alist-org/alist/v3/internal/tus"
+
+func InitTus() {
+	tus.Init()
+}
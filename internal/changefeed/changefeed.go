@@ -0,0 +1,44 @@
+// Package changefeed polls the change feeds of the storages, so the changes made outside,
+// e.g. by the official clients of the cloud drives, are reflected in the caches and the search index
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+// lastPolled is only accessed by the cron goroutine
+var lastPolled = make(map[string]time.Time)
+
+func Init() {
+	cron.NewCron(time.Minute).Do(poll)
+}
+
+// poll polls every storage supporting the change feed once in the interval,
+// the first poll of a storage only gets the cursor
+func poll() {
+	interval := time.Duration(setting.GetInt(conf.ChangeFeedInterval, 0)) * time.Minute
+	if interval <= 0 {
+		return
+	}
+	for _, storage := range op.GetAllStorages() {
+		if _, ok := storage.(driver.ChangeFeed); !ok {
+			continue
+		}
+		mountPath := storage.GetStorage().MountPath
+		if time.Since(lastPolled[mountPath]) < interval {
+			continue
+		}
+		lastPolled[mountPath] = time.Now()
+		if err := op.SyncChanges(context.Background(), storage); err != nil {
+			log.Warnf("failed sync changes of storage [%s]: %+v", mountPath, err)
+		}
+	}
+}
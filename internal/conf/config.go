@@ -54,10 +54,27 @@ type TaskConfig struct {
 }
 
 type TasksConfig struct {
-	Download TaskConfig `json:"download" envPrefix:"DOWNLOAD_"`
-	Transfer TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
-	Upload   TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
-	Copy     TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Download   TaskConfig `json:"download" envPrefix:"DOWNLOAD_"`
+	Transfer   TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
+	Upload     TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
+	Copy       TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Decompress TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
+	Sync       TaskConfig `json:"sync" envPrefix:"SYNC_"`
+	Dedup      TaskConfig `json:"dedup" envPrefix:"DEDUP_"`
+}
+
+// RateLimit is the budget of the requests of a user or a client ip, 0 means unlimited
+type RateLimit struct {
+	Rate        float64 `json:"rate" env:"RATE"` // requests per second
+	Burst       int     `json:"burst" env:"BURST"`
+	Concurrency int     `json:"concurrency" env:"CONCURRENCY"`
+}
+
+type RateLimits struct {
+	API      RateLimit `json:"api" envPrefix:"API_"`
+	Download RateLimit `json:"download" envPrefix:"DOWNLOAD_"`
+	WebDAV   RateLimit `json:"webdav" envPrefix:"WEBDAV_"`
+	S3       RateLimit `json:"s3" envPrefix:"S3_"`
 }
 
 type Cors struct {
@@ -79,6 +96,7 @@ type Config struct {
 	Cdn                   string      `json:"cdn" env:"CDN"`
 	JwtSecret             string      `json:"jwt_secret" env:"JWT_SECRET"`
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
+	AccessTokenExpiresIn  int         `json:"access_token_expires_in" env:"ACCESS_TOKEN_EXPIRES_IN"` // minutes, 0 means as long as the session
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
 	Scheme                Scheme      `json:"scheme"`
@@ -88,6 +106,7 @@ type Config struct {
 	Log                   LogConfig   `json:"log"`
 	DelayedStart          int         `json:"delayed_start" env:"DELAYED_START"`
 	MaxConnections        int         `json:"max_connections" env:"MAX_CONNECTIONS"`
+	RateLimits            RateLimits  `json:"rate_limits" envPrefix:"RATE_LIMITS_"`
 	TlsInsecureSkipVerify bool        `json:"tls_insecure_skip_verify" env:"TLS_INSECURE_SKIP_VERIFY"`
 	Tasks                 TasksConfig `json:"tasks" envPrefix:"TASKS_"`
 	Cors                  Cors        `json:"cors" envPrefix:"CORS_"`
@@ -103,6 +122,8 @@ func DefaultConfig() *Config {
 	transferPersistPath := filepath.Join(flags.DataDir, "tasks/transfer.json")
 	uploadPersistPath := filepath.Join(flags.DataDir, "tasks/upload.json")
 	copyPersistPath := filepath.Join(flags.DataDir, "tasks/copy.json")
+	decompressPersistPath := filepath.Join(flags.DataDir, "tasks/decompress.json")
+	syncPersistPath := filepath.Join(flags.DataDir, "tasks/sync.json")
 	return &Config{
 		Scheme: Scheme{
 			Address:    "0.0.0.0",
@@ -160,6 +181,22 @@ func DefaultConfig() *Config {
 				PersistPath:    copyPersistPath,
 				TaskPersistant: true,
 			},
+			Decompress: TaskConfig{
+				Workers:        5,
+				MaxRetry:       2,
+				PersistPath:    decompressPersistPath,
+				TaskPersistant: true,
+			},
+			Sync: TaskConfig{
+				Workers:        2,
+				MaxRetry:       1,
+				PersistPath:    syncPersistPath,
+				TaskPersistant: true,
+			},
+			Dedup: TaskConfig{
+				Workers:  1,
+				MaxRetry: 0,
+			},
 		},
 		Cors: Cors{
 			AllowOrigins: []string{"*"},
@@ -44,12 +44,21 @@ const (
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	StorageGroups           = "storage_groups"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	TusExpiration           = "tus_expiration"
+	TrashStorage            = "trash_storage"
+	TrashRetention          = "trash_retention"
+	DownloadLimit           = "download_limit"
+	UploadLimit             = "upload_limit"
+	AuditRetention          = "audit_retention"
 
 	// index
-	SearchIndex     = "search_index"
-	AutoUpdateIndex = "auto_update_index"
-	IgnorePaths     = "ignore_paths"
-	MaxIndexDepth   = "max_index_depth"
+	SearchIndex         = "search_index"
+	AutoUpdateIndex     = "auto_update_index"
+	IgnorePaths         = "ignore_paths"
+	MaxIndexDepth       = "max_index_depth"
+	IndexContent        = "index_content"
+	IndexContentMaxSize = "index_content_max_size"
+	ChangeFeedInterval  = "change_feed_interval"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -83,6 +92,11 @@ const (
 	NotifyOnCopyFailed        = "notify_on_copy_failed"
 	NotifyOnDownloadSucceeded = "notify_on_download_succeeded"
 	NotifyOnDownloadFailed    = "notify_on_download_failed"
+	NotifyOnUploadSucceeded   = "notify_on_upload_succeeded"
+	NotifyOnStorageError      = "notify_on_storage_error"
+	NotifyOnIndexBuilt        = "notify_on_index_built"
+	NotifyChannels            = "notify_channels"
+	NotifyTemplatePrefix      = "notify_template_"
 
 	//ldap
 	LdapLoginEnabled      = "ldap_login_enabled"
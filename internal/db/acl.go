@@ -0,0 +1,69 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetGroups() ([]model.Group, error) {
+	var groups []model.Group
+	if err := db.Find(&groups).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return groups, nil
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	var g model.Group
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group")
+	}
+	return &g, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateGroup(g *model.Group) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+// DeleteGroupById deletes the group with its acl rules
+func DeleteGroupById(id uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.AclRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Group{}, id).Error
+	}))
+}
+
+func GetAclRules() ([]model.AclRule, error) {
+	var rules []model.AclRule
+	if err := db.Find(&rules).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rules, nil
+}
+
+func GetAclRuleById(id uint) (*model.AclRule, error) {
+	var r model.AclRule
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl rule")
+	}
+	return &r, nil
+}
+
+func CreateAclRule(r *model.AclRule) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateAclRule(r *model.AclRule) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteAclRuleById(id uint) error {
+	return errors.WithStack(db.Delete(&model.AclRule{}, id).Error)
+}
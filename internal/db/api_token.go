@@ -0,0 +1,44 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetApiTokensByUser(userID uint) ([]model.ApiToken, error) {
+	var tokens []model.ApiToken
+	if err := db.Where("user_id = ?", userID).Order("created desc").Find(&tokens).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return tokens, nil
+}
+
+func GetApiTokenById(id uint) (*model.ApiToken, error) {
+	var token model.ApiToken
+	if err := db.First(&token, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api token")
+	}
+	return &token, nil
+}
+
+func GetApiTokenByKeyId(keyID string) (*model.ApiToken, error) {
+	var token model.ApiToken
+	if err := db.Where("key_id = ?", keyID).First(&token).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api token")
+	}
+	return &token, nil
+}
+
+func CreateApiToken(token *model.ApiToken) error {
+	return errors.WithStack(db.Create(token).Error)
+}
+
+func UpdateApiTokenLastUsed(id uint, lastUsed time.Time) error {
+	return errors.WithStack(db.Model(&model.ApiToken{}).Where("id = ?", id).Update("last_used", lastUsed).Error)
+}
+
+func DeleteApiTokenById(id uint) error {
+	return errors.WithStack(db.Delete(&model.ApiToken{}, id).Error)
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateAuditLogs(logs []*model.AuditLog) error {
+	return errors.WithStack(db.CreateInBatches(logs, 100).Error)
+}
+
+func auditLogQuery(req *model.AuditLogReq) *gorm.DB {
+	q := db.Model(&model.AuditLog{})
+	if req.Username != "" {
+		q = q.Where("username = ?", req.Username)
+	}
+	if req.Operation != "" {
+		q = q.Where("operation = ?", req.Operation)
+	}
+	if req.Protocol != "" {
+		q = q.Where("protocol = ?", req.Protocol)
+	}
+	if req.Path != "" {
+		like := "%" + req.Path + "%"
+		q = q.Where("(src_path LIKE ? OR dst_path LIKE ?)", like, like)
+	}
+	if req.Success != nil {
+		q = q.Where("success = ?", *req.Success)
+	}
+	if req.Start != nil {
+		q = q.Where("time >= ?", *req.Start)
+	}
+	if req.End != nil {
+		q = q.Where("time < ?", *req.End)
+	}
+	return q
+}
+
+// GetAuditLogs returns the logs matched by the filters in a page, the latest first
+func GetAuditLogs(req *model.AuditLogReq) (logs []model.AuditLog, count int64, err error) {
+	q := auditLogQuery(req)
+	if err = q.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = q.Order("time desc").Order("id desc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs")
+	}
+	return logs, count, nil
+}
+
+// WalkAuditLogs calls fn with the logs matched by the filters one by one, the earliest first
+func WalkAuditLogs(req *model.AuditLogReq, fn func(l *model.AuditLog) error) error {
+	rows, err := auditLogQuery(req).Order("time").Order("id").Rows()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l model.AuditLog
+		if err = db.ScanRows(rows, &l); err != nil {
+			return errors.WithStack(err)
+		}
+		if err = fn(&l); err != nil {
+			return err
+		}
+	}
+	return errors.WithStack(rows.Err())
+}
+
+func DeleteAuditLogsBefore(t time.Time) error {
+	return errors.WithStack(db.Where("time < ?", t).Delete(&model.AuditLog{}).Error)
+}
@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SyncJob), new(model.SyncRecord), new(model.UserUsage), new(model.FileOwner), new(model.Group), new(model.AclRule), new(model.Share), new(model.TusUpload), new(model.TrashItem), new(model.S3AccessKey), new(model.S3ObjectMeta), new(model.S3Upload), new(model.S3UploadPart), new(model.WebdavLock), new(model.WebdavProps), new(model.DuplicateFile), new(model.ApiToken), new(model.Session), new(model.AuditLog))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
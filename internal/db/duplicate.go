@@ -0,0 +1,51 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceDuplicateFiles replaces the result of the last scan
+func ReplaceDuplicateFiles(files []model.DuplicateFile) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.DuplicateFile{}).Error; err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		for i := range files {
+			files[i].ID = 0
+		}
+		return tx.CreateInBatches(files, 100).Error
+	}))
+}
+
+// GetDuplicateSetKeys returns the keys of the sets in a page, the largest sets first
+func GetDuplicateSetKeys(pageIndex, pageSize int) (keys []string, count int64, err error) {
+	setDB := db.Model(&model.DuplicateFile{}).Group("set_key")
+	if err := db.Table("(?) as s", setDB.Select("set_key")).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get duplicate sets count")
+	}
+	if err := setDB.Select("set_key").Order("MAX(size) DESC").Order("set_key").
+		Offset((pageIndex-1)*pageSize).Limit(pageSize).Pluck("set_key", &keys).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get duplicate sets")
+	}
+	return keys, count, nil
+}
+
+func GetDuplicateFilesByKeys(keys []string) ([]model.DuplicateFile, error) {
+	var files []model.DuplicateFile
+	if err := db.Where("set_key IN ?", keys).Order("path").Find(&files).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return files, nil
+}
+
+func DeleteDuplicateFilesById(ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return errors.WithStack(db.Delete(&model.DuplicateFile{}, ids).Error)
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetFileOwner returns nil if the file is accounted to nobody
+func GetFileOwner(path string) (*model.FileOwner, error) {
+	var owners []model.FileOwner
+	if err := db.Where("path = ?", path).Limit(1).Find(&owners).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(owners) == 0 {
+		return nil, nil
+	}
+	return &owners[0], nil
+}
+
+// SetFileOwner accounts the file to the user instead of its previous owner, it's accounted to nobody if userID is 0
+func SetFileOwner(path string, userID uint, size int64) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var owners []model.FileOwner
+		if err := tx.Where("path = ?", path).Find(&owners).Error; err != nil {
+			return err
+		}
+		if err := releaseFileOwners(tx, owners); err != nil {
+			return err
+		}
+		if userID == 0 {
+			return nil
+		}
+		if err := tx.Create(&model.FileOwner{Path: path, UserID: userID, Size: size}).Error; err != nil {
+			return err
+		}
+		return addUserUsage(tx, userID, size, 1)
+	}))
+}
+
+func getFileOwnersUnder(tx *gorm.DB, path string) ([]model.FileOwner, error) {
+	var owners []model.FileOwner
+	if err := tx.Where("path = ? OR path LIKE ?", path, strings.TrimSuffix(path, "/")+"/%").Find(&owners).Error; err != nil {
+		return nil, err
+	}
+	// the wildcards in the path may match more
+	res := owners[:0]
+	for _, o := range owners {
+		if utils.IsSubPath(path, o.Path) {
+			res = append(res, o)
+		}
+	}
+	return res, nil
+}
+
+// releaseFileOwners deletes the records and gives the usage back to the owners
+func releaseFileOwners(tx *gorm.DB, owners []model.FileOwner) error {
+	if len(owners) == 0 {
+		return nil
+	}
+	type usage struct{ size, files int64 }
+	released := make(map[uint]*usage)
+	ids := make([]uint, 0, len(owners))
+	for _, o := range owners {
+		u, ok := released[o.UserID]
+		if !ok {
+			u = &usage{}
+			released[o.UserID] = u
+		}
+		u.size += o.Size
+		u.files++
+		ids = append(ids, o.ID)
+	}
+	if err := tx.Delete(&model.FileOwner{}, ids).Error; err != nil {
+		return err
+	}
+	for userID, u := range released {
+		if err := addUserUsage(tx, userID, -u.size, -u.files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteFileOwners gives the usage of the files under the path back to their owners
+func DeleteFileOwners(path string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		owners, err := getFileOwnersUnder(tx, path)
+		if err != nil {
+			return err
+		}
+		return releaseFileOwners(tx, owners)
+	}))
+}
+
+// MoveFileOwners moves the records of the files under the path to the new path,
+// the files under the new path are regarded as overwritten
+func MoveFileOwners(srcPath, dstPath string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		owners, err := getFileOwnersUnder(tx, srcPath)
+		if err != nil || len(owners) == 0 {
+			return err
+		}
+		overwritten, err := getFileOwnersUnder(tx, dstPath)
+		if err != nil {
+			return err
+		}
+		if err = releaseFileOwners(tx, overwritten); err != nil {
+			return err
+		}
+		for _, o := range owners {
+			if err = tx.Model(&o).Update("path", dstPath+strings.TrimPrefix(o.Path, srcPath)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// ResetUserFiles accounts the files to the user only, the usage of the user is reset to them
+func ResetUserFiles(userID uint, files []model.FileOwner) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.FileOwner{}).Error; err != nil {
+			return err
+		}
+		usage := model.UserUsage{UserID: userID}
+		paths := make([]string, 0, len(files))
+		for i := range files {
+			files[i].ID, files[i].UserID = 0, userID
+			usage.UsedSize += files[i].Size
+			usage.UsedFiles++
+			paths = append(paths, files[i].Path)
+		}
+		// the files accounted to the others before
+		for i := 0; i < len(paths); i += 100 {
+			var owners []model.FileOwner
+			if err := tx.Where("path IN ?", paths[i:min(i+100, len(paths))]).Find(&owners).Error; err != nil {
+				return err
+			}
+			if err := releaseFileOwners(tx, owners); err != nil {
+				return err
+			}
+		}
+		if len(files) > 0 {
+			if err := tx.CreateInBatches(files, 100).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(&usage).Error
+	}))
+}
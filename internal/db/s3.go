@@ -0,0 +1,186 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetS3AccessKeysByUser(userID uint) ([]model.S3AccessKey, error) {
+	var keys []model.S3AccessKey
+	if err := db.Where("user_id = ?", userID).Order("created desc").Find(&keys).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return keys, nil
+}
+
+func GetS3AccessKeyById(id uint) (*model.S3AccessKey, error) {
+	var key model.S3AccessKey
+	if err := db.First(&key, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 access key")
+	}
+	return &key, nil
+}
+
+func GetS3AccessKeyByAccessKeyId(accessKeyID string) (*model.S3AccessKey, error) {
+	var key model.S3AccessKey
+	if err := db.Where("access_key_id = ?", accessKeyID).First(&key).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 access key")
+	}
+	return &key, nil
+}
+
+func CountS3AccessKeys() (int64, error) {
+	var count int64
+	if err := db.Model(&model.S3AccessKey{}).Count(&count).Error; err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return count, nil
+}
+
+func CreateS3AccessKey(key *model.S3AccessKey) error {
+	return errors.WithStack(db.Create(key).Error)
+}
+
+func DeleteS3AccessKeyById(id uint) error {
+	return errors.WithStack(db.Delete(&model.S3AccessKey{}, id).Error)
+}
+
+// GetS3ObjectMeta returns nil if the obj has no metadata
+func GetS3ObjectMeta(path string) (map[string]string, error) {
+	var m model.S3ObjectMeta
+	if err := db.Where("path = ?", path).Limit(1).Find(&m).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 object meta")
+	}
+	return m.Meta, nil
+}
+
+// SaveS3ObjectMeta replaces the metadata of the path
+func SaveS3ObjectMeta(m *model.S3ObjectMeta) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("path = ?", m.Path).Delete(&model.S3ObjectMeta{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(m).Error
+	}))
+}
+
+// getS3ObjectMetasUnder returns the metadata of the path and its sub paths
+func getS3ObjectMetasUnder(tx *gorm.DB, path string) ([]model.S3ObjectMeta, error) {
+	var metas []model.S3ObjectMeta
+	if err := tx.Where("path = ? OR path LIKE ?", path, strings.TrimSuffix(path, "/")+"/%").Find(&metas).Error; err != nil {
+		return nil, err
+	}
+	// the wildcards in the path may match more
+	res := metas[:0]
+	for _, m := range metas {
+		if utils.IsSubPath(path, m.Path) {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// MoveS3ObjectMetas moves the metadata of the path and its sub paths to the new path
+func MoveS3ObjectMetas(srcPath, dstPath string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		metas, err := getS3ObjectMetasUnder(tx, srcPath)
+		if err != nil {
+			return err
+		}
+		for _, m := range metas {
+			newPath := dstPath + strings.TrimPrefix(m.Path, srcPath)
+			if err = tx.Where("path = ?", newPath).Delete(&model.S3ObjectMeta{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Model(&m).Update("path", newPath).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// DeleteS3ObjectMetas deletes the metadata of the path and its sub paths
+func DeleteS3ObjectMetas(path string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		metas, err := getS3ObjectMetasUnder(tx, path)
+		if err != nil || len(metas) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(metas))
+		for _, m := range metas {
+			ids = append(ids, m.ID)
+		}
+		return tx.Delete(&model.S3ObjectMeta{}, ids).Error
+	}))
+}
+
+func GetS3UploadById(id string) (*model.S3Upload, error) {
+	var u model.S3Upload
+	if err := db.Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 upload")
+	}
+	return &u, nil
+}
+
+func GetS3UploadsByUser(userID uint, bucket string) ([]model.S3Upload, error) {
+	var uploads []model.S3Upload
+	if err := db.Where("user_id = ? AND bucket = ?", userID, bucket).Order("object_key, created").Find(&uploads).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uploads, nil
+}
+
+func GetS3Uploads() ([]model.S3Upload, error) {
+	var uploads []model.S3Upload
+	if err := db.Find(&uploads).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uploads, nil
+}
+
+// GetIdleS3Uploads returns the uploads that receive no part since the time
+func GetIdleS3Uploads(since time.Time) ([]model.S3Upload, error) {
+	var uploads []model.S3Upload
+	if err := db.Where("updated < ?", since).Find(&uploads).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uploads, nil
+}
+
+func CreateS3Upload(u *model.S3Upload) error {
+	return errors.WithStack(db.Create(u).Error)
+}
+
+func GetS3UploadParts(uploadID string) ([]model.S3UploadPart, error) {
+	var parts []model.S3UploadPart
+	if err := db.Where("upload_id = ?", uploadID).Order("part_number").Find(&parts).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parts, nil
+}
+
+// SaveS3UploadPart creates or replaces the part, the upload is touched so that it's not regarded as idle
+func SaveS3UploadPart(p *model.S3UploadPart) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(p).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.S3Upload{}).Where("id = ?", p.UploadID).Update("updated", p.Updated).Error
+	}))
+}
+
+// DeleteS3UploadById deletes the upload with its parts
+func DeleteS3UploadById(id string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", id).Delete(&model.S3UploadPart{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.S3Upload{}).Error
+	}))
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"fmt"
 	stdpath "path"
+	"regexp"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
@@ -55,37 +56,117 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 }
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
-	var searchDB *gorm.DB
-	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
-		keywordsClause := db.Where("1 = 1")
-		for _, keyword := range strings.Fields(req.Keywords) {
-			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
-		}
-		searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
-	} else {
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+	// sqlite has no regexp function, the names are matched after the other filters
+	var re *regexp.Regexp
+	switch req.Mode {
+	case model.SearchModePhrase:
+		searchDB = searchDB.Where("name LIKE ?", fmt.Sprintf("%%%s%%", req.Keywords))
+	case model.SearchModeRegex:
 		switch conf.Conf.Database.Type {
 		case "mysql":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+			searchDB = searchDB.Where("name REGEXP ?", req.Keywords)
 		case "postgres":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			searchDB = searchDB.Where("name ~ ?", req.Keywords)
+		default:
+			var err error
+			if re, err = regexp.Compile(req.Keywords); err != nil {
+				return nil, 0, errors.WithStack(err)
+			}
+		}
+	default:
+		if !useFullText || conf.Conf.Database.Type == "sqlite3" {
+			keywordsClause := db.Where("1 = 1")
+			for _, keyword := range strings.Fields(req.Keywords) {
+				keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
+			}
+			searchDB = searchDB.Where(keywordsClause)
+		} else {
+			switch conf.Conf.Database.Type {
+			case "mysql":
+				searchDB = searchDB.Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+			case "postgres":
+				searchDB = searchDB.Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			}
 		}
 	}
 
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
-		searchDB.Where(db.Where("is_dir = ?", isDir))
+		searchDB = searchDB.Where("is_dir = ?", isDir)
+	}
+	if req.MinSize > 0 {
+		searchDB = searchDB.Where("size >= ?", req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		searchDB = searchDB.Where("size <= ?", req.MaxSize)
+	}
+	if !req.ModifiedAfter.IsZero() {
+		searchDB = searchDB.Where("modified >= ?", req.ModifiedAfter)
+	}
+	if !req.ModifiedBefore.IsZero() {
+		searchDB = searchDB.Where("modified <= ?", req.ModifiedBefore)
+	}
+	if len(req.Types) > 0 {
+		searchDB = searchDB.Where("obj_type IN ?", req.Types)
+	}
+	if len(req.Exts) > 0 {
+		extsClause := db.Where("1 = 0")
+		for _, ext := range req.Exts {
+			extsClause = extsClause.Or("LOWER(name) LIKE ?", "%."+ext)
+		}
+		searchDB = searchDB.Where("is_dir = ?", false).Where(extsClause)
+	}
+	order := "name"
+	if req.OrderBy != "" {
+		order = req.OrderBy
+	}
+	if req.OrderDirection == "desc" {
+		order += " desc"
+	} else {
+		order += " asc"
 	}
 
+	if re != nil {
+		return matchSearchNodes(searchDB.Order(order), re, req.PageReq)
+	}
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+// matchSearchNodes scans the nodes one by one to match the names by the regex,
+// only the nodes of the requested page are kept
+func matchSearchNodes(searchDB *gorm.DB, re *regexp.Regexp, page model.PageReq) ([]model.SearchNode, int64, error) {
+	rows, err := searchDB.Rows()
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get search items")
+	}
+	defer rows.Close()
+	var (
+		count int64
+		files []model.SearchNode
+	)
+	from := int64((page.Page - 1) * page.PerPage)
+	for rows.Next() {
+		var node model.SearchNode
+		if err = db.ScanRows(rows, &node); err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+		if !re.MatchString(node.Name) {
+			continue
+		}
+		if count >= from && len(files) < page.PerPage {
+			files = append(files, node)
+		}
+		count++
+	}
+	return files, count, errors.WithStack(rows.Err())
+}
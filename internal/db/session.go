@@ -0,0 +1,73 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSessionById(id string) (*model.Session, error) {
+	var s model.Session
+	if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get session")
+	}
+	return &s, nil
+}
+
+func GetSessionsByUser(userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := db.Where("user_id = ? AND expires > ?", userID, time.Now()).Order("last_seen desc").Find(&sessions).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return sessions, nil
+}
+
+func CreateSession(s *model.Session) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+// RefreshSession saves the refreshed session only if the refresh token isn't rotated by another request,
+// so that a refresh token can be used only once even by the concurrent requests
+func RefreshSession(s *model.Session, oldRefreshHash string) error {
+	res := db.Model(&model.Session{}).
+		Where("id = ? AND refresh_hash = ?", s.ID, oldRefreshHash).
+		Updates(map[string]any{
+			"refresh_hash": s.RefreshHash,
+			"last_seen":    s.LastSeen,
+			"ip":           s.IP,
+			"expires":      s.Expires,
+		})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.WithStack(errs.SessionRevoked)
+	}
+	return nil
+}
+
+func UpdateSessionLastSeen(id string, lastSeen time.Time) error {
+	return errors.WithStack(db.Model(&model.Session{}).Where("id = ?", id).Update("last_seen", lastSeen).Error)
+}
+
+func DeleteSessionById(id string) error {
+	return errors.WithStack(db.Where("id = ?", id).Delete(&model.Session{}).Error)
+}
+
+// DeleteSessionsByUser deletes the sessions of the user and returns the ids of them
+func DeleteSessionsByUser(userID uint) ([]string, error) {
+	var ids []string
+	if err := db.Model(&model.Session{}).Where("user_id = ?", userID).Pluck("id", &ids).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return ids, errors.WithStack(db.Where("id IN ?", ids).Delete(&model.Session{}).Error)
+}
+
+func DeleteExpiredSessions() error {
+	return errors.WithStack(db.Where("expires <= ?", time.Now()).Delete(&model.Session{}).Error)
+}
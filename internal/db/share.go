@@ -0,0 +1,67 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShares() ([]model.Share, error) {
+	var shares []model.Share
+	if err := db.Order("created desc").Find(&shares).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return shares, nil
+}
+
+func GetSharesByCreator(creatorID uint) ([]model.Share, error) {
+	var shares []model.Share
+	if err := db.Where("creator_id = ?", creatorID).Order("created desc").Find(&shares).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return shares, nil
+}
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where("id = ?", id).Delete(&model.Share{}).Error)
+}
+
+func AddShareView(id string) error {
+	return errors.WithStack(db.Model(&model.Share{}).Where("id = ?", id).Updates(map[string]any{
+		"views":       gorm.Expr("views + 1"),
+		"last_access": time.Now(),
+	}).Error)
+}
+
+// AddShareDownload counts a download in one statement,
+// so that the concurrent downloads can't exceed the limit
+func AddShareDownload(id string) error {
+	res := db.Model(&model.Share{}).
+		Where("id = ? AND (max_downloads = 0 OR downloads < max_downloads)", id).
+		Updates(map[string]any{
+			"downloads":   gorm.Expr("downloads + 1"),
+			"last_access": time.Now(),
+		})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.WithStack(errs.ShareExhausted)
+	}
+	return nil
+}
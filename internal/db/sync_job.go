@@ -0,0 +1,71 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetSyncJobs() ([]model.SyncJob, error) {
+	var jobs []model.SyncJob
+	if err := db.Find(&jobs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return jobs, nil
+}
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	var job model.SyncJob
+	if err := db.First(&job, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get sync job")
+	}
+	return &job, nil
+}
+
+func CreateSyncJob(job *model.SyncJob) error {
+	return errors.WithStack(db.Create(job).Error)
+}
+
+func UpdateSyncJob(job *model.SyncJob) error {
+	return errors.WithStack(db.Save(job).Error)
+}
+
+// UpdateSyncJobStatus only updates the result of the last run,
+// so that the job edited while running is not overwritten
+func UpdateSyncJobStatus(job *model.SyncJob) error {
+	return errors.WithStack(db.Model(job).Select("last_run", "status").Updates(job).Error)
+}
+
+func DeleteSyncJobById(id uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", id).Delete(&model.SyncRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.SyncJob{}, id).Error
+	}))
+}
+
+func GetSyncRecords(jobID uint) ([]model.SyncRecord, error) {
+	var records []model.SyncRecord
+	if err := db.Where("job_id = ?", jobID).Find(&records).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return records, nil
+}
+
+// ReplaceSyncRecords replaces all records of the job
+func ReplaceSyncRecords(jobID uint, records []model.SyncRecord) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", jobID).Delete(&model.SyncRecord{}).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		for i := range records {
+			records[i].ID = 0
+			records[i].JobID = jobID
+		}
+		return tx.CreateInBatches(records, 100).Error
+	}))
+}
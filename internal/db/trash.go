@@ -0,0 +1,41 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetTrashItems() ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := db.Order("deleted desc").Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	var item model.TrashItem
+	if err := db.First(&item, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get trash item")
+	}
+	return &item, nil
+}
+
+// GetTrashItemsBefore returns the items removed before the time
+func GetTrashItemsBefore(t time.Time) ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := db.Where("deleted < ?", t).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
+
+func CreateTrashItem(item *model.TrashItem) error {
+	return errors.WithStack(db.Create(item).Error)
+}
+
+func DeleteTrashItemById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrashItem{}, id).Error)
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetTusUploadById(id string) (*model.TusUpload, error) {
+	var u model.TusUpload
+	if err := db.Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get upload")
+	}
+	return &u, nil
+}
+
+func GetTusUploads() ([]model.TusUpload, error) {
+	var uploads []model.TusUpload
+	if err := db.Find(&uploads).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uploads, nil
+}
+
+// GetIdleTusUploads returns the uploads that receive nothing since the time
+func GetIdleTusUploads(since time.Time) ([]model.TusUpload, error) {
+	var uploads []model.TusUpload
+	if err := db.Where("updated < ?", since).Find(&uploads).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uploads, nil
+}
+
+func CreateTusUpload(u *model.TusUpload) error {
+	return errors.WithStack(db.Create(u).Error)
+}
+
+func UpdateTusUploadOffset(u *model.TusUpload) error {
+	return errors.WithStack(db.Model(u).Select("offset", "updated").Updates(u).Error)
+}
+
+func DeleteTusUploadById(id string) error {
+	return errors.WithStack(db.Where("id = ?", id).Delete(&model.TusUpload{}).Error)
+}
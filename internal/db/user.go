@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
 func GetUserByRole(role int) (*model.User, error) {
@@ -61,9 +62,55 @@ func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err err
 }
 
 func DeleteUserById(id uint) error {
+	if err := db.Delete(&model.UserUsage{}, id).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if err := db.Where("user_id = ?", id).Delete(&model.FileOwner{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	// the shares are revoked with the user
+	if err := db.Where("creator_id = ?", id).Delete(&model.Share{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	// so are the s3 access keys and the api tokens
+	if err := db.Where("user_id = ?", id).Delete(&model.S3AccessKey{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if err := db.Where("user_id = ?", id).Delete(&model.ApiToken{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if err := db.Where("user_id = ?", id).Delete(&model.Session{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
 	return errors.WithStack(db.Delete(&model.User{}, id).Error)
 }
 
+// GetUserUsage returns an empty usage if the user never writes
+func GetUserUsage(userID uint) (*model.UserUsage, error) {
+	usage := model.UserUsage{UserID: userID}
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&usage).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get user usage")
+	}
+	return &usage, nil
+}
+
+// AddUserUsage adds the deltas to the usage, the result is never negative
+func AddUserUsage(userID uint, size, files int64) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		return addUserUsage(tx, userID, size, files)
+	}))
+}
+
+func addUserUsage(tx *gorm.DB, userID uint, size, files int64) error {
+	usage := model.UserUsage{UserID: userID}
+	if err := tx.Where("user_id = ?", userID).Limit(1).Find(&usage).Error; err != nil {
+		return err
+	}
+	usage.UsedSize = max(usage.UsedSize+size, 0)
+	usage.UsedFiles = max(usage.UsedFiles+files, 0)
+	return tx.Save(&usage).Error
+}
+
 func UpdateAuthn(userID uint, authn string) error {
 	return db.Model(&model.User{ID: userID}).Update("authn", authn).Error
 }
@@ -0,0 +1,172 @@
+package db
+
+import (
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// alive filters out the expired locks which are not swept yet
+func alive(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("duration < 0 OR expiry > ?", now)
+	}
+}
+
+func GetWebdavLockByToken(token string, now time.Time) (*model.WebdavLock, error) {
+	var locks []model.WebdavLock
+	if err := db.Scopes(alive(now)).Where("token = ?", token).Limit(1).Find(&locks).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(locks) == 0 {
+		return nil, errors.WithStack(gorm.ErrRecordNotFound)
+	}
+	return &locks[0], nil
+}
+
+func GetWebdavLocksByTokens(tokens []string, now time.Time) ([]model.WebdavLock, error) {
+	var locks []model.WebdavLock
+	if len(tokens) == 0 {
+		return locks, nil
+	}
+	if err := db.Scopes(alive(now)).Where("token IN ?", tokens).Find(&locks).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return locks, nil
+}
+
+// GetWebdavLocksOnPath returns the locks on the path and the infinite depth locks on its parents,
+// the locks on its children are included too if tree is true
+func GetWebdavLocksOnPath(path string, tree bool, now time.Time) ([]model.WebdavLock, error) {
+	path = utils.FixAndCleanPath(path)
+	var parents []string
+	for p := path; p != "/"; {
+		p = stdpath.Dir(p)
+		parents = append(parents, p)
+	}
+	cond := db.Where("root = ?", path)
+	if len(parents) > 0 {
+		cond = cond.Or("root IN ? AND zero_depth = ?", parents, false)
+	}
+	if tree {
+		if path == "/" {
+			cond = db.Where("1 = 1")
+		} else {
+			cond = cond.Or("root LIKE ?", path+"/%")
+		}
+	}
+	var locks []model.WebdavLock
+	if err := db.Scopes(alive(now)).Where(cond).Find(&locks).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// LIKE treats _ and % as wildcards, so filter the children again
+	res := locks[:0]
+	for _, l := range locks {
+		if l.Root == path || (!l.ZeroDepth && utils.IsSubPath(l.Root, path)) || (tree && utils.IsSubPath(path, l.Root)) {
+			res = append(res, l)
+		}
+	}
+	return res, nil
+}
+
+func CreateWebdavLock(l *model.WebdavLock) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func UpdateWebdavLockExpiry(l *model.WebdavLock) error {
+	return errors.WithStack(db.Model(l).Select("duration", "expiry").Updates(l).Error)
+}
+
+func DeleteWebdavLockByToken(token string) (bool, error) {
+	res := db.Where("token = ?", token).Delete(&model.WebdavLock{})
+	return res.RowsAffected > 0, errors.WithStack(res.Error)
+}
+
+func DeleteExpiredWebdavLocks(now time.Time) error {
+	return errors.WithStack(db.Where("duration >= 0 AND expiry <= ?", now).Delete(&model.WebdavLock{}).Error)
+}
+
+func GetWebdavProps(path string) ([]model.WebdavProp, error) {
+	var props []model.WebdavProps
+	if err := db.Where("path = ?", path).Limit(1).Find(&props).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(props) == 0 {
+		return nil, nil
+	}
+	return props[0].Props, nil
+}
+
+// GetWebdavPropsUnder returns the dead properties of the path and its sub paths
+func GetWebdavPropsUnder(path string) ([]model.WebdavProps, error) {
+	props, err := getWebdavPropsUnder(db, path)
+	return props, errors.WithStack(err)
+}
+
+// SaveWebdavProps replaces the dead properties of the path, they are deleted if empty
+func SaveWebdavProps(p *model.WebdavProps) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("path = ?", p.Path).Delete(&model.WebdavProps{}).Error; err != nil {
+			return err
+		}
+		if len(p.Props) == 0 {
+			return nil
+		}
+		return tx.Create(p).Error
+	}))
+}
+
+func getWebdavPropsUnder(tx *gorm.DB, path string) ([]model.WebdavProps, error) {
+	var props []model.WebdavProps
+	if err := tx.Where("path = ? OR path LIKE ?", path, strings.TrimSuffix(path, "/")+"/%").Find(&props).Error; err != nil {
+		return nil, err
+	}
+	// the wildcards in the path may match more
+	res := props[:0]
+	for _, p := range props {
+		if utils.IsSubPath(path, p.Path) {
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
+
+// MoveWebdavProps moves the dead properties of the path and its sub paths to the new path
+func MoveWebdavProps(srcPath, dstPath string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		props, err := getWebdavPropsUnder(tx, srcPath)
+		if err != nil {
+			return err
+		}
+		for _, p := range props {
+			newPath := dstPath + strings.TrimPrefix(p.Path, srcPath)
+			if err = tx.Where("path = ?", newPath).Delete(&model.WebdavProps{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Model(&p).Update("path", newPath).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// DeleteWebdavProps deletes the dead properties of the path and its sub paths
+func DeleteWebdavProps(path string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		props, err := getWebdavPropsUnder(tx, path)
+		if err != nil || len(props) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(props))
+		for _, p := range props {
+			ids = append(ids, p.ID)
+		}
+		return tx.Delete(&model.WebdavProps{}, ids).Error
+	}))
+}
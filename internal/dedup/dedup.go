@@ -0,0 +1,205 @@
+// Package dedup finds the files stored more than once across the storages
+package dedup
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Scan adds a task to scan the duplicates under the paths, it fails if a scan is running
+func Scan(ctx context.Context, paths []string) (task.TaskExtensionInfo, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no path to scan")
+	}
+	for i := range paths {
+		paths[i] = utils.FixAndCleanPath(paths[i])
+	}
+	if len(ScanTaskManager.GetByState(tache.StatePending, tache.StateRunning, tache.StateWaitingRetry, tache.StateCanceling)) > 0 {
+		return nil, errors.New("a scan is running")
+	}
+	t := &ScanTask{Paths: paths}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t.SetCreator(taskCreator)
+	ScanTaskManager.Add(t)
+	return t, nil
+}
+
+// GetSets returns the duplicate sets found by the last scan in a page
+func GetSets(pageIndex, pageSize int) ([]model.DuplicateSet, int64, error) {
+	keys, count, err := db.GetDuplicateSetKeys(pageIndex, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(keys) == 0 {
+		return []model.DuplicateSet{}, count, nil
+	}
+	files, err := db.GetDuplicateFilesByKeys(keys)
+	if err != nil {
+		return nil, 0, err
+	}
+	byKey := make(map[string][]model.DuplicateFile, len(keys))
+	for _, f := range files {
+		byKey[f.SetKey] = append(byKey[f.SetKey], f)
+	}
+	sets := make([]model.DuplicateSet, 0, len(keys))
+	for _, key := range keys {
+		members := byKey[key]
+		if len(members) == 0 {
+			continue
+		}
+		sets = append(sets, model.DuplicateSet{
+			Key:     key,
+			Size:    members[0].Size,
+			Partial: members[0].Partial,
+			Files:   members,
+		})
+	}
+	return sets, count, nil
+}
+
+// Resolve keeps one file of the set and deletes the others or replaces them with the shortcuts to the kept one,
+// apiUrl is the address the shortcuts point to. The files of a partial set are hashed fully first,
+// those differ from the kept one are left alone
+func Resolve(ctx context.Context, req model.DuplicateResolveReq, apiUrl string) error {
+	files, err := db.GetDuplicateFilesByKeys([]string{req.Key})
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.Errorf("duplicate set [%s] not found", req.Key)
+	}
+	keep := utils.FixAndCleanPath(req.Keep)
+	var keepFile *model.DuplicateFile
+	for i := range files {
+		if files[i].Path == keep {
+			keepFile = &files[i]
+		}
+	}
+	if keepFile == nil {
+		return errors.Errorf("[%s] is not in the duplicate set", keep)
+	}
+	switch req.Action {
+	case model.DuplicateActionDelete, model.DuplicateActionLink:
+	default:
+		return errors.Errorf("unknown action: %s", req.Action)
+	}
+	// the head and the tail being equal doesn't make the files the same
+	var keepHash string
+	if keepFile.Partial {
+		if keepHash, err = fullHash(ctx, keep); err != nil {
+			return errors.WithMessagef(err, "failed hash [%s]", keep)
+		}
+	}
+	var resolved []uint
+	var lastErr error
+	for _, f := range files {
+		if f.ID == keepFile.ID {
+			continue
+		}
+		if f.Partial {
+			if err := sameContent(ctx, f.Path, keepHash); err != nil {
+				log.Errorf("failed resolve duplicate [%s]: %+v", f.Path, err)
+				lastErr = err
+				continue
+			}
+		}
+		if err := resolve(ctx, f, keep, req.Action, apiUrl); err != nil {
+			log.Errorf("failed resolve duplicate [%s]: %+v", f.Path, err)
+			lastErr = err
+			continue
+		}
+		resolved = append(resolved, f.ID)
+	}
+	failed := len(files) - 1 - len(resolved)
+	// the kept file is not a duplicate any more if all the others are resolved
+	if failed == 0 {
+		resolved = append(resolved, keepFile.ID)
+	}
+	if err := db.DeleteDuplicateFilesById(resolved...); err != nil {
+		return err
+	}
+	if lastErr != nil {
+		return errors.WithMessagef(lastErr, "failed resolve %d of %d files, the last error", failed, len(files)-1)
+	}
+	return nil
+}
+
+func sameContent(ctx context.Context, path, keepHash string) error {
+	h, err := fullHash(ctx, path)
+	if err != nil {
+		return errors.WithMessagef(err, "failed hash [%s]", path)
+	}
+	if h != keepHash {
+		return errors.Errorf("[%s] differs from the kept file", path)
+	}
+	return nil
+}
+
+// isAlias reports whether the path is served by an alias, which only points to the other storages
+func isAlias(path string) bool {
+	storage, _, err := op.GetStorageAndActualPath(path)
+	return err == nil && storage.Config().Name == "Alias"
+}
+
+// samePlace reports whether the paths are stored at the same place, the balanced mirrors count as one storage,
+// the paths through an alias can't be told, so they are regarded as the same
+func samePlace(a, b string) (bool, error) {
+	if isAlias(a) || isAlias(b) {
+		return true, nil
+	}
+	storageA, actualA, err := op.GetStorageAndActualPath(a)
+	if err != nil {
+		return false, err
+	}
+	storageB, actualB, err := op.GetStorageAndActualPath(b)
+	if err != nil {
+		return false, err
+	}
+	return utils.GetActualMountPath(storageA.GetStorage().MountPath) == utils.GetActualMountPath(storageB.GetStorage().MountPath) &&
+		actualA == actualB, nil
+}
+
+func resolve(ctx context.Context, f model.DuplicateFile, keep, action, apiUrl string) error {
+	// removing the other path of the kept file removes the only copy
+	same, err := samePlace(f.Path, keep)
+	if err != nil {
+		return err
+	}
+	if same {
+		return errors.Errorf("[%s] may be the same file as the kept one", f.Path)
+	}
+	if action == model.DuplicateActionLink {
+		if err := putShortcut(ctx, f.Path, keep, apiUrl); err != nil {
+			return errors.WithMessage(err, "failed put shortcut")
+		}
+	}
+	return fs.Remove(ctx, f.Path)
+}
+
+// putShortcut puts an internet shortcut beside the file, which points to the page of the target,
+// so that it's opened with the permissions of whoever follows it
+func putShortcut(ctx context.Context, path, target, apiUrl string) error {
+	content := fmt.Sprintf("[InternetShortcut]\r\nURL=%s%s\r\n",
+		strings.TrimSuffix(apiUrl, "/"), utils.EncodePath(target, true))
+	return fs.PutDirectly(ctx, stdpath.Dir(path), &stream.FileStream{
+		Obj: &model.Object{
+			Name: stdpath.Base(path) + ".url",
+			Size: int64(len(content)),
+		},
+		Reader:   strings.NewReader(content),
+		Mimetype: "application/internet-shortcut",
+	})
+}
@@ -0,0 +1,199 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// file is a file found by the scan
+type file struct {
+	path string
+	obj  model.Obj
+}
+
+// partialHasher returns the hash of the head and the tail of the file
+type partialHasher func(ctx context.Context, f file) (string, error)
+
+// unionFind joins the files of a size into the sets,
+// the key of a set is the first hash the files are matched by
+type unionFind struct {
+	parent  []int
+	key     []string
+	partial []bool
+}
+
+func newUnionFind(n int) *unionFind {
+	u := &unionFind{parent: make([]int, n), key: make([]string, n), partial: make([]bool, n)}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *unionFind) union(a, b int, key string, partial bool) {
+	ra, rb := u.find(a), u.find(b)
+	if ra == rb {
+		return
+	}
+	u.parent[rb] = ra
+	if u.key[ra] == "" {
+		u.key[ra] = u.key[rb]
+	}
+	if u.key[ra] == "" {
+		u.key[ra] = key
+	}
+	u.partial[ra] = u.partial[ra] || u.partial[rb] || partial
+}
+
+// conflict reports whether the files have different hashes of the same type,
+// they can't be the same even if the partial hashes are equal
+func conflict(a, b model.Obj) bool {
+	for ht, v := range a.GetHash().All() {
+		if w := b.GetHash().GetHash(ht); v != "" && w != "" && !strings.EqualFold(v, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// setsConflict reports whether any files of the sets of a and b conflict
+func setsConflict(u *unionFind, fs []file, a, b int) bool {
+	ra, rb := u.find(a), u.find(b)
+	for i := range fs {
+		if u.find(i) != ra {
+			continue
+		}
+		for j := range fs {
+			if u.find(j) == rb && conflict(fs[i].obj, fs[j].obj) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// group finds the duplicate sets of the files, the files are matched by the hashes provided by the drivers,
+// the files of the same size can't be matched by the hashes are matched by the partial hashes
+func group(ctx context.Context, files []file, hasher partialHasher) []model.DuplicateSet {
+	bySize := make(map[int64][]file)
+	for _, f := range files {
+		// the empty files are all the same, they are not worth listing
+		if f.obj.IsDir() || f.obj.GetSize() <= 0 {
+			continue
+		}
+		bySize[f.obj.GetSize()] = append(bySize[f.obj.GetSize()], f)
+	}
+	var sets []model.DuplicateSet
+	for size, fs := range bySize {
+		if len(fs) < 2 {
+			continue
+		}
+		if utils.IsCanceled(ctx) {
+			return nil
+		}
+		sets = append(sets, groupSize(ctx, size, fs, hasher)...)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		if sets[i].Size != sets[j].Size {
+			return sets[i].Size > sets[j].Size
+		}
+		return sets[i].Key < sets[j].Key
+	})
+	return sets
+}
+
+func groupSize(ctx context.Context, size int64, fs []file, hasher partialHasher) []model.DuplicateSet {
+	u := newUnionFind(len(fs))
+	seen := make(map[string]int)
+	for i, f := range fs {
+		for ht, v := range f.obj.GetHash().All() {
+			if v == "" {
+				continue
+			}
+			key := ht.Name + ":" + strings.ToLower(v)
+			if j, ok := seen[key]; ok {
+				u.union(j, i, key, false)
+			} else {
+				seen[key] = i
+			}
+		}
+	}
+	// the files not matched by the hashes are compared with the others by the partial hashes,
+	// a matched set is represented by one of its files
+	count := make(map[int]int)
+	for i := range fs {
+		count[u.find(i)]++
+	}
+	var candidates []int
+	single := 0
+	for root, n := range count {
+		if n == 1 {
+			single++
+		}
+		candidates = append(candidates, root)
+	}
+	if single > 0 && len(candidates) > 1 {
+		sort.Ints(candidates)
+		partials := make(map[string][]int)
+		for _, i := range candidates {
+			h, err := hasher(ctx, fs[i])
+			if err != nil {
+				log.Warnf("failed hash %s: %+v", fs[i].path, err)
+				continue
+			}
+			key := "partial:" + h
+			for _, j := range partials[key] {
+				if !setsConflict(u, fs, j, i) {
+					u.union(j, i, key, true)
+					break
+				}
+			}
+			partials[key] = append(partials[key], i)
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range fs {
+		root := u.find(i)
+		members[root] = append(members[root], i)
+	}
+	var sets []model.DuplicateSet
+	for root, is := range members {
+		if len(is) < 2 {
+			continue
+		}
+		set := model.DuplicateSet{
+			Key:     fmt.Sprintf("%d-%s", size, u.key[root]),
+			Size:    size,
+			Partial: u.partial[root],
+		}
+		for _, i := range is {
+			set.Files = append(set.Files, model.DuplicateFile{
+				SetKey:   set.Key,
+				Partial:  set.Partial,
+				Path:     fs[i].path,
+				Size:     size,
+				Modified: fs[i].obj.ModTime(),
+			})
+		}
+		sort.Slice(set.Files, func(i, j int) bool {
+			return set.Files[i].Path < set.Files[j].Path
+		})
+		sets = append(sets, set)
+	}
+	return sets
+}
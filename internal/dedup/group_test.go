@@ -0,0 +1,60 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func newFile(path string, size int64, md5 string) file {
+	obj := &model.Object{Name: path, Size: size}
+	if md5 != "" {
+		obj.HashInfo = utils.NewHashInfo(utils.MD5, md5)
+	}
+	return file{path: path, obj: obj}
+}
+
+func TestGroup(t *testing.T) {
+	files := []file{
+		newFile("/a/video.mp4", 100, "aaa"),
+		newFile("/b/video.mp4", 100, "AAA"),
+		// no hash, matched by the partial hash
+		newFile("/c/video.mp4", 100, ""),
+		// the same partial hash but a different md5
+		newFile("/d/video.mp4", 100, "bbb"),
+		newFile("/a/other.mp4", 200, "ccc"),
+		newFile("/a/empty", 0, ""),
+		newFile("/b/empty", 0, ""),
+	}
+	partials := map[string]string{
+		"/a/video.mp4": "x",
+		"/b/video.mp4": "x",
+		"/c/video.mp4": "x",
+		"/d/video.mp4": "x",
+	}
+	sets := group(context.Background(), files, func(ctx context.Context, f file) (string, error) {
+		return partials[f.path], nil
+	})
+	if len(sets) != 1 {
+		t.Fatalf("expect 1 set, got %+v", sets)
+	}
+	set := sets[0]
+	if !set.Partial || set.Size != 100 {
+		t.Errorf("expect a partial set of size 100, got %+v", set)
+	}
+	var paths []string
+	for _, f := range set.Files {
+		paths = append(paths, f.Path)
+	}
+	expect := []string{"/a/video.mp4", "/b/video.mp4", "/c/video.mp4"}
+	if len(paths) != len(expect) {
+		t.Fatalf("expect %v, got %v", expect, paths)
+	}
+	for i := range expect {
+		if paths[i] != expect[i] {
+			t.Errorf("expect %v, got %v", expect, paths)
+		}
+	}
+}
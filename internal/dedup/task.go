@@ -0,0 +1,149 @@
+package dedup
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// partialSize is the size of the head and the tail read for the partial hash
+const partialSize = 64 * 1024
+
+type ScanTask struct {
+	task.TaskExtension
+	Paths  []string `json:"paths"`
+	Status string   `json:"-"`
+}
+
+func (t *ScanTask) GetName() string {
+	return fmt.Sprintf("scan duplicates in [%s]", strings.Join(t.Paths, ", "))
+}
+
+func (t *ScanTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *ScanTask) Run() error {
+	t.Status = "listing files"
+	files, err := t.walk()
+	if err != nil {
+		return err
+	}
+	hashed := 0
+	sets := group(t.Ctx(), files, func(ctx context.Context, f file) (string, error) {
+		hashed++
+		t.Status = fmt.Sprintf("hashing [%s] (%d)", f.path, hashed)
+		return partialHash(ctx, f)
+	})
+	if utils.IsCanceled(t.Ctx()) {
+		return t.Ctx().Err()
+	}
+	var dups []model.DuplicateFile
+	for _, set := range sets {
+		dups = append(dups, set.Files...)
+	}
+	if err = db.ReplaceDuplicateFiles(dups); err != nil {
+		return errors.WithMessage(err, "failed save duplicates")
+	}
+	t.SetProgress(100)
+	t.Status = fmt.Sprintf("found %d duplicate sets in %d files", len(sets), len(files))
+	return nil
+}
+
+var ScanTaskManager *tache.Manager[*ScanTask]
+
+// walk lists all files under the paths, the files under the overlapped paths are listed once
+func (t *ScanTask) walk() ([]file, error) {
+	var files []file
+	visited := make(map[string]struct{})
+	for _, root := range t.Paths {
+		obj, err := fs.Get(t.Ctx(), root, &fs.GetArgs{})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed get [%s]", root)
+		}
+		err = fs.WalkFS(t.Ctx(), -1, root, obj, func(reqPath string, info model.Obj) error {
+			if utils.IsCanceled(t.Ctx()) {
+				return t.Ctx().Err()
+			}
+			if _, ok := visited[reqPath]; ok {
+				return nil
+			}
+			visited[reqPath] = struct{}{}
+			// the files under an alias are also listed where they are stored,
+			// the balanced mirrors are served under the same path, so they are listed once
+			if info.IsDir() && isAlias(reqPath) {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() {
+				files = append(files, file{path: reqPath, obj: info})
+				t.Status = fmt.Sprintf("listing files (%d)", len(files))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed walk [%s]", root)
+		}
+	}
+	return files, nil
+}
+
+// partialHash hashes the size, the head and the tail of the file
+func partialHash(ctx context.Context, f file) (string, error) {
+	return hashRanges(ctx, f.path, func(size int64) []http_range.Range {
+		ranges := []http_range.Range{{Start: 0, Length: min(size, partialSize)}}
+		if size > partialSize {
+			tail := max(size-partialSize, partialSize)
+			ranges = append(ranges, http_range.Range{Start: tail, Length: size - tail})
+		}
+		return ranges
+	})
+}
+
+// fullHash hashes the size and the whole content of the file
+func fullHash(ctx context.Context, path string) (string, error) {
+	return hashRanges(ctx, path, func(size int64) []http_range.Range {
+		return []http_range.Range{{Start: 0, Length: size}}
+	})
+}
+
+func hashRanges(ctx context.Context, path string, ranges func(size int64) []http_range.Range) (string, error) {
+	link, obj, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return "", errors.WithMessage(err, "failed link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, link)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	size := obj.GetSize()
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "%d:", size)
+	for _, r := range ranges(size) {
+		if r.Length <= 0 {
+			continue
+		}
+		rc, err := ss.RangeRead(r)
+		if err != nil {
+			return "", errors.WithMessage(err, "failed read")
+		}
+		if _, err = io.Copy(h, io.LimitReader(rc, r.Length)); err != nil {
+			return "", errors.WithMessage(err, "failed read")
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
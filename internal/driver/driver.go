@@ -2,6 +2,7 @@ package driver
 
 import (
 	"context"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
 )
@@ -80,10 +81,62 @@ type Remove interface {
 	Remove(ctx context.Context, obj model.Obj) error
 }
 
+type SetModified interface {
+	// SetModified set the modification time of the obj, e.g. by the Win32LastModifiedTime property of WebDAV
+	SetModified(ctx context.Context, obj model.Obj, modified time.Time) error
+}
+
+type WithDetails interface {
+	// GetDetails get the space usage of the storage
+	GetDetails(ctx context.Context) (*model.StorageDetails, error)
+}
+
+type ChangeFeed interface {
+	// GetChanges get the changes since the cursor and the cursor for the next call,
+	// only the latest cursor is returned if the cursor is empty
+	GetChanges(ctx context.Context, cursor string) ([]model.Change, string, error)
+}
+
 type Put interface {
 	Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up UpdateProgress) error
 }
 
+type ArchiveReader interface {
+	// GetArchiveMeta get the meta-info of an archive
+	// return errs.WrongArchivePassword if the meta-info is also encrypted but provided password is wrong or empty
+	// return errs.NotImplement to use internal archive tools to get the meta-info, such as the following cases:
+	// 1. the driver do not support the format of the archive but there may be an internal tool do
+	// 2. handling archives is a VIP feature, but the driver does not have VIP access
+	GetArchiveMeta(ctx context.Context, obj model.Obj, args model.ArchiveArgs) (model.ArchiveMeta, error)
+	// ListArchive list the children of model.ArchiveArgs.InnerPath in the archive
+	// return errs.NotImplement to use internal archive tools to list the children
+	// return errs.NotSupport if the folder structure should be acquired from model.ArchiveMeta.GetTree
+	ListArchive(ctx context.Context, obj model.Obj, args model.ArchiveInnerArgs) ([]model.Obj, error)
+	// Extract get url/filepath/reader of a file in the archive
+	// return errs.NotImplement to use internal archive tools to extract
+	Extract(ctx context.Context, obj model.Obj, args model.ArchiveInnerArgs) (*model.Link, error)
+}
+
+type ArchiveGetter interface {
+	// ArchiveGet get file by inner path
+	// return errs.NotImplement to use internal archive tools to get the children
+	// return errs.NotSupport if the folder structure should be acquired from model.ArchiveMeta.GetTree
+	ArchiveGet(ctx context.Context, obj model.Obj, args model.ArchiveInnerArgs) (model.Obj, error)
+}
+
+type ArchiveDecompress interface {
+	// ArchiveDecompress extract args.InnerPath of the archive srcObj into dstDir on the server side
+	// a folder with the same name as the archive file needs to be created to store the extracted results if args.PutIntoNewDir
+	// return errs.NotImplement to use internal archive tools
+	ArchiveDecompress(ctx context.Context, srcObj, dstDir model.Obj, args model.ArchiveDecompressArgs) error
+}
+
+type ArchiveDecompressResult interface {
+	// ArchiveDecompress the same as ArchiveDecompress, but returns the extracted objects in dstDir
+	// nil can be returned if the driver can't tell, the cache of dstDir will be cleared then
+	ArchiveDecompress(ctx context.Context, srcObj, dstDir model.Obj, args model.ArchiveDecompressArgs) ([]model.Obj, error)
+}
+
 //type WriteResult interface {
 //	MkdirResult
 //	MoveResult
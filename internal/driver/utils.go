@@ -38,7 +38,7 @@ type RateLimitFile = stream.RateLimitFile
 func NewLimitedUploadStream(ctx context.Context, r io.Reader) *RateLimitReader {
 	return &RateLimitReader{
 		Reader:  r,
-		Limiter: stream.ServerUploadLimit,
+		Limiter: stream.UploadLimiter(ctx),
 		Ctx:     ctx,
 	}
 }
@@ -46,13 +46,13 @@ func NewLimitedUploadStream(ctx context.Context, r io.Reader) *RateLimitReader {
 func NewLimitedUploadFile(ctx context.Context, f model.File) *RateLimitFile {
 	return &RateLimitFile{
 		File:    f,
-		Limiter: stream.ServerUploadLimit,
+		Limiter: stream.UploadLimiter(ctx),
 		Ctx:     ctx,
 	}
 }
 
 func ServerUploadLimitWaitN(ctx context.Context, n int) error {
-	return stream.ServerUploadLimit.WaitN(ctx, n)
+	return stream.UploadLimiter(ctx).WaitN(ctx, n)
 }
 
 type ReaderWithCtx = stream.ReaderWithCtx
@@ -0,0 +1,17 @@
+package errs
+
+import (
+	"errors"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var (
+	UnknownArchiveFormat      = errors.New("unknown archive format")
+	WrongArchivePassword      = errors.New("wrong archive password")
+	DriverExtractNotSupported = errors.New("driver extraction not supported")
+)
+
+func IsWrongArchivePassword(err error) bool {
+	return errors.Is(pkgerr.Cause(err), WrongArchivePassword)
+}
@@ -14,6 +14,9 @@ var (
 
 	MoveBetweenTwoStorages = errors.New("can't move files between two storages, try to copy")
 	UploadNotSupported     = errors.New("upload not supported")
+	QuotaExceeded          = errors.New("quota exceeded")
+	UploadOffsetMismatch   = errors.New("upload offset mismatch")
+	UploadInProgress       = errors.New("upload is in progress")
 
 	MetaNotFound     = errors.New("meta not found")
 	StorageNotFound  = errors.New("storage not found")
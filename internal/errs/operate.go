@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	PermissionDenied = errors.New("permission denied")
+	Locked           = errors.New("the object is locked")
 )
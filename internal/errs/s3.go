@@ -0,0 +1,10 @@
+package errs
+
+import "errors"
+
+var (
+	S3InvalidPart      = errors.New("one or more of the specified parts could not be found")
+	S3InvalidPartOrder = errors.New("the list of parts was not in ascending order")
+	S3IncompleteBody   = errors.New("the body is shorter than the content length")
+	S3BadDigest        = errors.New("the Content-MD5 does not match the body")
+)
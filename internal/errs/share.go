@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	ShareExpired   = errors.New("share is expired")
+	ShareExhausted = errors.New("share reaches the download limit")
+)
@@ -0,0 +1,261 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type ArchiveDecompressTask struct {
+	task.TaskExtension
+	Status        string        `json:"-"`
+	SrcObjPath    string        `json:"src_path"`
+	DstDirPath    string        `json:"dst_path"`
+	SrcStorageMp  string        `json:"src_storage_mp"`
+	DstStorageMp  string        `json:"dst_storage_mp"`
+	Password      string        `json:"password"`
+	InnerPath     string        `json:"inner_path"`
+	PutIntoNewDir bool          `json:"put_into_new_dir"`
+	srcStorage    driver.Driver `json:"-"`
+	dstStorage    driver.Driver `json:"-"`
+}
+
+func (t *ArchiveDecompressTask) GetName() string {
+	return fmt.Sprintf("decompress [%s](%s)[%s] to [%s](%s)", t.SrcStorageMp, t.SrcObjPath, t.InnerPath, t.DstStorageMp, t.DstDirPath)
+}
+
+func (t *ArchiveDecompressTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *ArchiveDecompressTask) Run() error {
+	var err error
+	if t.srcStorage == nil {
+		t.srcStorage, err = op.GetStorageByMountPath(t.SrcStorageMp)
+	}
+	if t.dstStorage == nil && err == nil {
+		t.dstStorage, err = op.GetStorageByMountPath(t.DstStorageMp)
+	}
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+
+	t.Status = "getting src object"
+	srcObj, err := op.Get(t.Ctx(), t.srcStorage, t.SrcObjPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", t.SrcObjPath)
+	}
+	_, archiveTool, err := tool.GetArchiveTool(srcObj.GetName())
+	if err != nil {
+		return err
+	}
+	link, _, err := op.Link(t.Ctx(), t.srcStorage, t.SrcObjPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", t.SrcObjPath)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: t.Ctx(), Obj: srcObj}, link)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcObjPath)
+	}
+	defer func() {
+		if err := ss.Close(); err != nil {
+			log.Errorf("failed to close file streamer, %v", err)
+		}
+	}()
+
+	tmpDir, err := os.MkdirTemp(conf.Conf.TempDir, "decompress-*")
+	if err != nil {
+		return errors.WithMessage(err, "failed create temp dir")
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Errorf("failed to remove temp dir [%s], %v", tmpDir, err)
+		}
+	}()
+
+	// decompressing takes the first half of the progress, uploading takes the rest
+	t.Status = "decompressing"
+	err = archiveTool.Decompress(ss, tmpDir, model.ArchiveInnerArgs{
+		ArchiveArgs: model.ArchiveArgs{Password: t.Password},
+		InnerPath:   t.InnerPath,
+	}, func(p float64) {
+		t.SetProgress(p / 2)
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed decompress")
+	}
+
+	dstDirPath := t.DstDirPath
+	if t.PutIntoNewDir {
+		dstDirPath = stdpath.Join(dstDirPath, archiveBaseName(srcObj.GetName()))
+		if err = op.MakeDir(t.Ctx(), t.dstStorage, dstDirPath); err != nil {
+			return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+		}
+	}
+	t.Status = "uploading"
+	return t.upload(tmpDir, dstDirPath)
+}
+
+// upload puts the decompressed files in localDir to dstDirPath of the dst storage
+func (t *ArchiveDecompressTask) upload(localDir, dstDirPath string) error {
+	var total, uploaded int64
+	err := filepath.WalkDir(localDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed walk decompressed files")
+	}
+	return filepath.WalkDir(localDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		dstPath := stdpath.Join(dstDirPath, filepath.ToSlash(rel))
+		if d.IsDir() {
+			return op.MakeDir(t.Ctx(), t.dstStorage, dstPath)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		file := &stream.FileStream{
+			Ctx: t.Ctx(),
+			Obj: &model.Object{
+				Name:     info.Name(),
+				Size:     info.Size(),
+				Modified: info.ModTime(),
+			},
+			Reader:   f,
+			Mimetype: utils.GetMimeType(info.Name()),
+			Closers:  utils.NewClosers(f),
+		}
+		base := uploaded
+		err = op.Put(t.Ctx(), t.dstStorage, stdpath.Dir(dstPath), file, func(p float64) {
+			if total > 0 {
+				t.SetProgress(50 + (float64(base)+p/100*float64(info.Size()))/float64(total)*50)
+			}
+		}, true)
+		if err != nil {
+			return errors.WithMessagef(err, "failed upload [%s]", dstPath)
+		}
+		uploaded += info.Size()
+		return nil
+	})
+}
+
+// archiveBaseName trims the archive extension, e.g. "a.tar.gz" => "a"
+func archiveBaseName(name string) string {
+	if ext, _, err := tool.GetArchiveTool(name); err == nil {
+		return name[:len(name)-len(ext)]
+	}
+	return strings.TrimSuffix(name, stdpath.Ext(name))
+}
+
+var ArchiveDecompressTaskManager *tache.Manager[*ArchiveDecompressTask]
+
+func archiveMeta(ctx context.Context, path string, args model.ArchiveMetaArgs) (*model.ArchiveMetaProvider, error) {
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	return op.GetArchiveMeta(ctx, storage, actualPath, args)
+}
+
+func archiveList(ctx context.Context, path string, args model.ArchiveListArgs) ([]model.Obj, error) {
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	return op.ListArchive(ctx, storage, actualPath, args)
+}
+
+// archiveDecompress decompresses on the server side if the driver supports it and
+// the dst is in the same storage, otherwise adds a decompress task
+func archiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args model.ArchiveDecompressArgs, lazyCache ...bool) (task.TaskExtensionInfo, error) {
+	srcStorage, srcObjActualPath, err := getStorageAndActualPath(ctx, srcObjPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		err = op.ArchiveDecompress(ctx, srcStorage, srcObjActualPath, dstDirActualPath, args, lazyCache...)
+		if !errors.Is(err, errs.NotImplement) {
+			return nil, err
+		}
+	}
+	if dstStorage.Config().NoUpload {
+		return nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	t := &ArchiveDecompressTask{
+		srcStorage:    srcStorage,
+		dstStorage:    dstStorage,
+		SrcObjPath:    srcObjActualPath,
+		DstDirPath:    dstDirActualPath,
+		SrcStorageMp:  srcStorage.GetStorage().MountPath,
+		DstStorageMp:  dstStorage.GetStorage().MountPath,
+		Password:      args.Password,
+		InnerPath:     args.InnerPath,
+		PutIntoNewDir: args.PutIntoNewDir,
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t.SetCreator(taskCreator)
+	ArchiveDecompressTaskManager.Add(t)
+	return t, nil
+}
+
+func archiveDriverExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (*model.Link, model.Obj, error) {
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed get storage")
+	}
+	return op.DriverExtract(ctx, storage, actualPath, args)
+}
+
+func archiveInternalExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
+	if err != nil {
+		return nil, 0, errors.WithMessage(err, "failed get storage")
+	}
+	return op.InternalExtract(ctx, storage, actualPath, args)
+}
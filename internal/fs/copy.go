@@ -9,9 +9,10 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/notify"
 	"github.com/alist-org/alist/v3/internal/op"
-	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/tache"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
@@ -19,7 +20,7 @@ import (
 )
 
 type CopyTask struct {
-	tache.Base
+	task.TaskExtension
 	Status       string        `json:"-"` //don't save status to save space
 	SrcObjPath   string        `json:"src_path"`
 	DstDirPath   string        `json:"dst_path"`
@@ -48,19 +49,13 @@ func (t *CopyTask) GetSize() int64 {
 }
 
 func (t *CopyTask) OnFailed() {
-	result := fmt.Sprintf("%s:%s", t.GetName(), t.GetErr())
-	log.Debug(result)
-	if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnCopyFailed) {
-		go op.Notify("文件复制结果", result)
-	}
+	log.Debugf("%s:%s", t.GetName(), t.GetErr())
+	notify.Send(notify.EventCopyFailed, notify.Data{"Name": t.GetName(), "Error": t.GetErr()})
 }
 
 func (t *CopyTask) OnSucceeded() {
-	result := fmt.Sprintf("复制%s到%s成功", t.SrcObjPath, t.DstDirPath)
-	log.Debug(result)
-	if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnCopySucceeded) {
-		go op.Notify("文件复制结果", result)
-	}
+	log.Debugf("copy %s to %s succeeded", t.SrcObjPath, t.DstDirPath)
+	notify.Send(notify.EventCopySucceeded, notify.Data{"SrcPath": t.SrcObjPath, "DstPath": t.DstDirPath})
 }
 
 func humanReadableSize(size int64) string {
@@ -121,12 +116,12 @@ var CopyTaskManager *tache.Manager[*CopyTask]
 
 // Copy if in the same storage, call move method
 // if not, add copy task
-func _copy(ctx context.Context, SrcObjPath, DstDirPath string, overwrite bool, lazyCache ...bool) (tache.TaskWithInfo, error) {
-	srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(SrcObjPath)
+func _copy(ctx context.Context, SrcObjPath, DstDirPath string, overwrite bool, lazyCache ...bool) (task.TaskExtensionInfo, error) {
+	srcStorage, srcObjActualPath, err := getStorageAndActualPath(ctx, SrcObjPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get src storage")
 	}
-	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(DstDirPath)
+	dstStorage, dstDirActualPath, err := getStorageAndActualPath(ctx, DstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get dst storage")
 	}
@@ -150,7 +145,7 @@ func _copy(ctx context.Context, SrcObjPath, DstDirPath string, overwrite bool, l
 			}
 			fs := stream.FileStream{
 				Obj: srcObj,
-				Ctx: ctx,
+				Ctx: stream.WithDownloadLimiter(ctx, op.DownloadLimiter(ctx, srcStorage)),
 			}
 			// any link provided is seekable
 			ss, err := stream.NewSeekableStream(fs, link)
@@ -162,6 +157,7 @@ func _copy(ctx context.Context, SrcObjPath, DstDirPath string, overwrite bool, l
 	}
 	// not in the same storage
 
+	taskCreator, _ := ctx.Value("user").(*model.User)
 	t := &CopyTask{
 		srcStorage:   srcStorage,
 		dstStorage:   dstStorage,
@@ -171,6 +167,7 @@ func _copy(ctx context.Context, SrcObjPath, DstDirPath string, overwrite bool, l
 		SrcStorageMp: srcStorage.GetStorage().MountPath,
 		DstStorageMp: dstStorage.GetStorage().MountPath,
 	}
+	t.SetCreator(taskCreator)
 	CopyTaskManager.Add(t)
 	return t, nil
 }
@@ -193,7 +190,7 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, Src
 			}
 			SrcObjPath := stdpath.Join(SrcObjPath, obj.GetName())
 			dstObjPath := stdpath.Join(DstDirPath, srcObj.GetName())
-			CopyTaskManager.Add(&CopyTask{
+			child := &CopyTask{
 				srcStorage:   srcStorage,
 				dstStorage:   dstStorage,
 				SrcObjPath:   SrcObjPath,
@@ -201,7 +198,9 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, Src
 				Override:     t.Override,
 				SrcStorageMp: srcStorage.GetStorage().MountPath,
 				DstStorageMp: dstStorage.GetStorage().MountPath,
-			})
+			}
+			child.InheritCreator(&t.TaskExtension)
+			CopyTaskManager.Add(child)
 		}
 		t.Status = "src object is dir, added all copy tasks of objs"
 		return nil
@@ -223,7 +222,7 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	}
 	fs := stream.FileStream{
 		Obj: srcFile,
-		Ctx: tsk.Ctx(),
+		Ctx: stream.WithDownloadLimiter(tsk.Ctx(), op.DownloadLimiter(tsk.Ctx(), srcStorage)),
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
@@ -2,11 +2,17 @@ package fs
 
 import (
 	"context"
+	"io"
+	stdpath "path"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
-	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -59,6 +65,7 @@ func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditMakeDir, SrcPath: path}, err)
 	return err
 }
 
@@ -67,14 +74,16 @@ func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditMove, SrcPath: srcPath, DstPath: dstDirPath}, err)
 	return err
 }
 
-func Copy(ctx context.Context, srcObjPath, dstDirPath string, overwrite bool, lazyCache ...bool) (tache.TaskWithInfo, error) {
+func Copy(ctx context.Context, srcObjPath, dstDirPath string, overwrite bool, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath, overwrite, lazyCache...)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditCopy, SrcPath: srcObjPath, DstPath: dstDirPath}, err)
 	return res, err
 }
 
@@ -83,6 +92,7 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditRename, SrcPath: srcPath, DstPath: stdpath.Join(stdpath.Dir(srcPath), dstName)}, err)
 	return err
 }
 
@@ -91,6 +101,15 @@ func Remove(ctx context.Context, path string) error {
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditRemove, SrcPath: path}, err)
+	return err
+}
+
+func SetModified(ctx context.Context, path string, modified time.Time) error {
+	err := setModified(ctx, path, modified)
+	if err != nil && !errs.IsNotImplement(err) {
+		log.Errorf("failed set modified time of %s: %+v", path, err)
+	}
 	return err
 }
 
@@ -99,14 +118,16 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditUpload, DstPath: stdpath.Join(dstDirPath, file.GetName()), Size: file.GetSize()}, err)
 	return err
 }
 
-func PutAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
-	t, err := putAsTask(dstDirPath, file)
+func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
+	t, err := putAsTask(ctx, dstDirPath, file)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditUpload, DstPath: stdpath.Join(dstDirPath, file.GetName()), Size: file.GetSize()}, err)
 	return t, err
 }
 
@@ -121,6 +142,15 @@ func GetStorage(path string, args *GetStoragesArgs) (driver.Driver, error) {
 	return storageDriver, nil
 }
 
+// GetStorageDetails returns the space usage of the storage of the path
+func GetStorageDetails(ctx context.Context, path string, refresh ...bool) (*model.StorageDetails, error) {
+	storage, err := GetStorage(path, &GetStoragesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	return op.GetStorageDetails(ctx, storage, refresh...)
+}
+
 func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	res, err := other(ctx, args)
 	if err != nil {
@@ -128,3 +158,44 @@ func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	}
 	return res, err
 }
+
+func ArchiveMeta(ctx context.Context, path string, args model.ArchiveMetaArgs) (*model.ArchiveMetaProvider, error) {
+	meta, err := archiveMeta(ctx, path, args)
+	if err != nil {
+		log.Errorf("failed get archive meta %s: %+v", path, err)
+	}
+	return meta, err
+}
+
+func ArchiveList(ctx context.Context, path string, args model.ArchiveListArgs) ([]model.Obj, error) {
+	objs, err := archiveList(ctx, path, args)
+	if err != nil {
+		log.Errorf("failed list archive [%s]%s: %+v", path, args.InnerPath, err)
+	}
+	return objs, err
+}
+
+func ArchiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args model.ArchiveDecompressArgs, lazyCache ...bool) (task.TaskExtensionInfo, error) {
+	res, err := archiveDecompress(ctx, srcObjPath, dstDirPath, args, lazyCache...)
+	if err != nil {
+		log.Errorf("failed decompress [%s]%s to %s: %+v", srcObjPath, args.InnerPath, dstDirPath, err)
+	}
+	audit.Record(ctx, audit.Entry{Operation: model.AuditDecompress, SrcPath: stdpath.Join(srcObjPath, args.InnerPath), DstPath: dstDirPath}, err)
+	return res, err
+}
+
+func ArchiveDriverExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (*model.Link, model.Obj, error) {
+	l, obj, err := archiveDriverExtract(ctx, path, args)
+	if err != nil && !errors.Is(err, errs.DriverExtractNotSupported) {
+		log.Errorf("failed extract [%s]%s: %+v", path, args.InnerPath, err)
+	}
+	return l, obj, err
+}
+
+func ArchiveInternalExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	rc, size, err := archiveInternalExtract(ctx, path, args)
+	if err != nil {
+		log.Errorf("failed extract [%s]%s: %+v", path, args.InnerPath, err)
+	}
+	return rc, size, err
+}
@@ -12,7 +12,7 @@ import (
 )
 
 func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed get storage")
 	}
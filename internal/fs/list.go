@@ -2,9 +2,13 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/trash"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -15,7 +19,7 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	meta, _ := ctx.Value("meta").(*model.Meta)
 	user, _ := ctx.Value("user").(*model.User)
 	virtualFiles := op.GetStorageVirtualFilesByPath(path)
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil && len(virtualFiles) == 0 {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
@@ -39,10 +43,37 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	if whetherHide(user, meta, path) {
 		om.InitHideReg(meta.Hide)
 	}
+	if storage != nil && actualPath == "/" {
+		_objs = hideTrash(_objs)
+	}
 	objs := om.Merge(_objs, virtualFiles...)
+	if user != nil && len(user.GroupIDs) > 0 {
+		objs = filterAclVisible(user, path, objs)
+	}
 	return objs, nil
 }
 
+// hideTrash hides the trash in the root of a storage, it's accessed by the trash api
+func hideTrash(objs []model.Obj) []model.Obj {
+	for i, obj := range objs {
+		if obj.GetName() == trash.DirName {
+			return append(objs[:i:i], objs[i+1:]...)
+		}
+	}
+	return objs
+}
+
+// filterAclVisible removes the objs the acl rules of the user's groups hide
+func filterAclVisible(user *model.User, path string, objs []model.Obj) []model.Obj {
+	res := objs[:0:0]
+	for _, obj := range objs {
+		if op.AclVisible(user, stdpath.Join(path, obj.GetName())) {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
 	if user == nil || user.CanSeeHides() {
@@ -63,3 +94,24 @@ func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is guest, hide
 	return true
 }
+
+// getStorageAndActualPath refuses the paths in the trash unless the user is an admin,
+// the others access the trash by the trash api, which checks the items one by one
+func getStorageAndActualPath(ctx context.Context, path string) (driver.Driver, string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return storage, actualPath, err
+	}
+	if trash.InTrash(actualPath) {
+		if user, _ := ctx.Value("user").(*model.User); user == nil || !user.IsAdmin() {
+			return nil, "", errors.WithStack(errs.ObjectNotFound)
+		}
+	}
+	return storage, actualPath, nil
+}
+
+// InTrash reports whether the path is in the trash of its storage, it's skipped by the indexer
+func InTrash(path string) bool {
+	_, actualPath, err := op.GetStorageAndActualPath(path)
+	return err == nil && trash.InTrash(actualPath)
+}
@@ -2,15 +2,17 @@ package fs
 
 import (
 	"context"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/trash"
 	"github.com/pkg/errors"
 )
 
 func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
@@ -18,11 +20,11 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 }
 
 func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
-	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	srcStorage, srcActualPath, err := getStorageAndActualPath(ctx, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get src storage")
 	}
-	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	dstStorage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get dst storage")
 	}
@@ -33,7 +35,7 @@ func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
-	storage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	storage, srcActualPath, err := getStorageAndActualPath(ctx, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
@@ -41,15 +43,27 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 }
 
 func remove(ctx context.Context, path string) error {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	// the objs in the trash are removed permanently
+	if trash.Enabled(storage) && !trash.InTrash(actualPath) {
+		return trash.Remove(ctx, storage, actualPath)
+	}
 	return op.Remove(ctx, storage, actualPath)
 }
 
+func setModified(ctx context.Context, path string, modified time.Time) error {
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	return op.SetModified(ctx, storage, actualPath, modified)
+}
+
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
-	storage, actualPath, err := op.GetStorageAndActualPath(args.Path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, args.Path)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
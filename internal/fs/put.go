@@ -3,17 +3,20 @@ package fs
 import (
 	"context"
 	"fmt"
+	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/notify"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/tache"
 	"github.com/pkg/errors"
 )
 
 type UploadTask struct {
-	tache.Base
+	task.TaskExtension
 	Name             string `json:"name"`
 	Status           string `json:"status"`
 	storage          driver.Driver
@@ -21,17 +24,12 @@ type UploadTask struct {
 	file             model.FileStreamer
 }
 
-// func (t *UploadTask) OnFailed() {
-// 	result := fmt.Sprintf("%s上传失败:%s", t.file.GetName(), t.GetErr())
-// 	log.Debug(result)
-// 	go op.Notify("文件上传结果", result)
-// }
-
-// func (t *UploadTask) OnSucceeded() {
-// 	result := fmt.Sprintf("%s上传成功", t.file.GetName())
-// 	log.Debug(result)
-// 	go op.Notify("文件上传结果", "文件复制成功")
-// }
+func (t *UploadTask) OnSucceeded() {
+	notify.Send(notify.EventUploadSucceeded, notify.Data{
+		"Name":    t.file.GetName(),
+		"DstPath": stdpath.Join(t.storage.GetStorage().MountPath, t.dstDirActualPath),
+	})
+}
 
 func (t *UploadTask) GetName() string {
 	return t.Name
@@ -50,14 +48,18 @@ func (t *UploadTask) Run() error {
 var UploadTaskManager *tache.Manager[*UploadTask]
 
 // putAsTask add as a put task and return immediately
-func putAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
-	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
+	storage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
+	// fail fast before caching the file, it's checked again when the task runs
+	if err := op.CheckQuota(ctx, file.GetSize(), 1); err != nil {
+		return nil, err
+	}
 	if file.NeedStore() {
 		_, err := file.CacheFullInTempFile()
 		if err != nil {
@@ -73,18 +75,24 @@ func putAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo,
 		dstDirActualPath: dstDirActualPath,
 		file:             file,
 	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t.SetCreator(taskCreator)
 	UploadTaskManager.Add(t)
 	return t, nil
 }
 
 // putDirect put the file and return after finish
 func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
-	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	storage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	err = op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	if err == nil {
+		notify.Send(notify.EventUploadSucceeded, notify.Data{"Name": file.GetName(), "DstPath": dstDirPath})
+	}
+	return err
 }
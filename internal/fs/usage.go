@@ -0,0 +1,54 @@
+package fs
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var usageRecalculating atomic.Bool
+
+// RecalculateUsage resets the usage of the users to the size and count of the files under their base paths in background,
+// all files under the base path are accounted to the user, so it's only accurate for the users with their own base paths
+func RecalculateUsage(users []model.User) error {
+	if !usageRecalculating.CompareAndSwap(false, true) {
+		return errors.New("the usage is being recalculated")
+	}
+	go func() {
+		defer usageRecalculating.Store(false)
+		for i := range users {
+			if err := recalculateUsage(context.Background(), &users[i]); err != nil {
+				log.Errorf("failed recalculate usage of user [%s]: %+v", users[i].Username, err)
+			}
+		}
+	}()
+	return nil
+}
+
+func recalculateUsage(ctx context.Context, user *model.User) error {
+	root := utils.FixAndCleanPath(user.BasePath)
+	ctx = context.WithValue(ctx, "user", user)
+	obj, err := get(ctx, root)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get base path [%s]", root)
+	}
+	var files []model.FileOwner
+	var size int64
+	err = WalkFS(ctx, -1, root, obj, func(reqPath string, info model.Obj) error {
+		if !info.IsDir() {
+			files = append(files, model.FileOwner{Path: reqPath, Size: info.GetSize()})
+			size += info.GetSize()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("usage of user [%s]: %d bytes, %d files", user.Username, size, len(files))
+	return op.ResetUserFiles(user.ID, files)
+}
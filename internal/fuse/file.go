@@ -0,0 +1,329 @@
+//go:build fuse
+
+package fuse
+
+import (
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// handle is an opened file.
+// Reads are served by ranged requests against the link of the file,
+// writes go to a temp file which is uploaded on flush/release.
+type handle struct {
+	mu     sync.Mutex
+	path   string
+	obj    model.Obj
+	reader stream.SStreamReadAtSeeker
+	tmp    *os.File
+	dirty  bool
+}
+
+func (f *Fs) addHandle(h *handle) uint64 {
+	f.handlesMu.Lock()
+	defer f.handlesMu.Unlock()
+	f.nextFh++
+	f.handles[f.nextFh] = h
+	return f.nextFh
+}
+
+func (f *Fs) getHandle(fh uint64) *handle {
+	f.handlesMu.Lock()
+	defer f.handlesMu.Unlock()
+	return f.handles[fh]
+}
+
+// getDirtyHandle returns an opened handle of path which has not been uploaded yet,
+// so that a file just created is visible before it's closed
+func (f *Fs) getDirtyHandle(path string) *handle {
+	f.handlesMu.Lock()
+	defer f.handlesMu.Unlock()
+	for _, h := range f.handles {
+		if h.path == path && h.tmp != nil {
+			return h
+		}
+	}
+	return nil
+}
+
+func (f *Fs) delHandle(fh uint64) *handle {
+	f.handlesMu.Lock()
+	defer f.handlesMu.Unlock()
+	h := f.handles[fh]
+	delete(f.handles, fh)
+	return h
+}
+
+func (h *handle) getattr(f *Fs, stat *fuse.Stat_t) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	info, err := h.tmp.Stat()
+	if err != nil {
+		return -fuse.EIO
+	}
+	f.fillStat(&model.Object{
+		Name:     stdpath.Base(h.path),
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+		Ctime:    h.obj.CreateTime(),
+	}, stat)
+	return 0
+}
+
+// openReader prepares ranged reading of the remote file, must be called with h.mu held
+func (h *handle) openReader(f *Fs) error {
+	if h.reader != nil {
+		return nil
+	}
+	link, obj, err := fs.Link(f.ctx, h.path, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: f.ctx, Obj: obj}, link)
+	if err != nil {
+		return err
+	}
+	reader, err := stream.NewReadAtSeeker(ss, 0, true)
+	if err != nil {
+		_ = ss.Close()
+		return err
+	}
+	h.reader = reader
+	return nil
+}
+
+func (h *handle) closeReader() {
+	if h.reader != nil {
+		_ = h.reader.Close()
+		h.reader = nil
+	}
+}
+
+// openTmp switches the handle to write mode, must be called with h.mu held.
+// Unless truncate is set, the current content is downloaded first.
+func (h *handle) openTmp(f *Fs, truncate bool) error {
+	if h.tmp != nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp(conf.Conf.TempDir, "fuse-*")
+	if err != nil {
+		return err
+	}
+	if !truncate && h.obj.GetSize() > 0 {
+		err = h.openReader(f)
+		if err == nil {
+			_, err = utils.CopyWithBuffer(tmp, io.NewSectionReader(h.reader, 0, h.obj.GetSize()))
+		}
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+			return errors.WithMessage(err, "failed download existing content")
+		}
+	}
+	h.closeReader()
+	h.tmp = tmp
+	h.dirty = truncate
+	return nil
+}
+
+// upload puts the temp file to the storage if it has been modified, must be called with h.mu held
+func (h *handle) upload(f *Fs) error {
+	if h.tmp == nil || !h.dirty {
+		return nil
+	}
+	info, err := h.tmp.Stat()
+	if err != nil {
+		return err
+	}
+	name := stdpath.Base(h.path)
+	obj := &model.Object{
+		Name:     name,
+		Size:     info.Size(),
+		Modified: time.Now(),
+		Ctime:    h.obj.CreateTime(),
+	}
+	file := &stream.FileStream{
+		Ctx:      f.ctx,
+		Obj:      obj,
+		Reader:   model.NewNopMFile(io.NewSectionReader(h.tmp, 0, info.Size())),
+		Mimetype: utils.GetMimeType(name),
+	}
+	err = fs.PutDirectly(f.ctx, stdpath.Dir(h.path), file)
+	_ = file.Close()
+	f.invalidate(h.path)
+	if err != nil {
+		return err
+	}
+	h.obj = obj
+	h.dirty = false
+	return nil
+}
+
+func (h *handle) release(f *Fs) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.upload(f)
+	h.closeReader()
+	if h.tmp != nil {
+		_ = h.tmp.Close()
+		_ = os.Remove(h.tmp.Name())
+		h.tmp = nil
+	}
+	return err
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	reqPath, err := f.realPath(path)
+	if err != nil || !f.canWrite(reqPath) {
+		return -fuse.EACCES, ^uint64(0)
+	}
+	h := &handle{
+		path: reqPath,
+		obj:  &model.Object{Name: stdpath.Base(reqPath), Modified: time.Now(), Ctime: time.Now()},
+	}
+	if err = h.openTmp(f, true); err != nil {
+		log.Errorf("[fuse] failed create %s: %+v", reqPath, err)
+		return -fuse.EIO, ^uint64(0)
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	reqPath, err := f.realPath(path)
+	if err != nil || !f.canAccess(reqPath) {
+		return -fuse.EACCES, ^uint64(0)
+	}
+	obj, err := f.get(reqPath)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	h := &handle{path: reqPath, obj: obj}
+	if flags&fuse.O_ACCMODE != fuse.O_RDONLY {
+		if !f.canWrite(reqPath) {
+			return -fuse.EACCES, ^uint64(0)
+		}
+		if flags&fuse.O_TRUNC != 0 {
+			if err = h.openTmp(f, true); err != nil {
+				return errno(err), ^uint64(0)
+			}
+		}
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var r io.ReaderAt = h.tmp
+	if h.tmp == nil {
+		if ofst >= h.obj.GetSize() {
+			return 0
+		}
+		if err := h.openReader(f); err != nil {
+			return errno(err)
+		}
+		r = h.reader
+	}
+	n, err := r.ReadAt(buff, ofst)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Errorf("[fuse] failed read %s at %d: %+v", h.path, ofst, err)
+		if n == 0 {
+			return -fuse.EIO
+		}
+	}
+	return n
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.openTmp(f, false); err != nil {
+		return errno(err)
+	}
+	n, err := h.tmp.WriteAt(buff, ofst)
+	if err != nil {
+		log.Errorf("[fuse] failed write %s at %d: %+v", h.path, ofst, err)
+		return -fuse.EIO
+	}
+	h.dirty = true
+	return n
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		// truncate(2) on a path which is not opened, use a temporary handle
+		reqPath, err := f.realPath(path)
+		if err != nil || !f.canWrite(reqPath) {
+			return -fuse.EACCES
+		}
+		obj, err := f.get(reqPath)
+		if err != nil {
+			return errno(err)
+		}
+		if obj.IsDir() {
+			return -fuse.EISDIR
+		}
+		h = &handle{path: reqPath, obj: obj}
+		defer func() {
+			if err := h.release(f); err != nil {
+				log.Errorf("[fuse] failed truncate %s: %+v", reqPath, err)
+			}
+		}()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.openTmp(f, size == 0); err != nil {
+		return errno(err)
+	}
+	if err := h.tmp.Truncate(size); err != nil {
+		return -fuse.EIO
+	}
+	h.dirty = true
+	return 0
+}
+
+func (f *Fs) Flush(path string, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return errno(h.upload(f))
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	return f.Flush(path, fh)
+}
+
+func (f *Fs) Release(path string, fh uint64) int {
+	h := f.delHandle(fh)
+	if h == nil {
+		return 0
+	}
+	return errno(h.release(f))
+}
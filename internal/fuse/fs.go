@@ -1,170 +1,475 @@
-package fuse
+//go:build fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+package fuse
 
+import (
+	"context"
+	"math"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/go-cache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs exposes the virtual file tree of alist as a FUSE file system.
+// All paths received from the kernel are relative to RootFolder,
+// which itself is relative to the base path of User.
 type Fs struct {
-	RootFolder string
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+	RootFolder  string
+	User        *model.User
+	ReadOnly    bool
+	AttrTimeout time.Duration
+
+	uid, gid    uint32
+	ctx         context.Context
+	cancel      context.CancelFunc
+	initialized chan struct{}
+
+	attrCache cache.ICache[model.Obj]
+	dirCache  cache.ICache[[]model.Obj]
+
+	handles   map[uint64]*handle
+	handlesMu sync.Mutex
+	nextFh    uint64
+}
+
+func NewFs(rootFolder string, user *model.User, readOnly bool, attrTimeout time.Duration) *Fs {
+	return &Fs{
+		RootFolder:  utils.FixAndCleanPath(rootFolder),
+		User:        user,
+		ReadOnly:    readOnly,
+		AttrTimeout: attrTimeout,
+		attrCache:   cache.NewMemCache(cache.WithShards[model.Obj](16)),
+		dirCache:    cache.NewMemCache(cache.WithShards[[]model.Obj](16)),
+		handles:     make(map[uint64]*handle),
+		initialized: make(chan struct{}),
+	}
+}
+
+func (f *Fs) Init() {
+	f.ctx, f.cancel = context.WithCancel(context.WithValue(context.Background(), "user", f.User))
+	f.uid, f.gid, _ = fuse.Getcontext()
+	close(f.initialized)
+	log.Infof("[fuse] mounted %s for user %s", f.RootFolder, f.User.Username)
+}
+
+func (f *Fs) Destroy() {
+	f.handlesMu.Lock()
+	for fh, h := range f.handles {
+		if err := h.release(f); err != nil {
+			log.Errorf("[fuse] failed release %s: %+v", h.path, err)
+		}
+		delete(f.handles, fh)
+	}
+	f.handlesMu.Unlock()
+	if f.cancel != nil {
+		f.cancel()
+	}
+	log.Infof("[fuse] unmounted %s", f.RootFolder)
+}
+
+// realPath converts a path received from the kernel to a path of the virtual file system
+func (f *Fs) realPath(path string) (string, error) {
+	return f.User.JoinPath(stdpath.Join(f.RootFolder, path))
+}
+
+// ctxWithMeta attaches the nearest meta of path, as the handlers of server do
+func (f *Fs) ctxWithMeta(path string) (context.Context, *model.Meta) {
+	meta, err := op.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		log.Warnf("[fuse] failed get meta of %s: %+v", path, err)
+	}
+	return context.WithValue(f.ctx, "meta", meta), meta
+}
+
+func (f *Fs) canAccess(path string) bool {
+	_, meta := f.ctxWithMeta(path)
+	return common.CanAccess(f.User, meta, path, "")
+}
+
+func (f *Fs) canWrite(path string) bool {
+	if f.ReadOnly {
+		return false
+	}
+	if f.User.CanWrite() {
+		return true
+	}
+	_, meta := f.ctxWithMeta(path)
+	return common.CanWrite(meta, stdpath.Dir(path))
+}
+
+func (f *Fs) get(path string) (model.Obj, error) {
+	if obj, ok := f.attrCache.Get(path); ok {
+		return obj, nil
+	}
+	if dirObjs, ok := f.dirCache.Get(stdpath.Dir(path)); ok {
+		name := stdpath.Base(path)
+		for _, obj := range dirObjs {
+			if obj.GetName() == name {
+				return obj, nil
+			}
+		}
+	}
+	obj, err := fs.Get(f.ctx, path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	f.attrCache.Set(path, obj, cache.WithEx[model.Obj](f.AttrTimeout))
+	return obj, nil
+}
+
+func (f *Fs) list(path string) ([]model.Obj, error) {
+	if objs, ok := f.dirCache.Get(path); ok {
+		return objs, nil
+	}
+	ctx, _ := f.ctxWithMeta(path)
+	objs, err := fs.List(ctx, path, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	f.dirCache.Set(path, objs, cache.WithEx[[]model.Obj](f.AttrTimeout))
+	return objs, nil
+}
+
+// invalidate drops the cached attributes of path and the listing of its parent
+func (f *Fs) invalidate(path string) {
+	f.attrCache.Del(path)
+	f.dirCache.Del(path, stdpath.Dir(path))
+}
+
+func (f *Fs) fillStat(obj model.Obj, stat *fuse.Stat_t) {
+	*stat = fuse.Stat_t{}
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | 0755
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | 0644
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+		stat.Blocks = (stat.Size + 511) / 512
+	}
+	if f.ReadOnly {
+		stat.Mode &^= 0222
+	}
+	stat.Uid, stat.Gid = f.uid, f.gid
+	stat.Blksize = 4096
+	mtime := fuse.NewTimespec(obj.ModTime())
+	stat.Mtim, stat.Atim = mtime, mtime
+	stat.Ctim = mtime
+	stat.Birthtim = fuse.NewTimespec(obj.CreateTime())
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	const bsize = 4096
+	*stat = fuse.Statfs_t{
+		Bsize:   bsize,
+		Frsize:  bsize,
+		Blocks:  math.MaxInt64 / bsize,
+		Bfree:   math.MaxInt64 / bsize,
+		Bavail:  math.MaxInt64 / bsize,
+		Files:   math.MaxInt32,
+		Ffree:   math.MaxInt32,
+		Favail:  math.MaxInt32,
+		Namemax: 255,
+	}
+	return 0
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	reqPath, err := f.realPath(path)
+	if err != nil {
+		return -fuse.EACCES
+	}
+	if h := f.getDirtyHandle(reqPath); h != nil {
+		return h.getattr(f, stat)
+	}
+	if !f.canAccess(reqPath) {
+		return -fuse.EACCES
+	}
+	obj, err := f.get(reqPath)
+	if err != nil {
+		return errno(err)
+	}
+	f.fillStat(obj, stat)
+	return 0
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	reqPath, err := f.realPath(path)
+	if err != nil {
+		return -fuse.EACCES
+	}
+	if !f.canWrite(reqPath) {
+		return -fuse.EACCES
+	}
+	if err = fs.MakeDir(f.ctx, reqPath); err != nil {
+		return errno(err)
+	}
+	f.invalidate(reqPath)
+	return 0
+}
+
+func (f *Fs) remove(path string, dir bool) int {
+	reqPath, err := f.realPath(path)
+	if err != nil {
+		return -fuse.EACCES
+	}
+	if f.ReadOnly || !f.User.CanRemove() {
+		return -fuse.EACCES
+	}
+	obj, err := f.get(reqPath)
+	if err != nil {
+		return errno(err)
+	}
+	if dir && !obj.IsDir() {
+		return -fuse.ENOTDIR
+	}
+	if !dir && obj.IsDir() {
+		return -fuse.EISDIR
+	}
+	if dir {
+		children, err := f.list(reqPath)
+		if err != nil {
+			return errno(err)
+		}
+		if len(children) > 0 {
+			return -fuse.ENOTEMPTY
+		}
+	}
+	if err = fs.Remove(f.ctx, reqPath); err != nil {
+		return errno(err)
+	}
+	f.invalidate(reqPath)
+	f.dirCache.Del(reqPath)
+	return 0
+}
+
+func (f *Fs) Unlink(path string) int {
+	return f.remove(path, false)
+}
+
+func (f *Fs) Rmdir(path string) int {
+	return f.remove(path, true)
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	src, err := f.realPath(oldpath)
+	if err != nil {
+		return -fuse.EACCES
+	}
+	dst, err := f.realPath(newpath)
+	if err != nil {
+		return -fuse.EACCES
+	}
+	if f.ReadOnly {
+		return -fuse.EACCES
+	}
+	srcDir, dstDir := stdpath.Dir(src), stdpath.Dir(dst)
+	srcName, dstName := stdpath.Base(src), stdpath.Base(dst)
+	if srcDir != dstDir && !f.User.CanMove() || srcName != dstName && !f.User.CanRename() {
+		return -fuse.EACCES
+	}
+	// mv falls back to copy and delete on EXDEV, it must be known before anything is touched
+	if srcDir != dstDir {
+		srcStorage, err := fs.GetStorage(src, &fs.GetStoragesArgs{})
+		if err != nil {
+			return errno(err)
+		}
+		dstStorage, err := fs.GetStorage(dstDir, &fs.GetStoragesArgs{})
+		if err != nil {
+			return errno(err)
+		}
+		if srcStorage.GetStorage() != dstStorage.GetStorage() {
+			return -fuse.EXDEV
+		}
+	}
+	// the kernel expects rename to replace an existing destination
+	replace := false
+	if dstObj, err := f.get(dst); err == nil {
+		if dstObj.IsDir() {
+			return -fuse.EEXIST
+		}
+		if !f.User.CanRemove() {
+			return -fuse.EACCES
+		}
+		replace = true
+	}
+	// a move keeps the name of src, which may be taken by an unrelated file in dstDir
+	collide := false
+	if srcDir != dstDir && srcName != dstName {
+		_, err := f.get(stdpath.Join(dstDir, srcName))
+		collide = err == nil
+	}
+	defer func() {
+		f.invalidate(src)
+		f.invalidate(dst)
+		f.dirCache.Del(src)
+	}()
+	if !replace && !collide {
+		return errno(f.move(src, dstDir, dstName))
+	}
+	// src is moved under a temporary name first, the destination is removed
+	// only once it's in place, so that a failed move loses nothing
+	tmpName := "." + srcName + ".alist-rename-" + random.String(8)
+	if err = f.move(src, srcDir, tmpName); err != nil {
+		return errno(err)
+	}
+	tmp := stdpath.Join(srcDir, tmpName)
+	if srcDir != dstDir {
+		err = fs.Move(f.ctx, tmp, dstDir)
+		f.invalidate(tmp)
+		if err != nil {
+			f.restore(tmp, src)
+			return errno(err)
+		}
+		tmp = stdpath.Join(dstDir, tmpName)
+	}
+	if replace {
+		if err = fs.Remove(f.ctx, dst); err != nil {
+			f.restore(tmp, src)
+			return errno(err)
+		}
+	}
+	err = fs.Rename(f.ctx, tmp, dstName)
+	f.invalidate(tmp)
+	if err != nil {
+		// the destination is gone already, leave src where it can be found
+		f.restore(tmp, src)
+		return errno(err)
+	}
+	return 0
+}
+
+// move moves src into dstDir under dstName, both of which are in the same storage
+func (f *Fs) move(src, dstDir, dstName string) error {
+	srcDir, srcName := stdpath.Split(src)
+	srcDir = stdpath.Clean(srcDir)
+	if srcDir == dstDir {
+		if srcName == dstName {
+			return nil
+		}
+		return fs.Rename(f.ctx, src, dstName)
+	}
+	err := fs.Move(f.ctx, src, dstDir)
+	if err == nil && srcName != dstName {
+		err = fs.Rename(f.ctx, stdpath.Join(dstDir, srcName), dstName)
+	}
+	return err
+}
+
+// restore puts the temporary file of a failed rename back to src
+func (f *Fs) restore(tmp, src string) {
+	err := f.move(tmp, stdpath.Dir(src), stdpath.Base(src))
+	f.invalidate(tmp)
+	if err != nil {
+		log.Errorf("[fuse] failed restore %s to %s: %+v", tmp, src, err)
+	}
+}
+
+// Chmod, Chown and Utimens are accepted but ignored, since drivers have no such concept.
+// Editors and cp -p would fail otherwise.
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	return 0
+}
+
+func (f *Fs) Access(path string, mask uint32) int {
+	reqPath, err := f.realPath(path)
+	if err != nil || !f.canAccess(reqPath) {
+		return -fuse.EACCES
+	}
+	// W_OK
+	if mask&2 != 0 && !f.canWrite(reqPath) {
+		return -fuse.EACCES
+	}
+	return 0
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	reqPath, err := f.realPath(path)
+	if err != nil || !f.canAccess(reqPath) {
+		return -fuse.EACCES, ^uint64(0)
+	}
+	obj, err := f.get(reqPath)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, 0
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	reqPath, err := f.realPath(path)
+	if err != nil {
+		return -fuse.EACCES
+	}
+	objs, err := f.list(reqPath)
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	for _, obj := range objs {
+		stat := new(fuse.Stat_t)
+		f.fillStat(obj, stat)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
+	return 0
+}
+
+// errno converts errors of internal/fs into negated errno values expected by cgofuse
+func errno(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errs.IsNotFoundError(err):
+		return -fuse.ENOENT
+	case errors.Is(errors.Cause(err), errs.PermissionDenied):
+		return -fuse.EACCES
+	case errs.IsNotSupportError(err), errs.IsNotImplement(err),
+		errors.Is(errors.Cause(err), errs.UploadNotSupported):
+		return -fuse.ENOSYS
+	case errors.Is(errors.Cause(err), errs.MoveBetweenTwoStorages):
+		return -fuse.EXDEV
+	case errors.Is(errors.Cause(err), errs.NotFolder):
+		return -fuse.ENOTDIR
+	case errors.Is(errors.Cause(err), errs.NotFile):
+		return -fuse.EISDIR
+	}
+	log.Errorf("[fuse] %+v", err)
+	return -fuse.EIO
 }
 
 var _ fuse.FileSystemInterface = (*Fs)(nil)
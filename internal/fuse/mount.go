@@ -1,9 +1,47 @@
+//go:build fuse
+
+// Package fuse mounts the virtual file system of AList with cgofuse.
+// It needs the FUSE headers (libfuse, macFUSE or WinFsp) at build time,
+// so it is only built with `-tags fuse`.
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type MountArgs struct {
+	User        *model.User
+	ReadOnly    bool
+	AttrTimeout time.Duration
+	Options     []string // passed to FUSE as -o
+}
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
+// Mount mounts mountSrc of the virtual file system to mountDst,
+// the returned host should be unmounted by the caller.
+func Mount(mountSrc, mountDst string, args MountArgs) (*fuse.FileSystemHost, error) {
+	fs := NewFs(mountSrc, args.User, args.ReadOnly, args.AttrTimeout)
 	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+	host.SetCapReaddirPlus(true)
+	var opts []string
+	for _, opt := range args.Options {
+		opts = append(opts, "-o", opt)
+	}
+	if args.ReadOnly {
+		opts = append(opts, "-o", "ro")
+	}
+	mounted := make(chan bool, 1)
+	go func() {
+		mounted <- host.Mount(mountDst, opts)
+	}()
+	select {
+	case <-fs.initialized:
+		return host, nil
+	case <-mounted:
+		// host.Mount blocks until unmounted, it only returns before Init when failed
+		return nil, errors.Errorf("failed mount %s to %s", mountSrc, mountDst)
+	}
 }
@@ -0,0 +1,30 @@
+package model
+
+// Group is a named role that users belong to, the acl rules are attached to groups
+type Group struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"unique" binding:"required"`
+	Description string `json:"description"`
+}
+
+const (
+	AclRead   int32 = 1 << iota // get and download objs
+	AclList                     // list folders
+	AclWrite                    // mkdir, upload, rename, and move or copy into
+	AclDelete                   // remove, and move out of
+	AclShare                    // create share links
+	AclWebdav                   // access by webdav
+)
+
+// AclRule grants the permissions on the path and its sub paths to the members of the group,
+// the nearest rules of the groups of a user decide the permissions instead of the user and the metas
+type AclRule struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	GroupID    uint   `json:"group_id" gorm:"index" binding:"required"`
+	Path       string `json:"path" binding:"required"`
+	Permission int32  `json:"permission"`
+}
+
+func (r *AclRule) Can(perm int32) bool {
+	return r.Permission&perm == perm
+}
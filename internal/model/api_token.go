@@ -0,0 +1,48 @@
+package model
+
+import (
+	stdpath "path"
+	"time"
+)
+
+// ApiTokenPrefix tells the api tokens from the login tokens
+const ApiTokenPrefix = "alist_pat_"
+
+// ApiToken is a long-lived credential of a user, the requests with it act as the user restricted by the token
+type ApiToken struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Name   string `json:"name"`
+	// the public part of the token, it's also the access key id of the s3 server
+	KeyID      string `json:"key_id" gorm:"unique;size:64"`
+	SecretHash string `json:"-"`
+	// the secret access key of the s3 server, encrypted by the key in the config file
+	S3Secret string `json:"-"`
+	// a subset of the permissions of the user, see User.Permission
+	Permission int32 `json:"permission"`
+	// relative to the base path of the user
+	BasePath  string     `json:"base_path"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	LastUsed  *time.Time `json:"last_used"`
+	Created   time.Time  `json:"created"`
+}
+
+func (t *ApiToken) Expired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+// Restrict returns a copy of the user which only has the permissions and the base path of the token,
+// the tokens never act as the admin, the global token is for that
+func (t *ApiToken) Restrict(user *User) *User {
+	u := *user
+	if u.IsAdmin() {
+		// the admins have all the permissions
+		u.Role = GENERAL
+		u.Permission = t.Permission
+	} else {
+		u.Permission &= t.Permission
+	}
+	u.BasePath = stdpath.Join(user.BasePath, t.BasePath)
+	u.TokenID = t.ID
+	return &u
+}
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+const (
+	AuditProtocolWeb    = "web"
+	AuditProtocolWebdav = "webdav"
+	AuditProtocolS3     = "s3"
+)
+
+const (
+	AuditMakeDir    = "mkdir"
+	AuditMove       = "move"
+	AuditCopy       = "copy"
+	AuditRename     = "rename"
+	AuditRemove     = "remove"
+	AuditUpload     = "upload"
+	AuditDownload   = "download"
+	AuditDecompress = "decompress"
+
+	AuditStorageCreate  = "storage.create"
+	AuditStorageUpdate  = "storage.update"
+	AuditStorageDelete  = "storage.delete"
+	AuditStorageEnable  = "storage.enable"
+	AuditStorageDisable = "storage.disable"
+	AuditUserCreate     = "user.create"
+	AuditUserUpdate     = "user.update"
+	AuditUserDelete     = "user.delete"
+	AuditMetaCreate     = "meta.create"
+	AuditMetaUpdate     = "meta.update"
+	AuditMetaDelete     = "meta.delete"
+	AuditSettingSave    = "setting.save"
+	AuditSettingDelete  = "setting.delete"
+)
+
+// AuditLog records who did what, the src path is the subject of the admin actions,
+// such as the mount path of a storage or the name of a user
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Time      time.Time `json:"time" gorm:"index"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username" gorm:"index"`
+	IP        string    `json:"ip"`
+	Protocol  string    `json:"protocol"`
+	Operation string    `json:"operation" gorm:"index"`
+	SrcPath   string    `json:"src_path"`
+	DstPath   string    `json:"dst_path"`
+	Size      int64     `json:"size"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error"`
+}
+
+type AuditLogReq struct {
+	PageReq
+	Username  string `json:"username" form:"username"`
+	Operation string `json:"operation" form:"operation"`
+	Protocol  string `json:"protocol" form:"protocol"`
+	// matches the src path or the dst path which contains it
+	Path    string     `json:"path" form:"path"`
+	Success *bool      `json:"success" form:"success"`
+	Start   *time.Time `json:"start" form:"start"`
+	End     *time.Time `json:"end" form:"end"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	// DuplicateActionDelete deletes the files of the set except the kept one
+	DuplicateActionDelete = "delete"
+	// DuplicateActionLink replaces the files of the set except the kept one with the shortcuts to it
+	DuplicateActionLink = "link"
+)
+
+// DuplicateFile is a file of a duplicate set found by the last scan
+type DuplicateFile struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	SetKey string `json:"set_key" gorm:"index"`
+	// the files are matched by the size and the hash of the head and the tail only
+	Partial  bool      `json:"partial"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+type DuplicateSet struct {
+	Key     string          `json:"key"`
+	Size    int64           `json:"size"`
+	Partial bool            `json:"partial"`
+	Files   []DuplicateFile `json:"files"`
+}
+
+type DuplicateResolveReq struct {
+	Key    string `json:"key" binding:"required"`
+	Keep   string `json:"keep" binding:"required"`
+	Action string `json:"action" binding:"required"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// S3AccessKey is a credential of the built-in s3 server, the requests signed with it act as the user
+type S3AccessKey struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	UserID      uint   `json:"user_id" gorm:"index"`
+	AccessKeyID string `json:"access_key_id" gorm:"unique;size:64"`
+	Secret      string `json:"secret"`
+	// only GET and HEAD are allowed
+	ReadOnly bool      `json:"read_only"`
+	Remark   string    `json:"remark"`
+	Created  time.Time `json:"created"`
+}
+
+// S3ObjectMeta is the metadata of an obj put by the s3 server, it's moved with the obj
+type S3ObjectMeta struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// the full path of the obj
+	Path string            `json:"path" gorm:"unique"`
+	Meta map[string]string `json:"meta" gorm:"serializer:json"`
+}
+
+// S3Upload is an unfinished multipart upload of the s3 server, the parts are staged in the temp dir
+type S3Upload struct {
+	ID        string `json:"id" gorm:"primaryKey;size:64"`
+	UserID    uint   `json:"user_id" gorm:"index"`
+	Bucket    string `json:"bucket"`
+	ObjectKey string `json:"object_key"`
+	// the full path of the obj to be put
+	Path    string            `json:"path"`
+	Meta    map[string]string `json:"meta" gorm:"serializer:json"`
+	Created time.Time         `json:"created"`
+	Updated time.Time         `json:"updated"`
+}
+
+type S3UploadPart struct {
+	UploadID   string    `json:"upload_id" gorm:"primaryKey;size:64"`
+	PartNumber int       `json:"part_number" gorm:"primaryKey;autoIncrement:false"`
+	Size       int64     `json:"size"`
+	ETag       string    `json:"etag"`
+	Updated    time.Time `json:"updated"`
+}
@@ -2,6 +2,9 @@ package model
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -10,21 +13,54 @@ type IndexProgress struct {
 	IsDone       bool       `json:"is_done"`
 	LastDoneTime *time.Time `json:"last_done_time"`
 	Error        string     `json:"error"`
+	// Version of the index schema, the index is rebuilt if it's outdated
+	Version int `json:"version"`
 }
 
+const (
+	// SearchModeKeywords matches the names containing all the keywords
+	SearchModeKeywords = ""
+	// SearchModePhrase matches the names containing the exact keywords
+	SearchModePhrase = "phrase"
+	// SearchModeRegex matches the names by the keywords as a regular expression
+	SearchModeRegex = "regex"
+)
+
 type SearchReq struct {
 	Parent   string `json:"parent"`
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
-	Scope int `json:"scope"`
+	Scope int    `json:"scope"`
+	Mode  string `json:"mode"`
+	// 0 for no limit
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
+	// zero for no limit
+	ModifiedAfter  time.Time `json:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before"`
+	// extensions without the dot, case-insensitive
+	Exts []string `json:"exts"`
+	// the obj types, see conf.FOLDER etc.
+	Types []int `json:"types"`
+	// name, size or modified, sorted by name if empty
+	OrderBy        string `json:"order_by"`
+	OrderDirection string `json:"order_direction"`
 	PageReq
 }
 
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	ObjType  int       `json:"obj_type" gorm:"index"`
+	// HashInfo is utils.HashInfo in string
+	HashInfo string `json:"hash_info"`
+	// Content is the text of the document, only indexed by the searchers supporting it
+	Content string `json:"-" gorm:"-"`
+	// Snippets are the highlighted fragments of the content matching the keywords
+	Snippets []string `json:"snippets,omitempty" gorm:"-"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,9 +70,39 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	switch p.Mode {
+	case SearchModeKeywords, SearchModePhrase:
+	case SearchModeRegex:
+		if _, err := regexp.Compile(p.Keywords); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown search mode: %s", p.Mode)
+	}
+	switch p.OrderBy {
+	case "", "name", "size", "modified":
+	default:
+		return fmt.Errorf("can't order by %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("unknown order direction: %s", p.OrderDirection)
+	}
+	for i := range p.Exts {
+		p.Exts[i] = strings.ToLower(strings.TrimPrefix(p.Exts[i], "."))
+	}
 	return nil
 }
 
+// Ext returns the extension of the node without the dot in lower case, it's empty for dirs
+func (s *SearchNode) Ext() string {
+	if s.IsDir {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(path.Ext(s.Name), "."))
+}
+
 func (s *SearchNode) Type() string {
 	return "SearchNode"
 }
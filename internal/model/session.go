@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Session is a login of a user, the access tokens carry the id of the session,
+// so that a login can be revoked without changing the password
+type Session struct {
+	ID        string `json:"id" gorm:"primaryKey;size:64"`
+	UserID    uint   `json:"user_id" gorm:"index"`
+	UserAgent string `json:"user_agent"`
+	IP        string `json:"ip"`
+	// the hash of the secret of the refresh token, it's rotated by every refresh
+	RefreshHash string    `json:"-"`
+	Created     time.Time `json:"created"`
+	LastSeen    time.Time `json:"last_seen"`
+	// the session can't be refreshed after it
+	Expires time.Time `json:"expires"`
+	// whether it's the session of the request
+	Current bool `json:"current" gorm:"-"`
+}
+
+func (s *Session) Expired() bool {
+	return time.Now().After(s.Expires)
+}
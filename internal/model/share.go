@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+)
+
+// Share is a public link to a file or folder, it's accessed by /s/:id without login
+type Share struct {
+	ID        string `json:"id" gorm:"primaryKey;size:32"`
+	Path      string `json:"path" binding:"required"`
+	CreatorID uint   `json:"creator_id" gorm:"index"`
+	Creator   string `json:"creator"`
+	// nil means never expires
+	Expires *time.Time `json:"expires"`
+	// the password is hashed as the users' ones, empty means no password
+	PwdHash string `json:"-"`
+	Salt    string `json:"-"`
+	// 0 means unlimited
+	MaxDownloads int64 `json:"max_downloads"`
+	// view the files in the browser besides downloading them
+	AllowPreview bool `json:"allow_preview"`
+	// upload into the shared folder
+	AllowUpload bool `json:"allow_upload"`
+
+	Views      int64      `json:"views"`
+	Downloads  int64      `json:"downloads"`
+	LastAccess *time.Time `json:"last_access"`
+	Created    time.Time  `json:"created"`
+}
+
+func (s *Share) Expired() bool {
+	return s.Expires != nil && time.Now().After(*s.Expires)
+}
+
+func (s *Share) Exhausted() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
+
+func (s *Share) HasPassword() bool {
+	return s.PwdHash != ""
+}
+
+// SetPassword hashes the password with a new salt, an empty password removes it
+func (s *Share) SetPassword(pwd string) {
+	if pwd == "" {
+		s.PwdHash, s.Salt = "", ""
+		return
+	}
+	s.Salt = random.String(16)
+	s.PwdHash = TwoHashPwd(pwd, s.Salt)
+}
+
+func (s *Share) ValidatePassword(pwd string) bool {
+	return !s.HasPassword() || pwd != "" && TwoHashPwd(pwd, s.Salt) == s.PwdHash
+}
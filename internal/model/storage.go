@@ -16,6 +16,10 @@ type Storage struct {
 	Modified        time.Time `json:"modified"`
 	Disabled        bool      `json:"disabled"` // if disabled
 	EnableSign      bool      `json:"enable_sign"`
+	Trash           bool      `json:"trash"` // move the removed objs into the trash instead of deleting them
+	// KB/s of the data proxied by the server, 0 means unlimited
+	DownloadLimit int64 `json:"download_limit"`
+	UploadLimit   int64 `json:"upload_limit"`
 	Sort
 	Proxy
 }
@@ -56,3 +60,24 @@ func (p Proxy) WebdavProxy() bool {
 func (p Proxy) WebdavNative() bool {
 	return !p.Webdav302() && !p.WebdavProxy()
 }
+
+// StorageDetails is the space usage of a storage, reported by the drivers implementing driver.WithDetails
+type StorageDetails struct {
+	TotalSpace int64 `json:"total_space"`
+	UsedSpace  int64 `json:"used_space"`
+	FreeSpace  int64 `json:"free_space"`
+}
+
+// Full reports whether there is no free space, the storages without a limit are never full
+func (d *StorageDetails) Full() bool {
+	return d.TotalSpace > 0 && d.FreeSpace <= 0
+}
+
+// Change is a change reported by the change feed of a storage
+type Change struct {
+	// Path is the actual path in the storage, the root means the changes are unknown,
+	// e.g. the path of a deleted obj can't be resolved
+	Path    string
+	IsDir   bool
+	Deleted bool
+}
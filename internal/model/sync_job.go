@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	// SyncModeMirror makes the destination the same as the source, extra objs in the destination are deleted
+	SyncModeMirror = "mirror"
+	// SyncModeOneWay copies new and changed objs from the source to the destination
+	SyncModeOneWay = "one-way"
+	// SyncModeTwoWay copies new and changed objs in both directions
+	SyncModeTwoWay = "two-way"
+)
+
+type SyncJob struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Name     string `json:"name"`
+	SrcPath  string `json:"src_path" binding:"required"`
+	DstPath  string `json:"dst_path" binding:"required"`
+	Mode     string `json:"mode"`
+	Interval int    `json:"interval"` // minutes between two runs, 0 means only run manually
+	// propagate deletes, only used by one-way and two-way, mirror always deletes
+	Delete   bool       `json:"delete"`
+	Disabled bool       `json:"disabled"`
+	LastRun  *time.Time `json:"last_run"`
+	Status   string     `json:"status"`
+}
+
+// SyncRecord is the state of an obj after the last run of a job,
+// it's used to tell an obj is new or deleted on the other side
+type SyncRecord struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	JobID       uint      `json:"job_id" gorm:"index"`
+	Path        string    `json:"path"` // relative to the paths of the job
+	IsDir       bool      `json:"is_dir"`
+	SrcSize     int64     `json:"src_size"`
+	SrcModified time.Time `json:"src_modified"`
+	SrcHash     string    `json:"src_hash"`
+	DstSize     int64     `json:"dst_size"`
+	DstModified time.Time `json:"dst_modified"`
+	DstHash     string    `json:"dst_hash"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TrashItem is a removed obj kept in the trash, until it's restored or purged
+type TrashItem struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Name  string `json:"name"`
+	IsDir bool   `json:"is_dir"`
+	Size  int64  `json:"size"`
+	// the full path before removed, it's restored to there
+	Path string `json:"path"`
+	// the full path in the trash, it may be in another storage if the storage can't move
+	TrashPath string `json:"trash_path,omitempty"`
+	// who removed it, 0 if removed by the server itself
+	UserID  uint      `json:"user_id"`
+	Deleted time.Time `json:"deleted" gorm:"index"`
+}
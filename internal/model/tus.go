@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// TusUpload is an unfinished resumable upload, the received bytes are staged in the temp dir
+type TusUpload struct {
+	ID     string `json:"id" gorm:"primaryKey;size:64"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	// the full path of the file to be uploaded
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+	// the raw Upload-Metadata header, it's returned as is when resuming
+	Metadata string    `json:"metadata"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
@@ -43,10 +43,39 @@ type User struct {
 	//   8: webdav read
 	//   9: webdav write
 	//   10: can use storage other interface
+	//   11: can read archives
+	//   12: can decompress archives
 	Permission int32  `json:"permission"`
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	// the groups the user belongs to, see AclRule
+	GroupIDs []uint `json:"group_ids" gorm:"serializer:json"`
+	// 0 means unlimited
+	QuotaSize  int64 `json:"quota_size"`
+	QuotaFiles int64 `json:"quota_files"`
+	// KB/s of the data proxied by the server, 0 means unlimited
+	DownloadLimit int64 `json:"download_limit"`
+	UploadLimit   int64 `json:"upload_limit"`
+	// the api token the request is authenticated by, the user restricted by a token shouldn't be saved
+	TokenID uint `json:"-" gorm:"-"`
+}
+
+// UserUsage is the size and count of the files written by the user,
+// it's kept apart from User so that the frequent updates don't race with editing users
+type UserUsage struct {
+	UserID    uint  `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	UsedSize  int64 `json:"used_size"`
+	UsedFiles int64 `json:"used_files"`
+}
+
+// FileOwner is the user the file is accounted to, the usage is given back to it when the file is removed
+type FileOwner struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// the full path of the file
+	Path   string `json:"path" gorm:"unique"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Size   int64  `json:"size"`
 }
 
 func (u *User) IsGuest() bool {
@@ -57,6 +86,19 @@ func (u *User) IsAdmin() bool {
 	return u.Role == ADMIN
 }
 
+func (u *User) InGroup(groupID uint) bool {
+	for _, id := range u.GroupIDs {
+		if id == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *User) HasQuota() bool {
+	return u.QuotaSize > 0 || u.QuotaFiles > 0
+}
+
 func (u *User) ValidateRawPassword(password string) error {
 	return u.ValidatePwdStaticHash(StaticHash(password))
 }
@@ -118,6 +160,14 @@ func (u *User) CanWebdavManage() bool {
 	return u.IsAdmin() || (u.Permission>>9)&1 == 1
 }
 
+func (u *User) CanReadArchives() bool {
+	return u.IsAdmin() || (u.Permission>>11)&1 == 1
+}
+
+func (u *User) CanDecompress() bool {
+	return u.IsAdmin() || (u.Permission>>12)&1 == 1
+}
+
 func (u *User) JoinPath(reqPath string) (string, error) {
 	return utils.JoinBasePath(u.BasePath, reqPath)
 }
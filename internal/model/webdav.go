@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// WebdavLock is a lock created by a WebDAV LOCK request, it's stored in the database
+// so that the locks survive restarts and are shared by all the instances
+type WebdavLock struct {
+	Token string `json:"token" gorm:"primaryKey;size:64"`
+	// the full path of the locked resource
+	Root      string `json:"root" gorm:"unique"`
+	ZeroDepth bool   `json:"zero_depth"`
+	OwnerXML  string `json:"owner_xml"`
+	// negative means the lock never expires
+	Duration time.Duration `json:"duration"`
+	Expiry   time.Time     `json:"expiry"`
+	Created  time.Time     `json:"created"`
+}
+
+func (l *WebdavLock) Expired(now time.Time) bool {
+	return l.Duration >= 0 && !now.Before(l.Expiry)
+}
+
+// WebdavProps are the dead properties of a resource set by the WebDAV PROPPATCH requests
+type WebdavProps struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// the full path of the resource
+	Path  string       `json:"path" gorm:"unique"`
+	Props []WebdavProp `json:"props" gorm:"serializer:json"`
+}
+
+type WebdavProp struct {
+	Space    string `json:"space"`
+	Local    string `json:"local"`
+	Lang     string `json:"lang,omitempty"`
+	InnerXML string `json:"inner_xml"`
+}
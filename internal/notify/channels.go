@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// the JSON keys of the configs are the same as notify_value used before channels were supported
+
+type Bark struct {
+	BarkPush  string `json:"barkPush"`
+	BarkIcon  string `json:"barkIcon,omitempty"`
+	BarkSound string `json:"barkSound,omitempty"`
+	BarkGroup string `json:"barkGroup,omitempty"`
+	BarkLevel string `json:"barkLevel,omitempty"`
+	BarkUrl   string `json:"barkUrl,omitempty"`
+}
+
+func (b *Bark) Send(ctx context.Context, title, content string) error {
+	push := b.BarkPush
+	if !strings.HasPrefix(push, "http") {
+		push = fmt.Sprintf("https://api.day.app/%s", push)
+	}
+	query := url.Values{}
+	query.Set("icon", b.BarkIcon)
+	query.Set("sound", b.BarkSound)
+	query.Set("group", b.BarkGroup)
+	query.Set("level", b.BarkLevel)
+	query.Set("url", b.BarkUrl)
+	u := fmt.Sprintf("%s/%s/%s?%s", push, url.PathEscape(title), url.PathEscape(content), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return do(nil, req)
+}
+
+type Gotify struct {
+	GotifyUrl      string      `json:"gotifyUrl"`
+	GotifyToken    string      `json:"gotifyToken"`
+	GotifyPriority json.Number `json:"gotifyPriority,omitempty"`
+}
+
+func (g *Gotify) Send(ctx context.Context, title, content string) error {
+	data := url.Values{}
+	data.Set("title", title)
+	data.Set("message", content)
+	if g.GotifyPriority != "" {
+		data.Set("priority", g.GotifyPriority.String())
+	}
+	u := fmt.Sprintf("%s/message?token=%s", strings.TrimSuffix(g.GotifyUrl, "/"), url.QueryEscape(g.GotifyToken))
+	return postForm(ctx, nil, u, data)
+}
+
+type GoCqHttpBot struct {
+	GoCqHttpBotUrl   string `json:"goCqHttpBotUrl"`
+	GoCqHttpBotToken string `json:"goCqHttpBotToken"`
+	GoCqHttpBotQq    string `json:"goCqHttpBotQq"`
+}
+
+func (g *GoCqHttpBot) Send(ctx context.Context, title, content string) error {
+	u := fmt.Sprintf("%s?user_id=%s", g.GoCqHttpBotUrl, url.QueryEscape(g.GoCqHttpBotQq))
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+g.GoCqHttpBotToken)
+	return postJSON(ctx, nil, u, header, map[string]string{
+		"message": fmt.Sprintf("%s\n%s", title, content),
+	})
+}
+
+type ServerChan struct {
+	ServerChanKey string `json:"serverChanKey"`
+}
+
+func (s *ServerChan) Send(ctx context.Context, title, content string) error {
+	u := fmt.Sprintf("https://sc.ftqq.com/%s.send", s.ServerChanKey)
+	if strings.HasPrefix(s.ServerChanKey, "SCT") {
+		u = fmt.Sprintf("https://sctapi.ftqq.com/%s.send", s.ServerChanKey)
+	}
+	data := url.Values{}
+	data.Set("title", title)
+	data.Set("desp", content)
+	return postForm(ctx, nil, u, data)
+}
+
+type PushDeer struct {
+	PushDeerKey string `json:"pushDeerKey"`
+	PushDeerUrl string `json:"pushDeerUrl,omitempty"`
+}
+
+func (p *PushDeer) Send(ctx context.Context, title, content string) error {
+	u := p.PushDeerUrl
+	if u == "" {
+		u = "https://api2.pushdeer.com/message/push"
+	}
+	data := url.Values{}
+	data.Set("pushkey", p.PushDeerKey)
+	data.Set("text", title)
+	data.Set("desp", content)
+	data.Set("type", "markdown")
+	return postForm(ctx, nil, u, data)
+}
+
+type TelegramBot struct {
+	TelegramBotToken     string `json:"telegramBotToken"`
+	TelegramBotUserId    string `json:"telegramBotUserId"`
+	TelegramBotProxyHost string `json:"telegramBotProxyHost,omitempty"`
+	TelegramBotProxyPort string `json:"telegramBotProxyPort,omitempty"`
+	TelegramBotProxyAuth string `json:"telegramBotProxyAuth,omitempty"`
+	TelegramBotApiHost   string `json:"telegramBotApiHost,omitempty"`
+}
+
+func (t *TelegramBot) Send(ctx context.Context, title, content string) error {
+	apiHost := t.TelegramBotApiHost
+	if apiHost == "" {
+		apiHost = "https://api.telegram.org"
+	}
+	var client *http.Client
+	if t.TelegramBotProxyHost != "" && t.TelegramBotProxyPort != "" {
+		proxyURL := fmt.Sprintf("http://%s:%s", t.TelegramBotProxyHost, t.TelegramBotProxyPort)
+		if t.TelegramBotProxyAuth != "" {
+			proxyURL = fmt.Sprintf("http://%s@%s:%s", t.TelegramBotProxyAuth, t.TelegramBotProxyHost, t.TelegramBotProxyPort)
+		}
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return errors.Wrap(err, "invalid proxy")
+		}
+		client = &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxy)}}
+	}
+	data := url.Values{}
+	data.Set("chat_id", t.TelegramBotUserId)
+	data.Set("text", fmt.Sprintf("%s\n\n%s", title, content))
+	data.Set("disable_web_page_preview", "true")
+	return postForm(ctx, client, fmt.Sprintf("%s/bot%s/sendMessage", apiHost, t.TelegramBotToken), data)
+}
+
+type WeWorkBot struct {
+	WeWorkBotKey string `json:"weWorkBotKey"`
+	WeWorkOrigin string `json:"weWorkOrigin,omitempty"`
+}
+
+func (w *WeWorkBot) Send(ctx context.Context, title, content string) error {
+	origin := w.WeWorkOrigin
+	if origin == "" {
+		origin = "https://qyapi.weixin.qq.com"
+	}
+	return postJSON(ctx, nil, fmt.Sprintf("%s/cgi-bin/webhook/send?key=%s", origin, w.WeWorkBotKey), nil, map[string]any{
+		"msgtype": "text",
+		"text": map[string]string{
+			"content": fmt.Sprintf("%s\n\n%s", title, content),
+		},
+	})
+}
+
+func postForm(ctx context.Context, client *http.Client, u string, data url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(client, req)
+}
+
+func postJSON(ctx context.Context, client *http.Client, u string, header http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(client, req)
+}
+
+func init() {
+	RegisterNotifier("bark", func(config []byte) (Notifier, error) {
+		b, err := unmarshalConfig[Bark](config)
+		if err != nil {
+			return nil, err
+		}
+		if len(b.BarkPush) < 2 {
+			return nil, errors.New("barkPush is required")
+		}
+		return b, nil
+	})
+	RegisterNotifier("gotify", newFromConfig[Gotify])
+	RegisterNotifier("goCqHttpBot", newFromConfig[GoCqHttpBot])
+	RegisterNotifier("serverChan", newFromConfig[ServerChan])
+	RegisterNotifier("pushDeer", newFromConfig[PushDeer])
+	RegisterNotifier("telegramBot", newFromConfig[TelegramBot])
+	RegisterNotifier("weWorkBot", newFromConfig[WeWorkBot])
+}
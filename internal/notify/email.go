@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Email sends the message with SMTP
+type Email struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	// comma separated addresses
+	To string `json:"to"`
+	// use implicit TLS (usually port 465), otherwise STARTTLS is used if the server supports it
+	SSL bool `json:"ssl"`
+}
+
+func (e *Email) Send(ctx context.Context, title, content string) error {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if e.SSL {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: e.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+	if !e.SSL {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err = client.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if e.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+	from := e.From
+	if from == "" {
+		from = e.Username
+	}
+	if err = client.Mail(from); err != nil {
+		return err
+	}
+	to := e.recipients()
+	for _, addr := range to {
+		if err = client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		from, strings.Join(to, ", "), mime.QEncoding.Encode("UTF-8", title), content)
+	if _, err = w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (e *Email) recipients() []string {
+	var to []string
+	for _, addr := range strings.Split(e.To, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	return to
+}
+
+func init() {
+	RegisterNotifier("email", func(config []byte) (Notifier, error) {
+		e, err := unmarshalConfig[Email](config)
+		if err != nil {
+			return nil, err
+		}
+		if e.Host == "" || len(e.recipients()) == 0 {
+			return nil, errors.New("host and to are required")
+		}
+		if e.Port == 0 {
+			e.Port = 25
+			if e.SSL {
+				e.Port = 465
+			}
+		}
+		return e, nil
+	})
+}
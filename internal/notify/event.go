@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type Event string
+
+const (
+	EventLogin             Event = "login"
+	EventLoginFailed       Event = "login_failed"
+	EventCopySucceeded     Event = "copy_succeeded"
+	EventCopyFailed        Event = "copy_failed"
+	EventDownloadSucceeded Event = "download_succeeded"
+	EventDownloadFailed    Event = "download_failed"
+	EventUploadSucceeded   Event = "upload_succeeded"
+	EventStorageInitFailed Event = "storage_init_failed"
+	EventStorageError      Event = "storage_error"
+	EventIndexBuilt        Event = "index_built"
+	EventTest              Event = "test"
+)
+
+type eventInfo struct {
+	// the setting to switch the event on or off, empty means always on
+	switchKey string
+	// the first line of the rendered template is the title, the rest is the content
+	template string
+}
+
+var events = map[Event]eventInfo{
+	EventLogin:             {conf.NotifyOnLogin, "Alist登录成功\n登录用户:{{.Username}}\n登录IP:{{.IP}}"},
+	EventLoginFailed:       {conf.NotifyOnLogin, "{{.IP}}登录Alist失败:\n登录用户:{{.Username}}\n{{.Error}}"},
+	EventCopySucceeded:     {conf.NotifyOnCopySucceeded, "文件复制结果\n复制{{.SrcPath}}到{{.DstPath}}成功"},
+	EventCopyFailed:        {conf.NotifyOnCopyFailed, "文件复制结果\n{{.Name}}:{{.Error}}"},
+	EventDownloadSucceeded: {conf.NotifyOnDownloadSucceeded, "文件下载结果\n{{.Url}}下载成功"},
+	EventDownloadFailed:    {conf.NotifyOnDownloadFailed, "文件下载结果\n{{.Url}}下载失败:{{.Error}}"},
+	EventUploadSucceeded:   {conf.NotifyOnUploadSucceeded, "文件上传结果\n{{.Name}}上传到{{.DstPath}}成功"},
+	EventStorageInitFailed: {conf.NotifyOnStorageError, "存储加载失败\n{{.MountPath}}({{.Driver}}):{{.Status}}"},
+	EventStorageError:      {conf.NotifyOnStorageError, "存储状态异常\n{{.MountPath}}({{.Driver}}):{{.Status}}"},
+	EventIndexBuilt:        {conf.NotifyOnIndexBuilt, "索引构建完成\n共{{.Count}}个对象{{if .Error}}\n错误:{{.Error}}{{end}}"},
+	EventTest:              {"", "{{.SiteTitle}}测试通知\n欢迎使用!!!!"},
+}
+
+// Events returns all events in a stable order
+func Events() []Event {
+	return []Event{EventLogin, EventLoginFailed, EventCopySucceeded, EventCopyFailed,
+		EventDownloadSucceeded, EventDownloadFailed, EventUploadSucceeded,
+		EventStorageInitFailed, EventStorageError, EventIndexBuilt, EventTest}
+}
+
+// TemplateKey returns the key of the setting which overrides the template of the event
+func TemplateKey(event Event) string {
+	return conf.NotifyTemplatePrefix + string(event)
+}
+
+// DefaultTemplate returns the built-in template of the event
+func DefaultTemplate(event Event) string {
+	return events[event].template
+}
+
+// Data is passed to the template of an event
+type Data map[string]any
+
+func enabled(event Event) bool {
+	if !conf.Conf.Notify || !setting.GetBool(conf.NotifyEnabled) {
+		return false
+	}
+	info, ok := events[event]
+	if !ok {
+		return false
+	}
+	return info.switchKey == "" || setting.GetBool(info.switchKey)
+}
+
+func render(event Event, data Data) (string, string, error) {
+	text := setting.GetStr(TemplateKey(event))
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate(event)
+	}
+	tmpl, err := template.New(string(event)).Parse(text)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed parse template of %s", event)
+	}
+	if data == nil {
+		data = Data{}
+	}
+	data["Event"] = string(event)
+	data["SiteTitle"] = setting.GetStr(conf.SiteTitle)
+	data["Time"] = time.Now().Format("2006-01-02 15:04:05")
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", "", errors.Wrapf(err, "failed execute template of %s", event)
+	}
+	title, content, _ := strings.Cut(strings.TrimSpace(buf.String()), "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(content), nil
+}
+
+// Send notifies all enabled channels of the event in background if the event is switched on
+func Send(event Event, data Data) {
+	if !enabled(event) {
+		return
+	}
+	go func() {
+		if err := send(context.Background(), event, data, ""); err != nil {
+			log.Errorf("failed send notification of %s: %+v", event, err)
+		}
+	}()
+}
+
+// Test sends a test notification to the channel named channel,
+// or all enabled channels if channel is empty, and waits for the results
+func Test(ctx context.Context, channel string) error {
+	return send(ctx, EventTest, nil, channel)
+}
+
+func send(ctx context.Context, event Event, data Data, channel string) error {
+	title, content, err := render(event, data)
+	if err != nil {
+		return err
+	}
+	channels, err := GetChannels()
+	if err != nil {
+		return err
+	}
+	var errs []string
+	sent := 0
+	for _, c := range channels {
+		if channel != "" && c.Name != channel {
+			continue
+		}
+		if channel == "" && !c.Enabled {
+			continue
+		}
+		sent++
+		n, err := c.notifier()
+		if err == nil {
+			err = n.Send(ctx, title, content)
+		}
+		if err != nil {
+			errs = append(errs, c.Name+": "+err.Error())
+		}
+	}
+	if sent == 0 {
+		if channel != "" {
+			return errors.Errorf("notification channel [%s] not found", channel)
+		}
+		return errors.New("no notification channel is enabled")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
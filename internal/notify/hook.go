@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+// lastStatus records the last notified error status of the storages, drivers may save
+// the same error status many times, e.g. on every failed request, but it's notified once
+var lastStatus sync.Map
+
+func storageErrorHook(typ string, storage driver.Driver) {
+	s := storage.GetStorage()
+	event := EventStorageInitFailed
+	if typ == "status" {
+		if prev, ok := lastStatus.Load(s.MountPath); ok && prev.(string) == s.Status {
+			return
+		}
+		event = EventStorageError
+	}
+	lastStatus.Store(s.MountPath, s.Status)
+	Send(event, Data{
+		"MountPath": s.MountPath,
+		"Driver":    storage.Config().Name,
+		"Status":    s.Status,
+	})
+}
+
+func storageHook(typ string, storage driver.Driver) {
+	if typ == "del" {
+		lastStatus.Delete(storage.GetStorage().MountPath)
+	}
+}
+
+func init() {
+	op.RegisterStorageErrorHook(storageErrorHook)
+	op.RegisterStorageHook(storageHook)
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+)
+
+// Notifier sends messages to a notification channel
+type Notifier interface {
+	Send(ctx context.Context, title, content string) error
+}
+
+// New creates a Notifier with the config of a channel, which is a JSON object
+type New func(config []byte) (Notifier, error)
+
+var notifiers = map[string]New{}
+
+// RegisterNotifier registers a type of channel, it should be called in init()
+func RegisterNotifier(typ string, new New) {
+	notifiers[typ] = new
+}
+
+// Types returns the registered types of channel
+func Types() []string {
+	types := make([]string, 0, len(notifiers))
+	for typ := range notifiers {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Channel is a configured notification channel, all enabled channels receive the messages
+type Channel struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Enabled bool            `json:"enabled"`
+	Config  json.RawMessage `json:"config"`
+}
+
+func (c Channel) notifier() (Notifier, error) {
+	new, ok := notifiers[c.Type]
+	if !ok {
+		return nil, errors.Errorf("unknown notification channel type: %s", c.Type)
+	}
+	config := []byte(c.Config)
+	if len(strings.TrimSpace(string(config))) == 0 {
+		config = []byte("{}")
+	}
+	n, err := new(config)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid config of channel [%s]", c.Name)
+	}
+	return n, nil
+}
+
+// GetChannels returns the configured channels, the single platform configured
+// by notify_platform and notify_value is used if no channel is configured
+func GetChannels() ([]Channel, error) {
+	value := setting.GetStr(conf.NotifyChannels)
+	if strings.TrimSpace(value) != "" {
+		var channels []Channel
+		if err := json.Unmarshal([]byte(value), &channels); err != nil {
+			return nil, errors.Wrapf(err, "failed parse %s", conf.NotifyChannels)
+		}
+		return channels, nil
+	}
+	platform := setting.GetStr(conf.NotifyPlatform)
+	if platform == "" || platform == "closed" {
+		return nil, nil
+	}
+	return []Channel{{
+		Name:    platform,
+		Type:    platform,
+		Enabled: true,
+		Config:  json.RawMessage(setting.GetStr(conf.NotifyValue)),
+	}}, nil
+}
+
+// unmarshalConfig is a helper of New
+func unmarshalConfig[T any](config []byte) (*T, error) {
+	var c T
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// newFromConfig is a New for the notifiers which need no validation
+func newFromConfig[T any, PT interface {
+	*T
+	Notifier
+}](config []byte) (Notifier, error) {
+	c, err := unmarshalConfig[T](config)
+	if err != nil {
+		return nil, err
+	}
+	return PT(c), nil
+}
+
+// do sends the request and checks the status code
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, body)
+	}
+	return nil
+}
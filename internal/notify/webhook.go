@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Webhook sends the message with a custom request,
+// $title and $content in the url and the body are replaced with the message
+type Webhook struct {
+	WebhookUrl         string `json:"webhookUrl"`
+	WebhookBody        string `json:"webhookBody,omitempty"`    // one "key: value" per line
+	WebhookHeaders     string `json:"webhookHeaders,omitempty"` // one "key: value" per line
+	WebhookMethod      string `json:"webhookMethod"`
+	WebhookContentType string `json:"webhookContentType"`
+}
+
+func (w *Webhook) Send(ctx context.Context, title, content string) error {
+	headers, err := parseLines(w.WebhookHeaders)
+	if err != nil {
+		return errors.WithMessage(err, "malformed headers")
+	}
+	body := strings.ReplaceAll(strings.ReplaceAll(w.WebhookBody, "$title", title), "$content", content)
+	fields, err := parseLines(body)
+	if err != nil {
+		return errors.WithMessage(err, "malformed body")
+	}
+	var buf *bytes.Buffer
+	contentType := w.WebhookContentType
+	switch contentType {
+	case "application/json":
+		buf, err = formatJSON(fields)
+	case "multipart/form-data":
+		buf, contentType, err = formatMultipart(fields)
+	case "application/x-www-form-urlencoded", "text/plain":
+		buf = bytes.NewBufferString(formatURLForm(fields))
+	default:
+		return errors.Errorf("unsupported content type: %s", contentType)
+	}
+	if err != nil {
+		return err
+	}
+	u := strings.ReplaceAll(strings.ReplaceAll(w.WebhookUrl, "$title", url.QueryEscape(title)), "$content", url.QueryEscape(content))
+	method := w.WebhookMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return do(nil, req)
+}
+
+// parseLines parses "key: value" lines, empty lines are ignored
+func parseLines(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed line: %s", line)
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+func formatJSON(fields map[string]string) (*bytes.Buffer, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(data), nil
+}
+
+func formatMultipart(fields map[string]string) (*bytes.Buffer, string, error) {
+	var b bytes.Buffer
+	writer := multipart.NewWriter(&b)
+	for k, v := range fields {
+		if err := writer.WriteField(k, v); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &b, writer.FormDataContentType(), nil
+}
+
+func formatURLForm(fields map[string]string) string {
+	values := url.Values{}
+	for k, v := range fields {
+		values.Add(k, v)
+	}
+	return values.Encode()
+}
+
+func init() {
+	RegisterNotifier("webhook", func(config []byte) (Notifier, error) {
+		w, err := unmarshalConfig[Webhook](config)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(w.WebhookUrl, "$title") && !strings.Contains(w.WebhookBody, "$title") {
+			return nil, errors.New("$title is required in the url or the body")
+		}
+		return w, nil
+	})
+}
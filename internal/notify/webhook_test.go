@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	var got map[string]string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+	config, _ := json.Marshal(Webhook{
+		WebhookUrl:         server.URL,
+		WebhookBody:        "title: $title\ncontent: $content\n",
+		WebhookHeaders:     "Authorization: Bearer token",
+		WebhookMethod:      http.MethodPost,
+		WebhookContentType: "application/json",
+	})
+	n, err := notifiers["webhook"](config)
+	if err != nil {
+		t.Fatalf("failed create webhook: %+v", err)
+	}
+	if err = n.Send(context.Background(), "hello", "world"); err != nil {
+		t.Fatalf("failed send: %+v", err)
+	}
+	if got["title"] != "hello" || got["content"] != "world" {
+		t.Errorf("unexpected body: %v", got)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("unexpected header: %s", auth)
+	}
+	if _, err = notifiers["webhook"]([]byte(`{"webhookUrl":"http://localhost"}`)); err == nil {
+		t.Errorf("expect error without $title")
+	}
+}
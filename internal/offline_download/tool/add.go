@@ -8,7 +8,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
-	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
@@ -29,7 +29,7 @@ type AddURLArgs struct {
 	DeletePolicy DeletePolicy
 }
 
-func AddURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
+func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, error) {
 	// get tool
 	tool, err := Tools.Get(args.Tool)
 
@@ -74,6 +74,8 @@ func AddURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
 		DeletePolicy: args.DeletePolicy,
 		tool:         tool,
 	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t.SetCreator(taskCreator)
 	if tool.Name() == "storage" {
 		args := model.FsOtherArgs{
 			Path:   args.DstDirPath,
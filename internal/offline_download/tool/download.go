@@ -6,15 +6,16 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
-	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/notify"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/tache"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
 type DownloadTask struct {
-	tache.Base
+	task.TaskExtension
 	Name         string       `json:"name"`
 	Url          string       `json:"url"`
 	DstDirPath   string       `json:"dst_dir_path"`
@@ -29,20 +30,13 @@ type DownloadTask struct {
 }
 
 func (t *DownloadTask) OnFailed() {
-	result := fmt.Sprintf("%s下载失败:%s", t.Url, t.GetErr())
-	log.Debug(result)
-	if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnDownloadFailed) {
-		go op.Notify("文件下载结果", result)
-	}
-
+	log.Debugf("download %s failed: %s", t.Url, t.GetErr())
+	notify.Send(notify.EventDownloadFailed, notify.Data{"Url": t.Url, "Error": t.GetErr()})
 }
 
 func (t *DownloadTask) OnSucceeded() {
-	result := fmt.Sprintf("%s下载成功", t.Url)
-	log.Debug(result)
-	if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnDownloadSucceeded) {
-		go op.Notify("文件下载结果", result)
-	}
+	log.Debugf("download %s succeeded", t.Url)
+	notify.Send(notify.EventDownloadSucceeded, notify.Data{"Url": t.Url})
 }
 
 func (t *DownloadTask) Run() error {
@@ -154,13 +148,15 @@ func (t *DownloadTask) Complete() error {
 	// upload files
 	for i, _ := range files {
 		file := files[i]
-		TransferTaskManager.Add(&TransferTask{
+		transferTask := &TransferTask{
 			file:         file,
 			DstDirPath:   t.DstDirPath,
 			TempDir:      t.TempDir,
 			DeletePolicy: t.DeletePolicy,
 			FileDir:      file.Path,
-		})
+		}
+		transferTask.InheritCreator(&t.TaskExtension)
+		TransferTaskManager.Add(transferTask)
 	}
 	return nil
 }
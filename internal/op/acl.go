@@ -0,0 +1,155 @@
+package op
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// all rules are kept in memory, there are usually only a few of them
+var (
+	aclRules  []model.AclRule
+	aclLoaded bool
+	aclMu     sync.RWMutex
+)
+
+func getAclRules() []model.AclRule {
+	aclMu.RLock()
+	if aclLoaded {
+		defer aclMu.RUnlock()
+		return aclRules
+	}
+	aclMu.RUnlock()
+	aclMu.Lock()
+	defer aclMu.Unlock()
+	if !aclLoaded {
+		rules, err := db.GetAclRules()
+		if err != nil {
+			log.Errorf("failed get acl rules: %+v", err)
+			return nil
+		}
+		aclRules, aclLoaded = rules, true
+	}
+	return aclRules
+}
+
+func clearAclCache() {
+	aclMu.Lock()
+	defer aclMu.Unlock()
+	aclRules, aclLoaded = nil, false
+}
+
+// CheckAcl evaluates the nearest acl rules of the user's groups on the path,
+// applied is false if no rule applies, then the permissions of the user and the metas decide.
+// The permissions of the rules on the same path are merged.
+func CheckAcl(user *model.User, path string, perm int32) (applied bool, allowed bool) {
+	if user == nil || user.IsAdmin() || len(user.GroupIDs) == 0 {
+		return false, false
+	}
+	path = utils.FixAndCleanPath(path)
+	var nearest string
+	var granted int32
+	for _, r := range getAclRules() {
+		if !user.InGroup(r.GroupID) || !utils.IsSubPath(r.Path, path) {
+			continue
+		}
+		switch {
+		case !applied || len(r.Path) > len(nearest):
+			nearest, granted, applied = r.Path, r.Permission, true
+		case r.Path == nearest:
+			granted |= r.Permission
+		}
+	}
+	return applied, granted&perm == perm
+}
+
+// AclCanTraverse reports whether the rules of the user's groups grant reading any sub path of the path,
+// the path can be listed to reach them even if it's not allowed itself
+func AclCanTraverse(user *model.User, path string) bool {
+	if user == nil || user.IsAdmin() || len(user.GroupIDs) == 0 {
+		return false
+	}
+	path = utils.FixAndCleanPath(path)
+	for _, r := range getAclRules() {
+		if user.InGroup(r.GroupID) && r.Path != path && utils.IsSubPath(path, r.Path) &&
+			r.Permission&(model.AclRead|model.AclList) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AclAllowed is CheckAcl with the fallback used when no rule applies
+func AclAllowed(user *model.User, path string, perm int32, fallback bool) bool {
+	if applied, allowed := CheckAcl(user, path, perm); applied {
+		return allowed
+	}
+	return fallback
+}
+
+// AclVisible reports whether the path can be seen in the list of its parent
+func AclVisible(user *model.User, path string) bool {
+	applied, allowed := CheckAcl(user, path, model.AclList)
+	if !applied || allowed {
+		return true
+	}
+	if _, allowed = CheckAcl(user, path, model.AclRead); allowed {
+		return true
+	}
+	return AclCanTraverse(user, path)
+}
+
+func GetGroups() ([]model.Group, error) {
+	return db.GetGroups()
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	return db.GetGroupById(id)
+}
+
+func CreateGroup(g *model.Group) error {
+	return db.CreateGroup(g)
+}
+
+func UpdateGroup(g *model.Group) error {
+	return db.UpdateGroup(g)
+}
+
+func DeleteGroupById(id uint) error {
+	defer clearAclCache()
+	return db.DeleteGroupById(id)
+}
+
+func GetAclRules() ([]model.AclRule, error) {
+	return db.GetAclRules()
+}
+
+func GetAclRuleById(id uint) (*model.AclRule, error) {
+	return db.GetAclRuleById(id)
+}
+
+func CreateAclRule(r *model.AclRule) error {
+	r.Path = utils.FixAndCleanPath(r.Path)
+	if _, err := db.GetGroupById(r.GroupID); err != nil {
+		return err
+	}
+	defer clearAclCache()
+	return db.CreateAclRule(r)
+}
+
+func UpdateAclRule(r *model.AclRule) error {
+	r.Path = utils.FixAndCleanPath(r.Path)
+	if _, err := db.GetGroupById(r.GroupID); err != nil {
+		return err
+	}
+	defer clearAclCache()
+	return db.UpdateAclRule(r)
+}
+
+func DeleteAclRuleById(id uint) error {
+	defer clearAclCache()
+	return db.DeleteAclRuleById(id)
+}
@@ -0,0 +1,59 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestCheckAcl(t *testing.T) {
+	g := model.Group{Name: "acl_test"}
+	if err := op.CreateGroup(&g); err != nil {
+		t.Fatalf("failed create group: %+v", err)
+	}
+	rules := []model.AclRule{
+		{GroupID: g.ID, Path: "/docs", Permission: model.AclRead | model.AclList},
+		{GroupID: g.ID, Path: "/docs/team", Permission: model.AclWrite},
+		{GroupID: g.ID, Path: "/docs/team", Permission: model.AclRead},
+		{GroupID: g.ID, Path: "/private/shared", Permission: model.AclRead},
+	}
+	for i := range rules {
+		if err := op.CreateAclRule(&rules[i]); err != nil {
+			t.Fatalf("failed create acl rule: %+v", err)
+		}
+	}
+	user := &model.User{ID: 200, Username: "acl", GroupIDs: []uint{g.ID}}
+	var cases = []struct {
+		path    string
+		perm    int32
+		applied bool
+		allowed bool
+	}{
+		{"/docs/a.txt", model.AclRead, true, true},
+		{"/docs/a.txt", model.AclWrite, true, false},
+		// the nearest rules are merged, and the farther ones don't apply
+		{"/docs/team/b.txt", model.AclRead | model.AclWrite, true, true},
+		{"/docs/team/b.txt", model.AclList, true, false},
+		{"/docsx", model.AclRead, false, false},
+		{"/other", model.AclRead, false, false},
+	}
+	for _, c := range cases {
+		applied, allowed := op.CheckAcl(user, c.path, c.perm)
+		if applied != c.applied || allowed != c.allowed {
+			t.Errorf("check %d on [%s]: expect (%v, %v), got (%v, %v)", c.perm, c.path, c.applied, c.allowed, applied, allowed)
+		}
+	}
+	if !op.AclCanTraverse(user, "/private") || op.AclCanTraverse(user, "/other") {
+		t.Errorf("expect only the parents of the granted paths to be traversable")
+	}
+	if applied, _ := op.CheckAcl(&model.User{ID: 201}, "/docs", model.AclRead); applied {
+		t.Errorf("expect no rule applies to the users without groups")
+	}
+	if err := op.DeleteGroupById(g.ID); err != nil {
+		t.Fatalf("failed delete group: %+v", err)
+	}
+	if applied, _ := op.CheckAcl(user, "/docs", model.AclRead); applied {
+		t.Errorf("expect the rules to be deleted with the group")
+	}
+}
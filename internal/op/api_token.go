@@ -0,0 +1,181 @@
+package op
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	apiTokenKeyIdLength  = 16
+	apiTokenSecretLength = 20 // bytes
+	// the last used time is saved at most once a minute
+	apiTokenTouchInterval = time.Minute
+)
+
+func GetApiTokensByUser(userID uint) ([]model.ApiToken, error) {
+	return db.GetApiTokensByUser(userID)
+}
+
+func GetApiTokenById(id uint) (*model.ApiToken, error) {
+	return db.GetApiTokenById(id)
+}
+
+func DeleteApiTokenById(id uint) error {
+	return db.DeleteApiTokenById(id)
+}
+
+// CreateApiToken generates a token for the user, the token is only returned here,
+// the permission should be a subset of the permissions of the user
+func CreateApiToken(user *model.User, token *model.ApiToken) (string, error) {
+	if !user.IsAdmin() && token.Permission&^user.Permission != 0 {
+		return "", errors.WithStack(errs.PermissionDenied)
+	}
+	secret := make([]byte, apiTokenSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.WithStack(err)
+	}
+	secretStr := hex.EncodeToString(secret)
+	token.ID = 0
+	token.UserID = user.ID
+	token.KeyID = model.ApiTokenPrefix + random.String(apiTokenKeyIdLength)
+	token.SecretHash = hashApiTokenSecret(secretStr)
+	s3Secret, err := encryptApiTokenS3Secret(deriveApiTokenS3Secret(secretStr))
+	if err != nil {
+		return "", err
+	}
+	token.S3Secret = s3Secret
+	token.BasePath = utils.FixAndCleanPath(token.BasePath)
+	token.LastUsed = nil
+	token.Created = time.Now()
+	if err := db.CreateApiToken(token); err != nil {
+		return "", err
+	}
+	return token.KeyID + "_" + secretStr, nil
+}
+
+func hashApiTokenSecret(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+// the server side key of the s3 secrets, it's in the config file rather than the database,
+// so the s3 secrets can't be taken from a leaked database
+func apiTokenS3Key() []byte {
+	key := sha256.Sum256([]byte("s3:" + conf.Conf.JwtSecret))
+	return key[:]
+}
+
+func deriveApiTokenS3Secret(secret string) string {
+	mac := hmac.New(sha256.New, apiTokenS3Key())
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func encryptApiTokenS3Secret(secret string) (string, error) {
+	block, err := aes.NewCipher(apiTokenS3Key())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// ApiTokenS3Secret returns the secret access key of the s3 server for the token,
+// it's derived from the token secret when the token is created and saved encrypted
+// since the signatures can't be verified without a secret
+func ApiTokenS3Secret(token *model.ApiToken) (string, error) {
+	// the tokens created before have to be recreated to access the s3 server
+	data, err := hex.DecodeString(token.S3Secret)
+	if err != nil || token.S3Secret == "" {
+		return "", errors.WithStack(errs.InvalidApiToken)
+	}
+	block, err := aes.NewCipher(apiTokenS3Key())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.WithStack(errs.InvalidApiToken)
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		// the jwt secret is changed
+		return "", errors.WithStack(errs.InvalidApiToken)
+	}
+	return string(secret), nil
+}
+
+// GetUserByApiToken returns the user restricted by the token
+func GetUserByApiToken(tokenStr string) (*model.User, error) {
+	i := strings.LastIndex(tokenStr, "_")
+	if !strings.HasPrefix(tokenStr, model.ApiTokenPrefix) || i < len(model.ApiTokenPrefix) {
+		return nil, errors.WithStack(errs.InvalidApiToken)
+	}
+	token, err := db.GetApiTokenByKeyId(tokenStr[:i])
+	if err != nil {
+		return nil, errors.WithStack(errs.InvalidApiToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashApiTokenSecret(tokenStr[i+1:])), []byte(token.SecretHash)) != 1 {
+		return nil, errors.WithStack(errs.InvalidApiToken)
+	}
+	return userOfApiToken(token)
+}
+
+// GetApiTokenUserByKeyId returns the token and the user restricted by it, used by the s3 server
+func GetApiTokenUserByKeyId(keyID string) (*model.ApiToken, *model.User, error) {
+	token, err := db.GetApiTokenByKeyId(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := userOfApiToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, user, nil
+}
+
+func userOfApiToken(token *model.ApiToken) (*model.User, error) {
+	if token.Expired() {
+		return nil, errors.WithStack(errs.ApiTokenExpired)
+	}
+	user, err := GetUserById(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errors.New("the user is disabled")
+	}
+	now := time.Now()
+	if token.LastUsed == nil || now.Sub(*token.LastUsed) > apiTokenTouchInterval {
+		if err := db.UpdateApiTokenLastUsed(token.ID, now); err != nil {
+			log.Warnf("failed update last used time of api token %d: %+v", token.ID, err)
+		}
+	}
+	return token.Restrict(user), nil
+}
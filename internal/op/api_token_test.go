@@ -0,0 +1,67 @@
+package op_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestApiToken(t *testing.T) {
+	user := &model.User{Username: "token", BasePath: "/home", Role: model.GENERAL, Permission: 0b1000_0001}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	if _, err := op.CreateApiToken(user, &model.ApiToken{Name: "too much", Permission: 0b1000_0011}); err == nil {
+		t.Errorf("expect a token can't have more permissions than the user")
+	}
+	token := model.ApiToken{Name: "ci", Permission: 0b1000_0000, BasePath: "backup"}
+	tokenStr, err := op.CreateApiToken(user, &token)
+	if err != nil {
+		t.Fatalf("failed create token: %+v", err)
+	}
+	got, err := op.GetUserByApiToken(tokenStr)
+	if err != nil {
+		t.Fatalf("failed get user by token: %+v", err)
+	}
+	if got.ID != user.ID || got.Permission != 0b1000_0000 || got.BasePath != "/home/backup" || got.TokenID != token.ID {
+		t.Errorf("expect the user restricted by the token, got %+v", got)
+	}
+	if _, err = op.GetUserByApiToken(tokenStr + "0"); !errors.Is(err, errs.InvalidApiToken) {
+		t.Errorf("expect a wrong secret to be refused, got %+v", err)
+	}
+	stored, err := op.GetApiTokenById(token.ID)
+	if err != nil {
+		t.Fatalf("failed get token: %+v", err)
+	}
+	if stored.LastUsed == nil {
+		t.Errorf("expect the last used time to be recorded")
+	}
+	if strings.Contains(stored.S3Secret, tokenStr[strings.LastIndex(tokenStr, "_")+1:]) {
+		t.Errorf("expect the s3 secret to be saved encrypted")
+	}
+	if s3Secret, err := op.ApiTokenS3Secret(stored); err != nil || s3Secret == "" {
+		t.Errorf("failed get the s3 secret: %+v", err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	expired := model.ApiToken{Name: "expired", ExpiresAt: &past}
+	expiredStr, err := op.CreateApiToken(user, &expired)
+	if err != nil {
+		t.Fatalf("failed create token: %+v", err)
+	}
+	if _, err = op.GetUserByApiToken(expiredStr); !errors.Is(err, errs.ApiTokenExpired) {
+		t.Errorf("expect the expired token to be refused, got %+v", err)
+	}
+
+	if err = op.DeleteApiTokenById(token.ID); err != nil {
+		t.Fatalf("failed delete token: %+v", err)
+	}
+	if _, err = op.GetUserByApiToken(tokenStr); err == nil {
+		t.Errorf("expect the revoked token to be refused")
+	}
+}
@@ -0,0 +1,61 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// changeCursors are the cursors of the change feeds by the mount paths,
+// they are not persisted, so the changes while not running are missed
+var changeCursors generic_sync.MapOf[string, string]
+
+// SyncChanges polls the change feed of the storage, then the caches of the changed dirs are cleared,
+// and the dirs are listed again, so that the objsUpdateHooks update the search index
+func SyncChanges(ctx context.Context, storage driver.Driver) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	feed, ok := storage.(driver.ChangeFeed)
+	if !ok {
+		return errs.NotImplement
+	}
+	key := storage.GetStorage().MountPath
+	cursor, _ := changeCursors.Load(key)
+	changes, next, err := feed.GetChanges(ctx, cursor)
+	if err != nil {
+		return errors.WithMessage(err, "failed get changes")
+	}
+	changeCursors.Store(key, next)
+	if cursor == "" {
+		return nil
+	}
+	dirs := mapset.NewSet[string]()
+	for _, c := range changes {
+		path := utils.FixAndCleanPath(c.Path)
+		if path == "/" {
+			dirs.Add(path)
+			continue
+		}
+		if c.IsDir {
+			ClearCache(storage, path)
+		}
+		dirs.Add(stdpath.Dir(path))
+	}
+	for _, dir := range dirs.ToSlice() {
+		ClearCache(storage, dir)
+		if _, err := List(ctx, storage, dir, model.ListArgs{}, true); err != nil {
+			// the dir may be removed after the change
+			log.Debugf("failed list changed dir [%s]: %+v", Key(storage, dir), err)
+		}
+	}
+	return nil
+}
@@ -143,6 +143,17 @@ func getMainItems(config driver.Config) []driver.Item {
 		Default:  "false",
 		Required: true,
 	})
+	items = append(items, []driver.Item{{
+		Name:    "download_limit",
+		Type:    conf.TypeNumber,
+		Default: "0",
+		Help:    "KB/s of the downloads proxied by the server, 0 means unlimited",
+	}, {
+		Name:    "upload_limit",
+		Type:    conf.TypeNumber,
+		Default: "0",
+		Help:    "KB/s of the uploads, 0 means unlimited",
+	}}...)
 	return items
 }
 func getAdditionalItems(t reflect.Type, defaultRoot string) []driver.Item {
@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -402,6 +403,11 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		moveFileOwners(storage, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+		moveS3ObjectMetas(storage, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+		moveWebdavProps(storage, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
 	return errors.WithStack(err)
 }
 
@@ -436,6 +442,11 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		moveFileOwners(storage, srcPath, stdpath.Join(srcDirPath, dstName))
+		moveS3ObjectMetas(storage, srcPath, stdpath.Join(srcDirPath, dstName))
+		moveWebdavProps(storage, srcPath, stdpath.Join(srcDirPath, dstName))
+	}
 	return errors.WithStack(err)
 }
 
@@ -502,6 +513,9 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			if rawObj.IsDir() {
 				ClearCache(storage, path)
 			}
+			removeFileOwners(storage, path)
+			removeS3ObjectMetas(storage, path)
+			removeWebdavProps(storage, path)
 		}
 	default:
 		return errs.NotImplement
@@ -513,6 +527,7 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	ctx = stream.WithUploadLimiter(ctx, UploadLimiter(ctx, storage))
 	defer func() {
 		if err := file.Close(); err != nil {
 			log.Errorf("failed to close file streamer, %v", err)
@@ -524,6 +539,15 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	tempName := file.GetName() + ".alist_to_delete"
 	tempPath := stdpath.Join(dstDirPath, tempName)
 	fi, err := GetUnwrap(ctx, storage, dstPath)
+	// the usage grows by the size difference if the user overwrites its own file,
+	// otherwise the existing file is removed and accounted by Remove
+	usageSize, usageFiles := max(file.GetSize(), 0), int64(1)
+	if err == nil && fi.GetSize() > 0 && !storage.Config().NoOverwriteUpload && ownedBy(ctx, storage, dstPath) {
+		usageSize, usageFiles = usageSize-fi.GetSize(), 0
+	}
+	if err := CheckQuota(ctx, usageSize, usageFiles); err != nil {
+		return err
+	}
 	if err == nil {
 		if fi.GetSize() == 0 {
 			err = Remove(ctx, storage, dstPath)
@@ -573,6 +597,9 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		setFileOwner(ctx, storage, dstPath, max(file.GetSize(), 0))
+	}
 	log.Debugf("put file [%s] done", file.GetName())
 	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
@@ -594,3 +621,24 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	}
 	return errors.WithStack(err)
 }
+
+// SetModified sets the modification time of the obj if the driver supports it
+func SetModified(ctx context.Context, storage driver.Driver, path string, modified time.Time) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.SetModified)
+	if !ok {
+		return errs.NotImplement
+	}
+	path = utils.FixAndCleanPath(path)
+	rawObj, err := Get(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get obj")
+	}
+	if err = s.SetModified(ctx, model.UnwrapObj(rawObj), modified); err != nil {
+		return errors.WithStack(err)
+	}
+	ClearCache(storage, stdpath.Dir(path))
+	return nil
+}
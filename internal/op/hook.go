@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -86,6 +87,8 @@ var settingItemHooks = map[string]SettingItemHook{
 		conf.SlicesMap[conf.StorageGroups] = strings.Split(item.Value, ",")
 		return nil
 	},
+	conf.DownloadLimit: setServerRate(stream.ServerDownloadLimit),
+	conf.UploadLimit:   setServerRate(stream.ServerUploadLimit),
 }
 
 func RegisterSettingItemHook(key string, hook SettingItemHook) {
@@ -113,3 +116,19 @@ func callStorageHooks(typ string, storage driver.Driver) {
 func RegisterStorageHook(hook StorageHook) {
 	storageHooks = append(storageHooks, hook)
 }
+
+// StorageErrorHook is called with typ "init" when a storage fails to init,
+// or "status" when a storage is saved with an error status
+type StorageErrorHook func(typ string, storage driver.Driver)
+
+var storageErrorHooks = make([]StorageErrorHook, 0)
+
+func callStorageErrorHooks(typ string, storage driver.Driver) {
+	for _, hook := range storageErrorHooks {
+		hook(typ, storage)
+	}
+}
+
+func RegisterStorageErrorHook(hook StorageErrorHook) {
+	storageErrorHooks = append(storageErrorHooks, hook)
+}
@@ -0,0 +1,102 @@
+package op
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+)
+
+// rateLimiters keeps a limiter for each storage or user, so that their transfers share the rate,
+// the rate follows the limit once it's changed
+type rateLimiters struct {
+	mu sync.Mutex
+	m  map[uint]*rateLimiter
+}
+
+type rateLimiter struct {
+	limit int64 // KB/s
+	*stream.RateLimiter
+}
+
+func newRateLimiters() *rateLimiters {
+	return &rateLimiters{m: make(map[uint]*rateLimiter)}
+}
+
+func (ls *rateLimiters) get(id uint, limit int64) stream.Limiter {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l, ok := ls.m[id]
+	if limit <= 0 {
+		if ok {
+			delete(ls.m, id)
+		}
+		return nil
+	}
+	if !ok {
+		l = &rateLimiter{limit: limit, RateLimiter: stream.NewRateLimiter(limit * 1024)}
+		ls.m[id] = l
+	} else if l.limit != limit {
+		l.limit = limit
+		l.SetRate(limit * 1024)
+	}
+	return l
+}
+
+var (
+	storageDownloadLimiters = newRateLimiters()
+	storageUploadLimiters   = newRateLimiters()
+	userDownloadLimiters    = newRateLimiters()
+	userUploadLimiters      = newRateLimiters()
+)
+
+func composeLimiter(ctx context.Context, server stream.Limiter, storage stream.Limiter, users *rateLimiters, userLimit func(*model.User) int64) stream.Limiter {
+	var user stream.Limiter
+	if u, ok := ctx.Value("user").(*model.User); ok && u != nil {
+		user = users.get(u.ID, userLimit(u))
+	}
+	return stream.NewMultiLimiter(server, storage, user)
+}
+
+// DownloadLimiter composes the download limits of the server, the storage and the user of the ctx
+func DownloadLimiter(ctx context.Context, storage driver.Driver) stream.Limiter {
+	var sl stream.Limiter
+	if storage != nil {
+		s := storage.GetStorage()
+		sl = storageDownloadLimiters.get(s.ID, s.DownloadLimit)
+	}
+	return composeLimiter(ctx, stream.ServerDownloadLimit, sl, userDownloadLimiters, func(u *model.User) int64 {
+		return u.DownloadLimit
+	})
+}
+
+// UploadLimiter composes the upload limits of the server, the storage and the user of the ctx
+func UploadLimiter(ctx context.Context, storage driver.Driver) stream.Limiter {
+	var sl stream.Limiter
+	if storage != nil {
+		s := storage.GetStorage()
+		sl = storageUploadLimiters.get(s.ID, s.UploadLimit)
+	}
+	return composeLimiter(ctx, stream.ServerUploadLimit, sl, userUploadLimiters, func(u *model.User) int64 {
+		return u.UploadLimit
+	})
+}
+
+// setServerRate is the hook of the settings of the server limits in KB/s
+func setServerRate(l *stream.RateLimiter) SettingItemHook {
+	return func(item *model.SettingItem) error {
+		var limit int64
+		if item.Value != "" {
+			var err error
+			if limit, err = strconv.ParseInt(item.Value, 10, 64); err != nil {
+				return errors.Wrapf(err, "invalid %s", item.Key)
+			}
+		}
+		l.SetRate(limit * 1024)
+		return nil
+	}
+}
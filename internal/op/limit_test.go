@@ -0,0 +1,32 @@
+package op
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimiters(t *testing.T) {
+	ls := newRateLimiters()
+	if l := ls.get(1, 0); l != nil {
+		t.Fatalf("expect no limiter if unlimited")
+	}
+	l := ls.get(1, 1024).(*rateLimiter)
+	if ls.get(1, 1024) != l {
+		t.Errorf("expect the transfers of the same id to share the limiter")
+	}
+	ls.get(1, 2048)
+	if l.Limit() != rate.Limit(2048*1024) {
+		t.Errorf("expect the rate to follow the limit, got %v", l.Limit())
+	}
+	// a wait larger than the burst is done in pieces
+	start := time.Now()
+	if err := l.WaitN(context.Background(), 2048*1024+512*1024); err != nil {
+		t.Fatalf("failed wait: %+v", err)
+	}
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("expect to wait about 250ms, got %v", d)
+	}
+}
@@ -0,0 +1,79 @@
+package op
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	s3AccessKeyIdLength = 20
+	s3SecretLength      = 40
+)
+
+func GetS3AccessKeysByUser(userID uint) ([]model.S3AccessKey, error) {
+	return db.GetS3AccessKeysByUser(userID)
+}
+
+func GetS3AccessKeyById(id uint) (*model.S3AccessKey, error) {
+	return db.GetS3AccessKeyById(id)
+}
+
+func GetS3AccessKeyByAccessKeyId(accessKeyID string) (*model.S3AccessKey, error) {
+	return db.GetS3AccessKeyByAccessKeyId(accessKeyID)
+}
+
+// HasS3AccessKeys reports whether any user has an access key,
+// the s3 server requires the requests to be signed once a key exists
+func HasS3AccessKeys() (bool, error) {
+	count, err := db.CountS3AccessKeys()
+	return count > 0, err
+}
+
+// CreateS3AccessKey generates a new key pair for the user
+func CreateS3AccessKey(user *model.User, readOnly bool, remark string) (*model.S3AccessKey, error) {
+	key := &model.S3AccessKey{
+		UserID:      user.ID,
+		AccessKeyID: random.String(s3AccessKeyIdLength),
+		Secret:      random.String(s3SecretLength),
+		ReadOnly:    readOnly,
+		Remark:      remark,
+		Created:     time.Now(),
+	}
+	if err := db.CreateS3AccessKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func DeleteS3AccessKeyById(id uint) error {
+	return db.DeleteS3AccessKeyById(id)
+}
+
+func GetS3ObjectMeta(path string) (map[string]string, error) {
+	return db.GetS3ObjectMeta(utils.FixAndCleanPath(path))
+}
+
+func SaveS3ObjectMeta(path string, meta map[string]string) error {
+	return db.SaveS3ObjectMeta(&model.S3ObjectMeta{Path: utils.FixAndCleanPath(path), Meta: meta})
+}
+
+// moveS3ObjectMetas keeps the metadata with the obj moved or renamed in the storage
+func moveS3ObjectMetas(storage driver.Driver, srcPath, dstPath string) {
+	mountPath := storage.GetStorage().MountPath
+	if err := db.MoveS3ObjectMetas(stdpath.Join(mountPath, srcPath), stdpath.Join(mountPath, dstPath)); err != nil {
+		log.Errorf("failed move s3 object metas of [%s]: %+v", srcPath, err)
+	}
+}
+
+func removeS3ObjectMetas(storage driver.Driver, path string) {
+	if err := db.DeleteS3ObjectMetas(stdpath.Join(storage.GetStorage().MountPath, path)); err != nil {
+		log.Errorf("failed remove s3 object metas of [%s]: %+v", path, err)
+	}
+}
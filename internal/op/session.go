@@ -0,0 +1,155 @@
+package op
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	refreshSecretLength = 32 // bytes
+	// the last seen time is saved at most once a minute
+	sessionTouchInterval = time.Minute
+)
+
+// the sessions are checked by every request, they are removed from the cache once revoked
+var sessionCache = cache.NewMemCache(cache.WithShards[*model.Session](16))
+
+func sessionLifetime() time.Duration {
+	return time.Duration(conf.Conf.TokenExpiresIn) * time.Hour
+}
+
+func newRefreshSecret() (string, error) {
+	secret := make([]byte, refreshSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func hashRefreshSecret(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+// CreateSession creates a session of the login, the refresh token is only returned here
+func CreateSession(user *model.User, userAgent, ip string) (*model.Session, string, error) {
+	if err := db.DeleteExpiredSessions(); err != nil {
+		log.Warnf("failed delete expired sessions: %+v", err)
+	}
+	secret, err := newRefreshSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	s := &model.Session{
+		ID:          uuid.NewString(),
+		UserID:      user.ID,
+		UserAgent:   userAgent,
+		IP:          ip,
+		RefreshHash: hashRefreshSecret(secret),
+		Created:     now,
+		LastSeen:    now,
+		Expires:     now.Add(sessionLifetime()),
+	}
+	if err = db.CreateSession(s); err != nil {
+		return nil, "", err
+	}
+	return s, s.ID + "." + secret, nil
+}
+
+// GetSession returns the session which isn't expired or revoked
+func GetSession(id string) (*model.Session, error) {
+	if id == "" {
+		return nil, errors.WithStack(errs.SessionRevoked)
+	}
+	s, ok := sessionCache.Get(id)
+	if !ok {
+		var err error
+		s, err = db.GetSessionById(id)
+		if err != nil {
+			return nil, errors.WithStack(errs.SessionRevoked)
+		}
+		sessionCache.Set(id, s, cache.WithEx[*model.Session](time.Hour))
+	}
+	if s.Expired() {
+		return nil, errors.WithStack(errs.SessionRevoked)
+	}
+	return s, nil
+}
+
+// TouchSession records the last seen time of the session, the session may be shared
+// by the requests through the cache, so it's not changed but reloaded by the next request
+func TouchSession(s *model.Session) {
+	now := time.Now()
+	if now.Sub(s.LastSeen) < sessionTouchInterval {
+		return
+	}
+	if err := db.UpdateSessionLastSeen(s.ID, now); err != nil {
+		log.Warnf("failed update last seen time of session %s: %+v", s.ID, err)
+	}
+	// setting a copy to the cache may bring back the session revoked meanwhile
+	sessionCache.Del(s.ID)
+}
+
+// RefreshSession rotates the refresh token and extends the session,
+// the old refresh token can't be used again
+func RefreshSession(refreshToken, ip string) (*model.Session, string, error) {
+	id, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return nil, "", errors.WithStack(errs.SessionRevoked)
+	}
+	s, err := GetSession(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashRefreshSecret(secret)), []byte(s.RefreshHash)) != 1 {
+		return nil, "", errors.WithStack(errs.SessionRevoked)
+	}
+	newSecret, err := newRefreshSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	updated := *s
+	updated.RefreshHash = hashRefreshSecret(newSecret)
+	updated.LastSeen = now
+	updated.IP = ip
+	updated.Expires = now.Add(sessionLifetime())
+	// refused if the refresh token is used by another request at the same time
+	if err = db.RefreshSession(&updated, s.RefreshHash); err != nil {
+		return nil, "", err
+	}
+	sessionCache.Del(id)
+	return &updated, id + "." + newSecret, nil
+}
+
+func GetSessionsByUser(userID uint) ([]model.Session, error) {
+	return db.GetSessionsByUser(userID)
+}
+
+func DeleteSessionById(id string) error {
+	sessionCache.Del(id)
+	return db.DeleteSessionById(id)
+}
+
+// DeleteSessionsByUser logs out the user everywhere
+func DeleteSessionsByUser(userID uint) error {
+	ids, err := db.DeleteSessionsByUser(userID)
+	for _, id := range ids {
+		sessionCache.Del(id)
+	}
+	return err
+}
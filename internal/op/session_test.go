@@ -0,0 +1,66 @@
+package op_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestSession(t *testing.T) {
+	user := &model.User{ID: 400, Username: "session"}
+	s, refreshToken, err := op.CreateSession(user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("failed create session: %+v", err)
+	}
+	if _, err = op.GetSession(s.ID); err != nil {
+		t.Fatalf("failed get session: %+v", err)
+	}
+	refreshed, newRefreshToken, err := op.RefreshSession(refreshToken, "127.0.0.2")
+	if err != nil {
+		t.Fatalf("failed refresh session: %+v", err)
+	}
+	if refreshed.ID != s.ID || refreshed.IP != "127.0.0.2" || newRefreshToken == refreshToken {
+		t.Errorf("expect the refresh token of the same session to be rotated, got %+v", refreshed)
+	}
+	if _, _, err = op.RefreshSession(refreshToken, "127.0.0.1"); err == nil {
+		t.Errorf("expect the old refresh token to be refused")
+	}
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := op.RefreshSession(newRefreshToken, "127.0.0.3"); err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := succeeded.Load(); n != 1 {
+		t.Errorf("expect the refresh token to be used only once by the concurrent requests, got %d", n)
+	}
+	other, _, err := op.CreateSession(user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("failed create session: %+v", err)
+	}
+	sessions, err := op.GetSessionsByUser(user.ID)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("expect 2 sessions, got %d, %+v", len(sessions), err)
+	}
+	if err = op.DeleteSessionById(s.ID); err != nil {
+		t.Fatalf("failed revoke session: %+v", err)
+	}
+	if _, err = op.GetSession(s.ID); err == nil {
+		t.Errorf("expect the revoked session to be refused")
+	}
+	if err = op.DeleteSessionsByUser(user.ID); err != nil {
+		t.Fatalf("failed logout user: %+v", err)
+	}
+	if _, err = op.GetSession(other.ID); err == nil {
+		t.Errorf("expect the sessions of the logged out user to be refused")
+	}
+}
@@ -0,0 +1,76 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+const shareIdLength = 8
+
+func GetShares() ([]model.Share, error) {
+	return db.GetShares()
+}
+
+func GetSharesByCreator(creatorID uint) ([]model.Share, error) {
+	return db.GetSharesByCreator(creatorID)
+}
+
+func GetShareById(id string) (*model.Share, error) {
+	return db.GetShareById(id)
+}
+
+// GetShare returns the share only if it's not expired,
+// the download limit is checked when downloading so that the folder can be still listed
+func GetShare(id string) (*model.Share, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Expired() {
+		return nil, errors.WithStack(errs.ShareExpired)
+	}
+	return s, nil
+}
+
+// CreateShare creates a share of the path for the user with a new random id,
+// the permissions of the user should be checked by the caller
+func CreateShare(user *model.User, s *model.Share) error {
+	s.Path = utils.FixAndCleanPath(s.Path)
+	if s.Expires != nil && s.Expires.Before(time.Now()) {
+		return errors.New("the expiry time is in the past")
+	}
+	if s.MaxDownloads < 0 {
+		return errors.New("max downloads can't be negative")
+	}
+	s.CreatorID, s.Creator = user.ID, user.Username
+	s.Views, s.Downloads, s.LastAccess = 0, 0, nil
+	s.Created = time.Now()
+	// retry in case the random id is taken
+	var err error
+	for i := 0; i < 3; i++ {
+		s.ID = random.String(shareIdLength)
+		if err = db.CreateShare(s); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func DeleteShareById(id string) error {
+	return db.DeleteShareById(id)
+}
+
+func AddShareView(id string) error {
+	return db.AddShareView(id)
+}
+
+// AddShareDownload fails with errs.ShareExhausted if the share reaches the download limit
+func AddShareDownload(id string) error {
+	return db.AddShareDownload(id)
+}
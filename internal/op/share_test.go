@@ -0,0 +1,44 @@
+package op_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestShareDownloadLimit(t *testing.T) {
+	user := &model.User{ID: 300, Username: "share"}
+	s := model.Share{Path: "/a", MaxDownloads: 2}
+	if err := op.CreateShare(user, &s); err != nil {
+		t.Fatalf("failed create share: %+v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := op.AddShareDownload(s.ID); err != nil {
+			t.Fatalf("expect download %d to be allowed, got %+v", i, err)
+		}
+	}
+	if err := op.AddShareDownload(s.ID); !errors.Is(err, errs.ShareExhausted) {
+		t.Errorf("expect the share to be exhausted, got %+v", err)
+	}
+	got, err := op.GetShare(s.ID)
+	if err != nil {
+		t.Fatalf("failed get share: %+v", err)
+	}
+	if got.Downloads != 2 || !got.Exhausted() {
+		t.Errorf("expect 2 downloads, got %d", got.Downloads)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err = op.CreateShare(user, &model.Share{Path: "/a", Expires: &past}); err == nil {
+		t.Errorf("expect an expired share can't be created")
+	}
+	if err = op.DeleteShareById(s.ID); err != nil {
+		t.Fatalf("failed delete share: %+v", err)
+	}
+	if _, err = op.GetShare(s.ID); err == nil {
+		t.Errorf("expect the revoked share to be gone")
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OpenListTeam/go-cache"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/pkg/errors"
@@ -41,6 +44,63 @@ func GetStorageByMountPath(mountPath string) (driver.Driver, error) {
 	return storageDriver, nil
 }
 
+// the space usage changes slowly, so it's cached for a while to save the api calls
+const detailsCacheExpiration = 10 * time.Minute
+
+var detailsCache = cache.NewMemCache(cache.WithShards[*model.StorageDetails](16))
+var detailsG singleflight.Group[*model.StorageDetails]
+
+// detailsFetched is when the details are fetched in the background for the last time,
+// so that a failing storage isn't requested again and again
+var detailsFetched generic_sync.MapOf[string, time.Time]
+
+// GetStorageDetails returns the space usage of the storage if the driver supports it
+func GetStorageDetails(ctx context.Context, storage driver.Driver, refresh ...bool) (*model.StorageDetails, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.WithDetails)
+	if !ok {
+		return nil, errs.NotImplement
+	}
+	key := storage.GetStorage().MountPath
+	if !utils.IsBool(refresh...) {
+		if details, ok := detailsCache.Get(key); ok {
+			return details, nil
+		}
+	}
+	details, err, _ := detailsG.Do(key, func() (*model.StorageDetails, error) {
+		details, err := s.GetDetails(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed get storage details")
+		}
+		detailsCache.Set(key, details, cache.WithEx[*model.StorageDetails](detailsCacheExpiration))
+		return details, nil
+	})
+	return details, err
+}
+
+// isStorageFull reports whether the storage is known to be full by the cached details,
+// the details are fetched in the background if not cached, so the routing is never blocked
+func isStorageFull(storage driver.Driver) bool {
+	if _, ok := storage.(driver.WithDetails); !ok {
+		return false
+	}
+	key := storage.GetStorage().MountPath
+	if details, ok := detailsCache.Get(key); ok {
+		return details.Full()
+	}
+	if last, ok := detailsFetched.Load(key); !ok || time.Since(last) > detailsCacheExpiration {
+		detailsFetched.Store(key, time.Now())
+		go func() {
+			if _, err := GetStorageDetails(context.Background(), storage); err != nil && !errs.IsNotImplement(err) {
+				log.Warnf("failed get details of storage [%s]: %+v", key, err)
+			}
+		}()
+	}
+	return false
+}
+
 // CreateStorage Save the storage to database so storage can get an id
 // then instantiate corresponding driver and save it in memory
 func CreateStorage(ctx context.Context, storage model.Storage) (uint, error) {
@@ -149,13 +209,18 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		err = storageDriver.Init(ctx)
 	}
 	storagesMap.Store(driverStorage.MountPath, storageDriver)
+	detailsCache.Del(driverStorage.MountPath)
+	changeCursors.Delete(driverStorage.MountPath)
 	if err != nil {
 		driverStorage.SetStatus(err.Error())
 		err = errors.Wrap(err, "failed init storage")
+		go callStorageErrorHooks("init", storageDriver)
 	} else {
 		driverStorage.SetStatus(WORK)
 	}
-	MustSaveDriverStorage(storageDriver)
+	if err := saveDriverStorage(storageDriver); err != nil {
+		log.Errorf("failed save driver storage: %s", err)
+	}
 	return err
 }
 
@@ -396,6 +461,10 @@ func MustSaveDriverStorage(driver driver.Driver) {
 	if err != nil {
 		log.Errorf("failed save driver storage: %s", err)
 	}
+	// drivers save the storage with the error as status when they stop working, e.g. failed refresh token
+	if status := driver.GetStorage().Status; status != WORK && status != DISABLED {
+		go callStorageErrorHooks("status", driver)
+	}
 }
 
 func saveDriverStorage(driver driver.Driver) error {
@@ -491,7 +560,13 @@ func GetBalancedStorage(path string) driver.Driver {
 	default:
 		virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
 		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
-		i = (i + 1) % storageNum
+		// skip the storages known to be full, unless all of them are
+		for n := 0; n < storageNum; n++ {
+			i = (i + 1) % storageNum
+			if !isStorageFull(storages[i]) {
+				break
+			}
+		}
 		balanceMap.Store(virtualPath, i)
 		return storages[i]
 	}
@@ -0,0 +1,82 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetUserUsage(userID uint) (*model.UserUsage, error) {
+	return db.GetUserUsage(userID)
+}
+
+// CheckQuota checks whether the user in ctx can write size bytes and files more,
+// writing without a user in ctx, e.g. by the server itself, is not limited
+func CheckQuota(ctx context.Context, size, files int64) error {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil || !user.HasQuota() {
+		return nil
+	}
+	usage, err := GetUserUsage(user.ID)
+	if err != nil {
+		return err
+	}
+	if user.QuotaSize > 0 && size > 0 && usage.UsedSize+size > user.QuotaSize {
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	if user.QuotaFiles > 0 && files > 0 && usage.UsedFiles+files > user.QuotaFiles {
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	return nil
+}
+
+// ResetUserFiles accounts the files to the user only, the usage of the user is reset to them
+func ResetUserFiles(userID uint, files []model.FileOwner) error {
+	return db.ResetUserFiles(userID, files)
+}
+
+// ownedBy reports whether the file is accounted to the user in ctx
+func ownedBy(ctx context.Context, storage driver.Driver, path string) bool {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil {
+		return false
+	}
+	owner, err := db.GetFileOwner(stdpath.Join(storage.GetStorage().MountPath, path))
+	if err != nil {
+		log.Errorf("failed get owner of [%s]: %+v", path, err)
+		return false
+	}
+	return owner != nil && owner.UserID == user.ID
+}
+
+// setFileOwner accounts the written file to the user in ctx instead of its previous owner,
+// the files written by the server itself are accounted to nobody
+func setFileOwner(ctx context.Context, storage driver.Driver, path string, size int64) {
+	var userID uint
+	if user, _ := ctx.Value("user").(*model.User); user != nil {
+		userID = user.ID
+	}
+	if err := db.SetFileOwner(stdpath.Join(storage.GetStorage().MountPath, path), userID, size); err != nil {
+		log.Errorf("failed update usage of [%s]: %+v", path, err)
+	}
+}
+
+// removeFileOwners gives the usage of the removed files back to their owners, whoever removes them
+func removeFileOwners(storage driver.Driver, path string) {
+	if err := db.DeleteFileOwners(stdpath.Join(storage.GetStorage().MountPath, path)); err != nil {
+		log.Errorf("failed update usage of [%s]: %+v", path, err)
+	}
+}
+
+func moveFileOwners(storage driver.Driver, srcPath, dstPath string) {
+	mountPath := storage.GetStorage().MountPath
+	if err := db.MoveFileOwners(stdpath.Join(mountPath, srcPath), stdpath.Join(mountPath, dstPath)); err != nil {
+		log.Errorf("failed move owners of [%s]: %+v", srcPath, err)
+	}
+}
@@ -0,0 +1,103 @@
+package op_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+func TestCheckQuota(t *testing.T) {
+	user := &model.User{ID: 100, Username: "quota", QuotaSize: 100, QuotaFiles: 2}
+	ctx := context.WithValue(context.Background(), "user", user)
+	if err := op.CheckQuota(ctx, 100, 1); err != nil {
+		t.Fatalf("expect no error, got %+v", err)
+	}
+	if err := db.AddUserUsage(user.ID, 60, 1); err != nil {
+		t.Fatalf("failed add usage: %+v", err)
+	}
+	if err := op.CheckQuota(ctx, 50, 1); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expect quota exceeded by size, got %+v", err)
+	}
+	if err := op.CheckQuota(ctx, 40, 1); err != nil {
+		t.Errorf("expect no error, got %+v", err)
+	}
+	if err := db.AddUserUsage(user.ID, 0, 1); err != nil {
+		t.Fatalf("failed add usage: %+v", err)
+	}
+	if err := op.CheckQuota(ctx, 1, 1); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expect quota exceeded by files, got %+v", err)
+	}
+	// overwriting a larger file only shrinks the usage
+	if err := op.CheckQuota(ctx, -10, 0); err != nil {
+		t.Errorf("expect no error, got %+v", err)
+	}
+	// the usage never goes negative
+	if err := db.AddUserUsage(user.ID, -1000, -10); err != nil {
+		t.Fatalf("failed add usage: %+v", err)
+	}
+	usage, err := op.GetUserUsage(user.ID)
+	if err != nil {
+		t.Fatalf("failed get usage: %+v", err)
+	}
+	if usage.UsedSize != 0 || usage.UsedFiles != 0 {
+		t.Errorf("expect empty usage, got %+v", usage)
+	}
+	// no limit without user
+	if err := op.CheckQuota(context.Background(), 1000, 1); err != nil {
+		t.Errorf("expect no error, got %+v", err)
+	}
+}
+
+func TestRemoveDirUsage(t *testing.T) {
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/usage",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, t.TempDir()),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/usage")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	owner := &model.User{ID: 101, Username: "owner"}
+	ownerCtx := context.WithValue(ctx, "user", owner)
+	for name, content := range map[string]string{"a.txt": "hello", "b.txt": "foo"} {
+		err = op.Put(ownerCtx, storage, "/dir", &stream.FileStream{
+			Obj:    &model.Object{Name: name, Size: int64(len(content))},
+			Reader: strings.NewReader(content),
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed put %s: %+v", name, err)
+		}
+	}
+	usage, err := op.GetUserUsage(owner.ID)
+	if err != nil {
+		t.Fatalf("failed get usage: %+v", err)
+	}
+	if usage.UsedSize != 8 || usage.UsedFiles != 2 {
+		t.Fatalf("expect 8 bytes in 2 files, got %+v", usage)
+	}
+	// the usage goes back to the owner, not to whoever removes the dir
+	remover := &model.User{ID: 102, Username: "remover"}
+	if err = op.Remove(context.WithValue(ctx, "user", remover), storage, "/dir"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	usage, err = op.GetUserUsage(owner.ID)
+	if err != nil {
+		t.Fatalf("failed get usage: %+v", err)
+	}
+	if usage.UsedSize != 0 || usage.UsedFiles != 0 {
+		t.Errorf("expect empty usage, got %+v", usage)
+	}
+}
@@ -82,6 +82,9 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err = DeleteSessionsByUser(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -0,0 +1,65 @@
+package op
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckWebdavLock returns errs.Locked if the path is locked by a WebDAV client,
+// unless the lock token is given. The locks on the children are checked too if tree is true,
+// which is the case when a folder is moved or removed.
+func CheckWebdavLock(path string, tree bool, tokens ...string) error {
+	locks, err := db.GetWebdavLocksOnPath(path, tree, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, l := range locks {
+		if !utils.SliceContains(tokens, l.Token) {
+			return errors.Wrapf(errs.Locked, "%s is locked", l.Root)
+		}
+	}
+	return nil
+}
+
+func GetWebdavProps(path string) ([]model.WebdavProp, error) {
+	return db.GetWebdavProps(utils.FixAndCleanPath(path))
+}
+
+// GetWebdavPropsUnder returns the dead properties of the path and its sub paths by path
+func GetWebdavPropsUnder(path string) (map[string][]model.WebdavProp, error) {
+	props, err := db.GetWebdavPropsUnder(utils.FixAndCleanPath(path))
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]model.WebdavProp, len(props))
+	for _, p := range props {
+		res[p.Path] = p.Props
+	}
+	return res, nil
+}
+
+func SaveWebdavProps(path string, props []model.WebdavProp) error {
+	return db.SaveWebdavProps(&model.WebdavProps{Path: utils.FixAndCleanPath(path), Props: props})
+}
+
+// moveWebdavProps keeps the dead properties with the obj moved or renamed in the storage
+func moveWebdavProps(storage driver.Driver, srcPath, dstPath string) {
+	mountPath := storage.GetStorage().MountPath
+	if err := db.MoveWebdavProps(stdpath.Join(mountPath, srcPath), stdpath.Join(mountPath, dstPath)); err != nil {
+		log.Errorf("failed move webdav props of [%s]: %+v", srcPath, err)
+	}
+}
+
+func removeWebdavProps(storage driver.Driver, path string) {
+	if err := db.DeleteWebdavProps(stdpath.Join(storage.GetStorage().MountPath, path)); err != nil {
+		log.Errorf("failed remove webdav props of [%s]: %+v", path, err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package ratelimit limits the requests of each user or client ip by the budgets of the routes
+package ratelimit
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"golang.org/x/time/rate"
+)
+
+// the keys idle for so long are forgotten, their buckets are full again anyway
+const idleExpiration = 10 * time.Minute
+
+// the budgets of the routes, they are set by Init
+var (
+	API      *Limiter
+	Download *Limiter
+	WebDAV   *Limiter
+	S3       *Limiter
+)
+
+// Limiter limits the requests of each key with a token bucket and the concurrent requests
+type Limiter struct {
+	name   string
+	config conf.RateLimit
+	mu     sync.Mutex
+	keys   map[string]*bucket
+}
+
+type bucket struct {
+	tokens        *rate.Limiter // nil if the rate is unlimited
+	inflight      int
+	lastSeen      time.Time
+	throttled     int64
+	lastThrottled time.Time
+}
+
+// Stat is the counters of a throttled key
+type Stat struct {
+	Budget        string    `json:"budget"`
+	Key           string    `json:"key"`
+	Inflight      int       `json:"inflight"`
+	Throttled     int64     `json:"throttled"`
+	LastThrottled time.Time `json:"last_throttled"`
+}
+
+func New(name string, config conf.RateLimit) *Limiter {
+	return &Limiter{name: name, config: config, keys: make(map[string]*bucket)}
+}
+
+// UserKey is the key of the requests authenticated as the user
+func UserKey(userID uint) string {
+	return "user:" + strconv.Itoa(int(userID))
+}
+
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+func (l *Limiter) enabled() bool {
+	return l != nil && (l.config.Rate > 0 || l.config.Concurrency > 0)
+}
+
+func (l *Limiter) bucket(key string) *bucket {
+	b, ok := l.keys[key]
+	if !ok {
+		b = &bucket{}
+		if l.config.Rate > 0 {
+			burst := l.config.Burst
+			if burst <= 0 {
+				burst = int(math.Ceil(l.config.Rate))
+			}
+			b.tokens = rate.NewLimiter(rate.Limit(l.config.Rate), burst)
+		}
+		l.keys[key] = b
+	}
+	return b
+}
+
+// Acquire takes a request of the key, release must be called once the request is done if ok,
+// otherwise retryAfter is how long to wait before the next request
+func (l *Limiter) Acquire(key string) (release func(), retryAfter time.Duration, ok bool) {
+	if !l.enabled() {
+		return func() {}, 0, true
+	}
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key)
+	b.lastSeen = now
+	if l.config.Concurrency > 0 && b.inflight >= l.config.Concurrency {
+		b.throttled++
+		b.lastThrottled = now
+		// no way to know when a request is done
+		return nil, time.Second, false
+	}
+	if b.tokens != nil {
+		r := b.tokens.ReserveN(now, 1)
+		if d := r.DelayFrom(now); !r.OK() || d > 0 {
+			r.CancelAt(now)
+			b.throttled++
+			b.lastThrottled = now
+			return nil, d, false
+		}
+	}
+	b.inflight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			b.inflight--
+			b.lastSeen = time.Now()
+		})
+	}, 0, true
+}
+
+func (l *Limiter) stats() []Stat {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var stats []Stat
+	for key, b := range l.keys {
+		if b.throttled == 0 {
+			continue
+		}
+		stats = append(stats, Stat{
+			Budget:        l.name,
+			Key:           key,
+			Inflight:      b.inflight,
+			Throttled:     b.throttled,
+			LastThrottled: b.lastThrottled,
+		})
+	}
+	return stats
+}
+
+func (l *Limiter) purgeIdle() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, b := range l.keys {
+		if b.inflight == 0 && time.Since(b.lastSeen) > idleExpiration {
+			delete(l.keys, key)
+		}
+	}
+}
+
+func all() []*Limiter {
+	return []*Limiter{API, Download, WebDAV, S3}
+}
+
+// Stats returns the keys throttled recently, the latest first
+func Stats() []Stat {
+	stats := make([]Stat, 0)
+	for _, l := range all() {
+		stats = append(stats, l.stats()...)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].LastThrottled.After(stats[j].LastThrottled)
+	})
+	return stats
+}
+
+// Init sets the budgets by the config, the idle keys are purged every minute
+func Init() {
+	API = New("api", conf.Conf.RateLimits.API)
+	Download = New("download", conf.Conf.RateLimits.Download)
+	WebDAV = New("webdav", conf.Conf.RateLimits.WebDAV)
+	S3 = New("s3", conf.Conf.RateLimits.S3)
+	cron.NewCron(time.Minute).Do(func() {
+		for _, l := range all() {
+			l.purgeIdle()
+		}
+	})
+}
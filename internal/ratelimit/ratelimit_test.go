@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+)
+
+func TestAcquire(t *testing.T) {
+	l := New("test", conf.RateLimit{Rate: 1, Burst: 2, Concurrency: 1})
+	release, _, ok := l.Acquire("ip:1")
+	if !ok {
+		t.Fatalf("expect the first request to be allowed")
+	}
+	if _, _, ok = l.Acquire("ip:1"); ok {
+		t.Errorf("expect the concurrent request to be throttled")
+	}
+	if _, _, ok = l.Acquire("ip:2"); !ok {
+		t.Errorf("expect the request of another key to be allowed")
+	}
+	release()
+	release()
+	release, _, ok = l.Acquire("ip:1")
+	if !ok {
+		t.Fatalf("expect the request to be allowed in the burst")
+	}
+	release()
+	_, retryAfter, ok := l.Acquire("ip:1")
+	if ok || retryAfter <= 0 {
+		t.Errorf("expect the request out of the rate to be throttled with the time to retry, got %v", retryAfter)
+	}
+	stats := l.stats()
+	if len(stats) != 1 || stats[0].Key != "ip:1" || stats[0].Throttled != 2 {
+		t.Errorf("expect ip:1 to be throttled twice, got %+v", stats)
+	}
+}
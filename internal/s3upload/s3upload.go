@@ -0,0 +1,323 @@
+// Package s3upload stages the multipart uploads of the s3 server, so that the large objs are not kept in memory
+package s3upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// DirName is the dir under the temp dir where the parts are staged,
+// it's kept when cleaning the temp dir on start so that the uploads survive a restart
+const DirName = "s3_multipart"
+
+// Expiration is how long an upload is kept after it receives the last part
+const Expiration = 24 * time.Hour
+
+// the uploads being completed, they can't be completed or aborted twice
+var completing sync.Map
+
+func dir() string {
+	return filepath.Join(conf.Conf.TempDir, DirName)
+}
+
+func uploadDir(id string) string {
+	return filepath.Join(dir(), id)
+}
+
+func partPath(id string, number int) string {
+	return filepath.Join(uploadDir(id), strconv.Itoa(number))
+}
+
+// Init removes the abandoned uploads periodically
+func Init() {
+	if err := os.MkdirAll(dir(), 0o777); err != nil {
+		log.Errorf("failed create s3 multipart dir: %+v", err)
+	}
+	clean()
+	cron.NewCron(time.Hour).Do(clean)
+}
+
+func clean() {
+	uploads, err := db.GetIdleS3Uploads(time.Now().Add(-Expiration))
+	if err != nil {
+		log.Errorf("failed get idle s3 uploads: %+v", err)
+		return
+	}
+	for _, u := range uploads {
+		if err = Abort(u.ID); err != nil && !errors.Is(err, errs.UploadInProgress) {
+			log.Errorf("failed remove abandoned s3 upload [%s]: %+v", u.Path, err)
+		}
+	}
+	// the staged parts without an upload are left by a crash
+	uploads, err = db.GetS3Uploads()
+	if err != nil {
+		log.Errorf("failed get s3 uploads: %+v", err)
+		return
+	}
+	ids := make(map[string]struct{}, len(uploads))
+	for _, u := range uploads {
+		// the staged parts are lost, e.g. the temp dir is cleaned by hand, the client has to start over
+		if lost(u.ID) {
+			if err = Abort(u.ID); err != nil && !errors.Is(err, errs.UploadInProgress) {
+				log.Errorf("failed remove s3 upload [%s] without staged parts: %+v", u.Path, err)
+			}
+			continue
+		}
+		ids[u.ID] = struct{}{}
+	}
+	entries, _ := os.ReadDir(dir())
+	for _, e := range entries {
+		if _, ok := ids[e.Name()]; !ok {
+			_ = os.RemoveAll(uploadDir(e.Name()))
+		}
+	}
+}
+
+// lost reports whether the dir or a part file of the upload is missing
+func lost(id string) bool {
+	if !utils.Exists(uploadDir(id)) {
+		return true
+	}
+	parts, err := db.GetS3UploadParts(id)
+	if err != nil {
+		return false
+	}
+	for _, p := range parts {
+		if !utils.Exists(partPath(id, p.PartNumber)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Create starts an upload of the obj to the path for the user in ctx,
+// the permissions should be checked by the caller
+func Create(ctx context.Context, bucket, key, path string, meta map[string]string) (*model.S3Upload, error) {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil {
+		return nil, errors.New("user is required")
+	}
+	if err := op.CheckQuota(ctx, 0, 1); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	u := &model.S3Upload{
+		ID:        random.String(32),
+		UserID:    user.ID,
+		Bucket:    bucket,
+		ObjectKey: key,
+		Path:      utils.FixAndCleanPath(path),
+		Meta:      meta,
+		Created:   now,
+		Updated:   now,
+	}
+	if err := os.MkdirAll(uploadDir(u.ID), 0o777); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := db.CreateS3Upload(u); err != nil {
+		_ = os.RemoveAll(uploadDir(u.ID))
+		return nil, err
+	}
+	return u, nil
+}
+
+func Get(id string) (*model.S3Upload, error) {
+	return db.GetS3UploadById(id)
+}
+
+func GetUploads(userID uint, bucket string) ([]model.S3Upload, error) {
+	return db.GetS3UploadsByUser(userID, bucket)
+}
+
+func GetParts(id string) ([]model.S3UploadPart, error) {
+	return db.GetS3UploadParts(id)
+}
+
+// WritePart stages the part of size bytes for the user in ctx, a part with the same number is replaced,
+// contentMD5 is the md5 of the part if the client provides it
+func WritePart(ctx context.Context, u *model.S3Upload, number int, r io.Reader, size int64, contentMD5 []byte) (*model.S3UploadPart, error) {
+	// the parts staged so far are written as one obj, so the quota is checked against all of them
+	staged, err := db.GetS3UploadParts(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	total := size
+	for _, p := range staged {
+		if p.PartNumber != number {
+			total += p.Size
+		}
+	}
+	if err = op.CheckQuota(ctx, total, 1); err != nil {
+		return nil, err
+	}
+	// written to a temp file first so that a part being replaced is always complete
+	f, err := os.CreateTemp(uploadDir(u.ID), "part_*")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	h := md5.New()
+	n, err := utils.CopyWithBuffer(io.MultiWriter(f, h), io.LimitReader(r, size))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if n != size {
+		return nil, errors.WithStack(errs.S3IncompleteBody)
+	}
+	sum := h.Sum(nil)
+	if contentMD5 != nil && !bytes.Equal(sum, contentMD5) {
+		return nil, errors.WithStack(errs.S3BadDigest)
+	}
+	if err = f.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = os.Rename(f.Name(), partPath(u.ID, number)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	p := &model.S3UploadPart{
+		UploadID:   u.ID,
+		PartNumber: number,
+		Size:       size,
+		ETag:       hex.EncodeToString(sum),
+		Updated:    time.Now(),
+	}
+	return p, db.SaveS3UploadPart(p)
+}
+
+// CompletedPart is a part listed by the client to complete the upload
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// Complete joins the parts in the order and hands the obj to an upload task as the user in ctx,
+// the etag of the obj is calculated in the same way as s3
+func Complete(ctx context.Context, u *model.S3Upload, completed []CompletedPart) (string, task.TaskExtensionInfo, error) {
+	if _, loaded := completing.LoadOrStore(u.ID, struct{}{}); loaded {
+		return "", nil, errors.WithStack(errs.UploadInProgress)
+	}
+	defer completing.Delete(u.ID)
+	staged, err := db.GetS3UploadParts(u.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	parts := make(map[int]model.S3UploadPart, len(staged))
+	for _, p := range staged {
+		parts[p.PartNumber] = p
+	}
+	if len(completed) == 0 {
+		return "", nil, errors.WithStack(errs.S3InvalidPart)
+	}
+	for i, c := range completed {
+		if i > 0 && c.PartNumber <= completed[i-1].PartNumber {
+			return "", nil, errors.WithStack(errs.S3InvalidPartOrder)
+		}
+		p, ok := parts[c.PartNumber]
+		if !ok || strings.Trim(c.ETag, `"`) != p.ETag {
+			return "", nil, errors.WithStack(errs.S3InvalidPart)
+		}
+	}
+	f, err := os.CreateTemp(conf.Conf.TempDir, "s3_*")
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	etag, size, err := join(f, u, completed)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", nil, err
+	}
+	dstDir, name := stdpath.Split(u.Path)
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		Mimetype: u.Meta["Content-Type"],
+	}
+	s.SetTmpFile(f)
+	t, err := fs.PutAsTask(ctx, dstDir, s)
+	if err != nil {
+		_ = s.Close()
+		return "", nil, err
+	}
+	if err = op.SaveS3ObjectMeta(u.Path, u.Meta); err != nil {
+		log.Errorf("failed save s3 object meta of [%s]: %+v", u.Path, err)
+	}
+	// the joined file belongs to the task now
+	return etag, t, remove(u.ID)
+}
+
+func join(f *os.File, u *model.S3Upload, completed []CompletedPart) (string, int64, error) {
+	var size int64
+	sums := make([]byte, 0, len(completed)*md5.Size)
+	for _, c := range completed {
+		n, err := appendPart(f, partPath(u.ID, c.PartNumber))
+		if err != nil {
+			return "", 0, err
+		}
+		size += n
+		sum, _ := hex.DecodeString(strings.Trim(c.ETag, `"`))
+		sums = append(sums, sum...)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", 0, errors.WithStack(err)
+	}
+	sum := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(completed)), size, nil
+}
+
+func appendPart(f *os.File, path string) (int64, error) {
+	part, err := os.Open(path)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer part.Close()
+	n, err := utils.CopyWithBuffer(f, part)
+	return n, errors.WithStack(err)
+}
+
+// Abort removes the upload and its staged parts, it fails if the upload is being completed
+func Abort(id string) error {
+	if _, loaded := completing.LoadOrStore(id, struct{}{}); loaded {
+		return errors.WithStack(errs.UploadInProgress)
+	}
+	defer completing.Delete(id)
+	return remove(id)
+}
+
+func remove(id string) error {
+	if err := os.RemoveAll(uploadDir(id)); err != nil {
+		return errors.WithStack(err)
+	}
+	return db.DeleteS3UploadById(id)
+}
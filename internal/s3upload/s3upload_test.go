@@ -0,0 +1,133 @@
+package s3upload
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestParts(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 1})
+	u, err := Create(ctx, "bucket", "b.txt", "/a/b.txt", nil)
+	if err != nil {
+		t.Fatalf("failed create upload: %+v", err)
+	}
+	p2, err := WritePart(ctx, u, 2, strings.NewReader("world"), 5, nil)
+	if err != nil {
+		t.Fatalf("failed write part: %+v", err)
+	}
+	if _, err = WritePart(ctx, u, 1, strings.NewReader("hi"), 2, nil); err != nil {
+		t.Fatalf("failed write part: %+v", err)
+	}
+	// the part with the same number is replaced
+	sum := md5.Sum([]byte("hello"))
+	p1, err := WritePart(ctx, u, 1, strings.NewReader("hello"), 5, sum[:])
+	if err != nil {
+		t.Fatalf("failed replace part: %+v", err)
+	}
+	if _, err = WritePart(ctx, u, 3, strings.NewReader("!"), 2, nil); !errors.Is(err, errs.S3IncompleteBody) {
+		t.Errorf("expect incomplete body, got %+v", err)
+	}
+	if _, err = WritePart(ctx, u, 3, strings.NewReader("!"), 1, sum[:]); !errors.Is(err, errs.S3BadDigest) {
+		t.Errorf("expect bad digest, got %+v", err)
+	}
+	parts, err := GetParts(u.ID)
+	if err != nil || len(parts) != 2 {
+		t.Fatalf("expect 2 parts, got %d: %+v", len(parts), err)
+	}
+	completed := []CompletedPart{{1, `"` + p1.ETag + `"`}, {2, p2.ETag}}
+	if _, _, err = Complete(ctx, u, []CompletedPart{completed[1], completed[0]}); !errors.Is(err, errs.S3InvalidPartOrder) {
+		t.Errorf("expect invalid part order, got %+v", err)
+	}
+	if _, _, err = Complete(ctx, u, []CompletedPart{{1, p2.ETag}}); !errors.Is(err, errs.S3InvalidPart) {
+		t.Errorf("expect invalid part, got %+v", err)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	etag, size, err := join(f, u, completed)
+	if err != nil {
+		t.Fatalf("failed join parts: %+v", err)
+	}
+	data, _ := os.ReadFile(f.Name())
+	if string(data) != "helloworld" || size != 10 || !strings.HasSuffix(etag, "-2") {
+		t.Errorf("expect helloworld with 2 parts, got %s (%d) %s", data, size, etag)
+	}
+	// the upload being completed can't be aborted
+	completing.Store(u.ID, struct{}{})
+	if err = Abort(u.ID); !errors.Is(err, errs.UploadInProgress) {
+		t.Errorf("expect upload in progress, got %+v", err)
+	}
+	completing.Delete(u.ID)
+	if err = Abort(u.ID); err != nil {
+		t.Fatalf("failed abort: %+v", err)
+	}
+	if _, err = os.Stat(uploadDir(u.ID)); !os.IsNotExist(err) {
+		t.Errorf("expect the parts to be removed")
+	}
+	if parts, _ = GetParts(u.ID); len(parts) != 0 {
+		t.Errorf("expect the parts to be deleted with the upload")
+	}
+}
+
+func TestPartsQuota(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 2, QuotaSize: 8})
+	u, err := Create(ctx, "bucket", "c.txt", "/a/c.txt", nil)
+	if err != nil {
+		t.Fatalf("failed create upload: %+v", err)
+	}
+	defer Abort(u.ID)
+	if _, err = WritePart(ctx, u, 1, strings.NewReader("hello"), 5, nil); err != nil {
+		t.Fatalf("failed write part: %+v", err)
+	}
+	// the staged parts count
+	if _, err = WritePart(ctx, u, 2, strings.NewReader("world"), 5, nil); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expect quota exceeded, got %+v", err)
+	}
+	// except the one replaced
+	if _, err = WritePart(ctx, u, 1, strings.NewReader("hello!"), 6, nil); err != nil {
+		t.Errorf("failed replace part: %+v", err)
+	}
+}
+
+func TestPartsLost(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 1})
+	u, err := Create(ctx, "bucket", "lost.txt", "/a/lost.txt", nil)
+	if err != nil {
+		t.Fatalf("failed create upload: %+v", err)
+	}
+	if _, err = WritePart(ctx, u, 1, strings.NewReader("hello"), 5, nil); err != nil {
+		t.Fatalf("failed write part: %+v", err)
+	}
+	if err = os.Remove(partPath(u.ID, 1)); err != nil {
+		t.Fatal(err)
+	}
+	clean()
+	if _, err = Get(u.ID); err == nil {
+		t.Errorf("expect the upload without staged parts to be aborted")
+	}
+}
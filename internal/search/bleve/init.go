@@ -8,7 +8,8 @@ import (
 )
 
 var config = searcher.Config{
-	Name: "bleve",
+	Name:    "bleve",
+	Content: true,
 }
 
 func Init(indexPath *string) (bleve.Index, error) {
@@ -23,8 +24,21 @@ func Init(indexPath *string) (bleve.Index, error) {
 		parentFieldMapping := bleve.NewTextFieldMapping()
 		searchNodeMapping.AddFieldMappingsAt("parent", parentFieldMapping)
 		// TODO: appoint analyzer
-		nameFieldMapping := bleve.NewKeywordFieldMapping()
-		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
+		nameFieldMapping := bleve.NewTextFieldMapping()
+		// the whole name is also indexed for the phrase and regex modes and the sorting
+		rawNameFieldMapping := bleve.NewKeywordFieldMapping()
+		rawNameFieldMapping.Name = "raw_name"
+		rawNameFieldMapping.Store = false
+		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping, rawNameFieldMapping)
+		searchNodeMapping.AddFieldMappingsAt("size", bleve.NewNumericFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("modified", bleve.NewDateTimeFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("obj_type", bleve.NewNumericFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("ext", bleve.NewKeywordFieldMapping())
+		hashInfoFieldMapping := bleve.NewKeywordFieldMapping()
+		hashInfoFieldMapping.Index = false
+		searchNodeMapping.AddFieldMappingsAt("hash_info", hashInfoFieldMapping)
+		// the content is stored with the term vectors to be highlighted
+		searchNodeMapping.AddFieldMappingsAt("content", bleve.NewTextFieldMapping())
 		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
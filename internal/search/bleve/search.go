@@ -3,6 +3,8 @@ package bleve
 import (
 	"context"
 	"os"
+	"strings"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/blevesearch/bleve/v2"
 	search2 "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 )
@@ -25,46 +28,140 @@ func (b *Bleve) Config() searcher.Config {
 	return config
 }
 
+// searchDocument is the indexed document, the ext is indexed to be filtered,
+// it's indexed by pointer so that it's mapped as a SearchNode
+type searchDocument struct {
+	model.SearchNode
+	Ext     string `json:"ext"`
+	Content string `json:"content"`
+}
+
+func toDocument(node model.SearchNode) *searchDocument {
+	return &searchDocument{SearchNode: node, Ext: node.Ext(), Content: node.Content}
+}
+
+// the stored fields except the content
+var nodeFields = []string{"parent", "name", "is_dir", "size", "modified", "obj_type", "hash_info"}
+
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`)
+
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	switch req.Mode {
+	case model.SearchModePhrase:
+		query := bleve.NewWildcardQuery("*" + wildcardEscaper.Replace(req.Keywords) + "*")
+		query.SetField("raw_name")
+		contentQuery := bleve.NewMatchPhraseQuery(req.Keywords)
+		contentQuery.SetField("content")
+		queries = append(queries, bleve.NewDisjunctionQuery(query, contentQuery))
+	case model.SearchModeRegex:
+		// the regexp query matches the whole name
+		query := bleve.NewRegexpQuery(".*(?:" + req.Keywords + ").*")
+		query.SetField("raw_name")
+		queries = append(queries, query)
+	default:
+		query := bleve.NewMatchQuery(req.Keywords)
+		query.SetField("name")
+		contentQuery := bleve.NewMatchQuery(req.Keywords)
+		contentQuery.SetField("content")
+		contentQuery.SetOperator(query2.MatchQueryOperatorAnd)
+		queries = append(queries, bleve.NewDisjunctionQuery(query, contentQuery))
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
+		isDirQuery.SetField("is_dir")
 		queries = append(queries, isDirQuery)
 	}
+	inclusive := true
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		var minSize, maxSize *float64
+		if req.MinSize > 0 {
+			v := float64(req.MinSize)
+			minSize = &v
+		}
+		if req.MaxSize > 0 {
+			v := float64(req.MaxSize)
+			maxSize = &v
+		}
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(minSize, maxSize, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if !req.ModifiedAfter.IsZero() || !req.ModifiedBefore.IsZero() {
+		modifiedQuery := bleve.NewDateRangeInclusiveQuery(req.ModifiedAfter, req.ModifiedBefore, &inclusive, &inclusive)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
+	if len(req.Types) > 0 {
+		var typeQueries []query2.Query
+		for _, t := range req.Types {
+			v := float64(t)
+			typeQuery := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+			typeQuery.SetField("obj_type")
+			typeQueries = append(typeQueries, typeQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(typeQueries...))
+	}
+	if len(req.Exts) > 0 {
+		var extQueries []query2.Query
+		for _, ext := range req.Exts {
+			extQuery := bleve.NewTermQuery(ext)
+			extQuery.SetField("ext")
+			extQueries = append(extQueries, extQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(extQueries...))
+	}
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	order := "raw_name"
+	if req.OrderBy != "" && req.OrderBy != "name" {
+		order = req.OrderBy
+	}
+	if req.OrderDirection == "desc" {
+		order = "-" + order
+	}
+	search.SortBy([]string{order})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
-	search.Fields = []string{"*"}
+	search.Fields = nodeFields
+	search.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	search.Highlight.Fields = []string{"content"}
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
+		node := model.SearchNode{
 			Parent: src.Fields["parent"].(string),
 			Name:   src.Fields["name"].(string),
 			IsDir:  src.Fields["is_dir"].(bool),
 			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		}
+		if modified, ok := src.Fields["modified"].(string); ok {
+			node.Modified, _ = time.Parse(time.RFC3339, modified)
+		}
+		if objType, ok := src.Fields["obj_type"].(float64); ok {
+			node.ObjType = int(objType)
+		}
+		if hashInfo, ok := src.Fields["hash_info"].(string); ok {
+			node.HashInfo = hashInfo
+		}
+		node.Snippets = src.Fragments["content"]
+		return node, nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
-	return b.BIndex.Index(uuid.NewString(), node)
+	return b.BIndex.Index(uuid.NewString(), toDocument(node))
 }
 
 func (b *Bleve) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	batch := b.BIndex.NewBatch()
 	for _, node := range nodes {
-		batch.Index(uuid.NewString(), node)
+		batch.Index(uuid.NewString(), toDocument(node))
 	}
 	return b.BIndex.Batch(batch)
 }
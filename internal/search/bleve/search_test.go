@@ -0,0 +1,75 @@
+package bleve
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestSearchFilters(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "bleve")
+	index, err := Init(&indexPath)
+	if err != nil {
+		t.Fatalf("failed init index: %+v", err)
+	}
+	b := &Bleve{BIndex: index}
+	defer b.Release(context.Background())
+	now := time.Now()
+	err = b.BatchIndex(context.Background(), []model.SearchNode{
+		{Parent: "/a", Name: "Foo Bar.TXT", Size: 10, Modified: now, ObjType: conf.TEXT},
+		{Parent: "/a", Name: "foo.mp4", Size: 1000, Modified: now.Add(-time.Hour), ObjType: conf.VIDEO, HashInfo: `{"md5":"x"}`},
+		{Parent: "/a", Name: "dir", IsDir: true, Modified: now, ObjType: conf.FOLDER},
+		{Parent: "/b", Name: "notes.md", Size: 20, Modified: now, ObjType: conf.TEXT, Content: "the quick brown fox <jumps>"},
+	})
+	if err != nil {
+		t.Fatalf("failed index: %+v", err)
+	}
+	tests := []struct {
+		req    model.SearchReq
+		expect []string
+	}{
+		{model.SearchReq{Mode: model.SearchModePhrase, Keywords: "o B"}, []string{"Foo Bar.TXT"}},
+		{model.SearchReq{Mode: model.SearchModeRegex, Keywords: "fo+"}, []string{"foo.mp4"}},
+		{model.SearchReq{Mode: model.SearchModeRegex, Keywords: "o", Exts: []string{"txt"}}, []string{"Foo Bar.TXT"}},
+		{model.SearchReq{Mode: model.SearchModeRegex, Keywords: "o", MinSize: 100}, []string{"foo.mp4"}},
+		{model.SearchReq{Mode: model.SearchModeRegex, Keywords: "o", ModifiedAfter: now.Add(-time.Minute)}, []string{"Foo Bar.TXT", "notes.md"}},
+		{model.SearchReq{Mode: model.SearchModeRegex, Keywords: ".", Types: []int{conf.VIDEO, conf.FOLDER}}, []string{"dir", "foo.mp4"}},
+		{model.SearchReq{Mode: model.SearchModeRegex, Keywords: ".", OrderBy: "size", OrderDirection: "desc", Scope: 2}, []string{"foo.mp4", "notes.md", "Foo Bar.TXT"}},
+		{model.SearchReq{Keywords: "brown"}, []string{"notes.md"}},
+		{model.SearchReq{Mode: model.SearchModePhrase, Keywords: "quick brown"}, []string{"notes.md"}},
+		{model.SearchReq{Mode: model.SearchModePhrase, Keywords: "brown quick"}, nil},
+	}
+	for _, tt := range tests {
+		tt.req.Page, tt.req.PerPage = 1, 10
+		nodes, total, err := b.Search(context.Background(), tt.req)
+		if err != nil {
+			t.Fatalf("failed search %+v: %+v", tt.req, err)
+		}
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		if int(total) != len(tt.expect) || len(names) != len(tt.expect) {
+			t.Errorf("search %+v: expect %v, got %v", tt.req, tt.expect, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.expect[i] {
+				t.Errorf("search %+v: expect %v, got %v", tt.req, tt.expect, names)
+				break
+			}
+		}
+	}
+	nodes, _, _ := b.Search(context.Background(), model.SearchReq{Keywords: "fox", PageReq: model.PageReq{Page: 1, PerPage: 10}})
+	if len(nodes) != 1 || len(nodes[0].Snippets) != 1 || nodes[0].Snippets[0] != "the quick brown <mark>fox</mark> &lt;jumps&gt;" {
+		t.Errorf("expect the highlighted snippet, got %+v", nodes)
+	}
+	nodes, _, _ = b.Search(context.Background(), model.SearchReq{Keywords: "mp4", Mode: model.SearchModePhrase, PageReq: model.PageReq{Page: 1, PerPage: 10}})
+	if len(nodes) != 1 || nodes[0].HashInfo != `{"md5":"x"}` || nodes[0].ObjType != conf.VIDEO || now.Add(-time.Hour).Sub(nodes[0].Modified).Abs() > time.Second {
+		t.Errorf("expect the node to be stored, got %+v", nodes)
+	}
+}
@@ -9,14 +9,17 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/notify"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/mq"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -25,6 +28,10 @@ var (
 	Quit    chan struct{}
 )
 
+// IndexVersion is increased when the fields of the index change,
+// the index built by an older version is rebuilt on startup
+const IndexVersion = 1
+
 func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth int, count bool) error {
 	var (
 		err      error
@@ -85,11 +92,13 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 					} else {
 						objCount = objCount + uint64(len(messages))
 					}
+					version := 0
 					if originErr != nil {
 						log.Errorf("build index error: %+v", originErr)
 						eMsg = originErr.Error()
 					} else {
 						log.Infof("success build index, count: %d", objCount)
+						version = IndexVersion
 					}
 					if count {
 						WriteProgress(&model.IndexProgress{
@@ -97,9 +106,13 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 							IsDone:       true,
 							LastDoneTime: &now,
 							Error:        eMsg,
+							Version:      version,
 						})
 					}
 				})
+				if count {
+					notify.Send(notify.EventIndexBuilt, notify.Data{"Count": objCount, "Error": eMsg})
+				}
 				return
 			}
 		}
@@ -133,6 +146,10 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 			if indexPath == "/" {
 				return nil
 			}
+			// the removed objs are not searchable
+			if info.IsDir() && fs.InTrash(indexPath) {
+				return filepath.SkipDir
+			}
 			indexMQ.Publish(mq.Message[ObjWithParent]{
 				Content: ObjWithParent{
 					Obj:    info,
@@ -141,6 +158,9 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 			})
 			return nil
 		}
+		if fs.InTrash(indexPath) {
+			continue
+		}
 		fi, err = fs.Get(ctx, indexPath, &fs.GetArgs{})
 		if err != nil {
 			return err
@@ -154,6 +174,18 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 	return nil
 }
 
+// Rebuild clears the index and builds it for all the storages
+func Rebuild(ctx context.Context) error {
+	if instance == nil {
+		return errs.SearchNotAvailable
+	}
+	if err := Clear(ctx); err != nil {
+		return errors.WithMessage(err, "failed clear index")
+	}
+	return BuildIndex(ctx, []string{"/"},
+		conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), true)
+}
+
 func Del(ctx context.Context, prefix string) error {
 	return instance.Del(ctx, prefix)
 }
@@ -170,7 +202,7 @@ func Update(parent string, objs []model.Obj) {
 	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running.Load() {
 		return
 	}
-	if isIgnorePath(parent) {
+	if isIgnorePath(parent) || fs.InTrash(parent) {
 		return
 	}
 	ctx := context.Background()
@@ -199,6 +231,21 @@ func Update(parent string, objs []model.Obj) {
 	// delete data that no longer exists
 	toDelete := old.Difference(now)
 	toAdd := now.Difference(old)
+	// the modified files are indexed again, e.g. the changes reported by the change feeds
+	objsByName := make(map[string]model.Obj, len(objs))
+	for i := range objs {
+		objsByName[objs[i].GetName()] = objs[i]
+	}
+	for i := range nodes {
+		obj, ok := objsByName[nodes[i].Name]
+		if !ok || obj.IsDir() || nodes[i].IsDir {
+			continue
+		}
+		if obj.GetSize() != nodes[i].Size || !nodes[i].Modified.IsZero() && obj.ModTime().Unix() != nodes[i].Modified.Unix() {
+			toDelete.Add(nodes[i].Name)
+			toAdd.Add(nodes[i].Name)
+		}
+	}
 	for i := range nodes {
 		if toDelete.Contains(nodes[i].Name) && !op.HasStorage(path.Join(parent, nodes[i].Name)) {
 			log.Debugf("delete index: %s", path.Join(parent, nodes[i].Name))
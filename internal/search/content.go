@@ -0,0 +1,88 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"io"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/doctext"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// the text beyond it is not indexed, to keep the index small
+	maxContentLength = 1 << 20
+	contentTimeout   = time.Minute
+)
+
+func indexContent() bool {
+	return instance != nil && instance.Config().Content && setting.GetBool(conf.IndexContent)
+}
+
+// fillContent extracts the content of the documents among the nodes,
+// the documents failed to be extracted are still indexed by the names
+func fillContent(ctx context.Context, nodes []model.SearchNode) {
+	if !indexContent() {
+		return
+	}
+	maxSize := int64(setting.GetInt(conf.IndexContentMaxSize, 10)) << 20
+	for i := range nodes {
+		if nodes[i].IsDir || nodes[i].Size > maxSize || !doctext.Supported(nodes[i].Name) {
+			continue
+		}
+		path := stdpath.Join(nodes[i].Parent, nodes[i].Name)
+		content, err := readContent(ctx, path, maxSize)
+		if err != nil {
+			log.Warnf("failed index the content of %s: %+v", path, err)
+			continue
+		}
+		nodes[i].Content = content
+	}
+}
+
+// readContent reads the whole document by a ranged read of the link, and extracts the text
+func readContent(ctx context.Context, path string, maxSize int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, contentTimeout)
+	defer cancel()
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get storage")
+	}
+	link, obj, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+	if err != nil {
+		return "", errors.WithMessage(err, "failed link")
+	}
+	if obj.GetSize() > maxSize {
+		return "", errors.Errorf("the size %d is larger than %d", obj.GetSize(), maxSize)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, link)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	r, err := ss.RangeRead(http_range.Range{Start: 0, Length: obj.GetSize()})
+	if err != nil {
+		return "", errors.WithMessage(err, "failed read")
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize))
+	if err != nil {
+		return "", errors.WithMessage(err, "failed read")
+	}
+	content, err := doctext.Extract(obj.GetName(), bytes.NewReader(data), int64(len(data)), doctext.Limits{
+		MaxSize: maxSize,
+		MaxText: maxContentLength,
+	})
+	if err != nil {
+		return "", errors.WithMessage(err, "failed extract")
+	}
+	return content, nil
+}
@@ -3,12 +3,15 @@ package meilisearch
 import (
 	"context"
 	"fmt"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
+	"html"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +19,63 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// the derived fields to be filtered and sorted
+	Ext          string `json:"ext"`
+	ModifiedUnix int64  `json:"modified_unix"`
+	Content      string `json:"content,omitempty"`
+}
+
+func toDocument(node model.SearchNode) *searchDocument {
+	return &searchDocument{
+		ID:           uuid.NewString(),
+		SearchNode:   node,
+		Ext:          node.Ext(),
+		ModifiedUnix: node.Modified.Unix(),
+		Content:      node.Content,
+	}
+}
+
+// the fields except the content, which is only retrieved as the snippets
+var nodeFields = []string{"id", "parent", "name", "is_dir", "size", "modified", "obj_type", "hash_info"}
+
+// the highlight tags are replaced after the snippets are escaped,
+// since the content is not escaped by meilisearch
+const (
+	highlightPreTag  = "\x02"
+	highlightPostTag = "\x03"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightPreTag, "<mark>", highlightPostTag, "</mark>")
+
+func snippetsOf(src map[string]any) []string {
+	formatted, ok := src["_formatted"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	content, ok := formatted["content"].(string)
+	if !ok || !strings.Contains(content, highlightPreTag) {
+		return nil
+	}
+	return []string{highlightReplacer.Replace(html.EscapeString(content))}
+}
+
+func fromMap(src map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src["parent"].(string),
+		Name:   src["name"].(string),
+		IsDir:  src["is_dir"].(bool),
+		Size:   int64(src["size"].(float64)),
+	}
+	if modified, ok := src["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	if objType, ok := src["obj_type"].(float64); ok {
+		node.ObjType = int(objType)
+	}
+	if hashInfo, ok := src["hash_info"].(string); ok {
+		node.HashInfo = hashInfo
+	}
+	return node
 }
 
 type Meilisearch struct {
@@ -23,6 +83,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -30,26 +91,71 @@ func (m *Meilisearch) Config() searcher.Config {
 }
 
 func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	keywords := req.Keywords
+	switch req.Mode {
+	case model.SearchModePhrase:
+		keywords = `"` + strings.ReplaceAll(keywords, `"`, "") + `"`
+	case model.SearchModeRegex:
+		return nil, 0, errs.NotSupport
+	}
 	mReq := &meilisearch.SearchRequest{
-		AttributesToSearchOn: m.SearchableAttributes,
-		Page:                 int64(req.Page),
-		HitsPerPage:          int64(req.PerPage),
+		AttributesToSearchOn:  m.SearchableAttributes,
+		AttributesToRetrieve:  nodeFields,
+		AttributesToCrop:      []string{"content"},
+		CropLength:            32,
+		AttributesToHighlight: []string{"content"},
+		HighlightPreTag:       highlightPreTag,
+		HighlightPostTag:      highlightPostTag,
+		Page:                  int64(req.Page),
+		HitsPerPage:           int64(req.PerPage),
 	}
+	var filters []string
 	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.MinSize > 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize > 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if !req.ModifiedAfter.IsZero() {
+		filters = append(filters, fmt.Sprintf("modified_unix >= %d", req.ModifiedAfter.Unix()))
+	}
+	if !req.ModifiedBefore.IsZero() {
+		filters = append(filters, fmt.Sprintf("modified_unix <= %d", req.ModifiedBefore.Unix()))
+	}
+	if len(req.Types) > 0 {
+		filters = append(filters, fmt.Sprintf("obj_type IN [%s]", strings.Join(utils.MustSliceConvert(req.Types, strconv.Itoa), ",")))
+	}
+	if len(req.Exts) > 0 {
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(utils.MustSliceConvert(req.Exts, func(src string) string {
+			return "'" + strings.ReplaceAll(src, "'", "\\'") + "'"
+		}), ",")))
 	}
-	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
+	if len(filters) > 0 {
+		mReq.Filter = strings.Join(filters, " AND ")
+	}
+	if req.OrderBy != "" {
+		orderBy := req.OrderBy
+		if orderBy == "modified" {
+			orderBy = "modified_unix"
+		}
+		direction := "asc"
+		if req.OrderDirection == "desc" {
+			direction = "desc"
+		}
+		mReq.Sort = []string{orderBy + ":" + direction}
+	}
+	search, err := m.Client.Index(m.IndexUid).Search(keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
 		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		node := fromMap(srcMap)
+		node.Snippets = snippetsOf(srcMap)
+		return node, nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -63,11 +169,7 @@ func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-
-		return &searchDocument{
-			ID:         uuid.NewString(),
-			SearchNode: src,
-		}, nil
+		return toDocument(src), nil
 	})
 
 	_, err := m.Client.Index(m.IndexUid).AddDocuments(documents)
@@ -94,19 +196,15 @@ func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) (
 	err := m.Client.Index(m.IndexUid).GetDocuments(&meilisearch.DocumentsQuery{
 		Filter: fmt.Sprintf("parent = '%s'", strings.ReplaceAll(parent, "'", "\\'")),
 		Limit:  int64(model.MaxInt),
+		Fields: nodeFields,
 	}, &result)
 	if err != nil {
 		return nil, err
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: fromMap(src),
 		}, nil
 	})
 }
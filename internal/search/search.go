@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -55,12 +56,24 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	nodes := []model.SearchNode{toSearchNode(parent, obj)}
+	fillContent(ctx, nodes)
+	return instance.Index(ctx, nodes[0])
+}
+
+func toSearchNode(parent string, obj model.Obj) model.SearchNode {
+	node := model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		ObjType:  utils.GetObjType(obj.GetName(), obj.IsDir()),
+	}
+	if len(obj.GetHash().Export()) > 0 {
+		node.HashInfo = obj.GetHash().String()
+	}
+	return node
 }
 
 type ObjWithParent struct {
@@ -77,13 +90,9 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		searchNodes = append(searchNodes, toSearchNode(objs[i].Parent, objs[i].Obj))
 	}
+	fillContent(ctx, searchNodes)
 	return instance.BatchIndex(ctx, searchNodes)
 }
 
@@ -9,6 +9,8 @@ import (
 type Config struct {
 	Name       string
 	AutoUpdate bool
+	// Content is whether the content of the documents can be indexed and searched
+	Content bool
 }
 
 type Searcher interface {
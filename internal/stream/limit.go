@@ -7,33 +7,114 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"golang.org/x/time/rate"
 	"io"
-	"time"
+	"math"
 )
 
+// Limiter is satisfied by rate.Limiter, the readers and the writers only wait for the bytes
 type Limiter interface {
-	Limit() rate.Limit
-	Burst() int
-	TokensAt(time.Time) float64
-	Tokens() float64
-	Allow() bool
-	AllowN(time.Time, int) bool
-	Reserve() *rate.Reservation
-	ReserveN(time.Time, int) *rate.Reservation
-	Wait(context.Context) error
 	WaitN(context.Context, int) error
-	SetLimit(rate.Limit)
-	SetLimitAt(time.Time, rate.Limit)
-	SetBurst(int)
-	SetBurstAt(time.Time, int)
 }
 
 var (
 	ClientDownloadLimit Limiter
 	ClientUploadLimit   Limiter
-	ServerDownloadLimit Limiter
-	ServerUploadLimit   Limiter
+	// the limits of the whole server, they are unlimited until set by the settings
+	ServerDownloadLimit = NewRateLimiter(0)
+	ServerUploadLimit   = NewRateLimiter(0)
 )
 
+// RateLimiter limits the bytes per second, the rate can be changed at any time
+type RateLimiter struct {
+	*rate.Limiter
+}
+
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	l := &RateLimiter{Limiter: rate.NewLimiter(rate.Inf, 0)}
+	l.SetRate(bytesPerSecond)
+	return l
+}
+
+// SetRate sets the bytes per second, 0 or less means unlimited
+func (l *RateLimiter) SetRate(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		l.SetLimit(rate.Inf)
+		return
+	}
+	// a second of the rate can be taken at once,
+	// the burst is set first so that it's never 0 with a finite limit
+	l.SetBurst(int(min(bytesPerSecond, math.MaxInt32)))
+	l.SetLimit(rate.Limit(bytesPerSecond))
+}
+
+// WaitN waits for n bytes in pieces of the burst, a read is often larger than the burst of a low rate
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if l.Limit() == rate.Inf {
+		return nil
+	}
+	for n > 0 {
+		m := min(n, max(l.Burst(), 1))
+		if err := l.Limiter.WaitN(ctx, m); err != nil {
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+type multiLimiter []Limiter
+
+func (ls multiLimiter) WaitN(ctx context.Context, n int) error {
+	for _, l := range ls {
+		if err := l.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewMultiLimiter composes the limiters, the slowest one limits the rate, the nil ones are skipped
+func NewMultiLimiter(limiters ...Limiter) Limiter {
+	var ls multiLimiter
+	for _, l := range limiters {
+		if l != nil {
+			ls = append(ls, l)
+		}
+	}
+	if len(ls) == 1 {
+		return ls[0]
+	}
+	return ls
+}
+
+// WithDownloadLimiter sets the limiter of the downloads done with the ctx instead of ServerDownloadLimit,
+// it's usually composed of the limits of the server, the storage and the user
+func WithDownloadLimiter(ctx context.Context, l Limiter) context.Context {
+	return context.WithValue(ctx, "download_limiter", l)
+}
+
+// WithUploadLimiter sets the limiter of the uploads done with the ctx instead of ServerUploadLimit
+func WithUploadLimiter(ctx context.Context, l Limiter) context.Context {
+	return context.WithValue(ctx, "upload_limiter", l)
+}
+
+func DownloadLimiter(ctx context.Context) Limiter {
+	if ctx != nil {
+		if l, ok := ctx.Value("download_limiter").(Limiter); ok {
+			return l
+		}
+	}
+	return ServerDownloadLimit
+}
+
+func UploadLimiter(ctx context.Context) Limiter {
+	if ctx != nil {
+		if l, ok := ctx.Value("upload_limiter").(Limiter); ok {
+			return l
+		}
+	}
+	return ServerUploadLimit
+}
+
 type RateLimitReader struct {
 	io.Reader
 	Limiter Limiter
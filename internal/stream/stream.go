@@ -186,7 +186,7 @@ func NewSeekableStream(fs FileStream, link *model.Link) (*SeekableStream, error)
 			if _, ok := mFile.(*os.File); !ok {
 				mFile = &RateLimitFile{
 					File:    mFile,
-					Limiter: ServerDownloadLimit,
+					Limiter: DownloadLimiter(fs.Ctx),
 					Ctx:     fs.Ctx,
 				}
 			}
@@ -198,7 +198,7 @@ func NewSeekableStream(fs FileStream, link *model.Link) (*SeekableStream, error)
 		if ss.Link.RangeReadCloser != nil {
 			ss.rangeReadCloser = &RateLimitRangeReadCloser{
 				RangeReadCloserIF: ss.Link.RangeReadCloser,
-				Limiter:           ServerDownloadLimit,
+				Limiter:           DownloadLimiter(fs.Ctx),
 			}
 			ss.Add(ss.rangeReadCloser)
 			return ss, nil
@@ -210,7 +210,7 @@ func NewSeekableStream(fs FileStream, link *model.Link) (*SeekableStream, error)
 			}
 			rrc = &RateLimitRangeReadCloser{
 				RangeReadCloserIF: rrc,
-				Limiter:           ServerDownloadLimit,
+				Limiter:           DownloadLimiter(fs.Ctx),
 			}
 			ss.rangeReadCloser = rrc
 			ss.Add(rrc)
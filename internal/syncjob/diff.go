@@ -0,0 +1,223 @@
+package syncjob
+
+import (
+	stdpath "path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// modTimeWindow is the tolerance when comparing modification times,
+// some storages only keep the modification time in seconds
+const modTimeWindow = time.Second
+
+// tree is all objs under the path of a job, the keys are paths relative to it
+type tree map[string]model.Obj
+
+type actionType int
+
+const (
+	copyToDst actionType = iota
+	copyToSrc
+	deleteDst
+	deleteSrc
+)
+
+type action struct {
+	typ  actionType
+	path string
+	// the obj to copy or delete
+	obj model.Obj
+}
+
+// hashEqual compares the first hash type both sides provide, ok is false if there is none
+func hashEqual(a, b utils.HashInfo) (equal bool, ok bool) {
+	for ht, v := range a.All() {
+		if w := b.GetHash(ht); v != "" && w != "" {
+			return strings.EqualFold(v, w), true
+		}
+	}
+	return false, false
+}
+
+func parseHash(s string) utils.HashInfo {
+	if s == "" {
+		return utils.NewHashInfo(nil, "")
+	}
+	return utils.FromString(s)
+}
+
+// changed reports whether the src file should be copied to the dst file,
+// the dst is considered outdated if the src is modified after it's written
+func changed(src, dst model.Obj) bool {
+	if src.GetSize() != dst.GetSize() {
+		return true
+	}
+	if equal, ok := hashEqual(src.GetHash(), dst.GetHash()); ok {
+		return !equal
+	}
+	return src.ModTime().After(dst.ModTime().Add(modTimeWindow))
+}
+
+// changedSince reports whether the obj is changed since it was recorded
+func changedSince(obj model.Obj, size int64, modified time.Time, hash string) bool {
+	if obj.GetSize() != size {
+		return true
+	}
+	if equal, ok := hashEqual(obj.GetHash(), parseHash(hash)); ok {
+		return !equal
+	}
+	d := obj.ModTime().Sub(modified)
+	return d > modTimeWindow || d < -modTimeWindow
+}
+
+// diff compares the two trees and returns the actions to sync them according to the mode of the job,
+// the records of the last run are used to tell whether an obj is new or deleted on the other side
+func diff(job *model.SyncJob, src, dst tree, records map[string]model.SyncRecord) []action {
+	paths := make([]string, 0, len(src)+len(dst))
+	for p := range src {
+		paths = append(paths, p)
+	}
+	for p := range dst {
+		if _, ok := src[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	// parents are always before their children
+	sort.Strings(paths)
+	var actions []action
+	var deleted []string
+	isDeleted := func(p string) bool {
+		for _, d := range deleted {
+			if strings.HasPrefix(p, d+"/") {
+				return true
+			}
+		}
+		return false
+	}
+	del := func(typ actionType, p string, obj model.Obj) {
+		actions = append(actions, action{typ: typ, path: p, obj: obj})
+		if obj.IsDir() {
+			deleted = append(deleted, p)
+		}
+	}
+	for _, p := range paths {
+		if isDeleted(p) {
+			continue
+		}
+		s, inSrc := src[p]
+		d, inDst := dst[p]
+		r, recorded := records[p]
+		switch {
+		case inSrc && inDst:
+			if s.IsDir() != d.IsDir() {
+				log.Warnf("sync job [%s]: [%s] is a file on one side and a folder on the other, skipped", job.Name, p)
+				continue
+			}
+			if s.IsDir() {
+				continue
+			}
+			if job.Mode != model.SyncModeTwoWay {
+				if changed(s, d) {
+					actions = append(actions, action{typ: copyToDst, path: p, obj: s})
+				}
+				continue
+			}
+			if equal, ok := hashEqual(s.GetHash(), d.GetHash()); ok && equal {
+				continue
+			}
+			if !recorded && s.GetSize() == d.GetSize() {
+				// no way to tell which one is newer without hash, regard them as the same
+				continue
+			}
+			srcChanged := !recorded || changedSince(s, r.SrcSize, r.SrcModified, r.SrcHash)
+			dstChanged := !recorded || changedSince(d, r.DstSize, r.DstModified, r.DstHash)
+			switch {
+			case srcChanged && dstChanged:
+				// conflict, the newer one wins
+				if s.ModTime().After(d.ModTime()) {
+					actions = append(actions, action{typ: copyToDst, path: p, obj: s})
+				} else {
+					actions = append(actions, action{typ: copyToSrc, path: p, obj: d})
+				}
+			case srcChanged:
+				actions = append(actions, action{typ: copyToDst, path: p, obj: s})
+			case dstChanged:
+				actions = append(actions, action{typ: copyToSrc, path: p, obj: d})
+			}
+		case inSrc:
+			// it's deleted from the dst since the last run
+			if job.Mode == model.SyncModeTwoWay && recorded && job.Delete {
+				del(deleteSrc, p, s)
+				continue
+			}
+			actions = append(actions, action{typ: copyToDst, path: p, obj: s})
+		case inDst:
+			switch job.Mode {
+			case model.SyncModeMirror:
+				del(deleteDst, p, d)
+			case model.SyncModeOneWay:
+				if recorded && job.Delete {
+					del(deleteDst, p, d)
+				}
+			case model.SyncModeTwoWay:
+				if recorded && job.Delete {
+					del(deleteDst, p, d)
+				} else {
+					actions = append(actions, action{typ: copyToSrc, path: p, obj: d})
+				}
+			}
+		}
+	}
+	return actions
+}
+
+// removeTree removes the obj at p and all its children from the tree
+func removeTree(t tree, p string) {
+	delete(t, p)
+	for k := range t {
+		if strings.HasPrefix(k, p+"/") {
+			delete(t, k)
+		}
+	}
+}
+
+// newRecords records the objs exist on both sides, the records of the failed paths are kept as before
+func newRecords(src, dst tree, old map[string]model.SyncRecord, failed map[string]bool) []model.SyncRecord {
+	var records []model.SyncRecord
+	for p, s := range src {
+		if failed[p] {
+			if r, ok := old[p]; ok {
+				records = append(records, r)
+			}
+			continue
+		}
+		d, ok := dst[p]
+		if !ok || s.IsDir() != d.IsDir() {
+			continue
+		}
+		records = append(records, model.SyncRecord{
+			Path:        p,
+			IsDir:       s.IsDir(),
+			SrcSize:     s.GetSize(),
+			SrcModified: s.ModTime(),
+			SrcHash:     s.GetHash().String(),
+			DstSize:     d.GetSize(),
+			DstModified: d.ModTime(),
+			DstHash:     d.GetHash().String(),
+		})
+	}
+	return records
+}
+
+func parentDir(p string) string {
+	dir := stdpath.Dir(p)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
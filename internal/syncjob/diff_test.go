@@ -0,0 +1,134 @@
+package syncjob
+
+import (
+	stdpath "path"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func file(p string, size int64, modified time.Time) model.Obj {
+	return &model.Object{Name: stdpath.Base(p), Size: size, Modified: modified}
+}
+
+func dir(p string) model.Obj {
+	return &model.Object{Name: stdpath.Base(p), IsFolder: true, Modified: base}
+}
+
+func record(p string, src, dst model.Obj) model.SyncRecord {
+	return model.SyncRecord{
+		Path:        p,
+		IsDir:       src.IsDir(),
+		SrcSize:     src.GetSize(),
+		SrcModified: src.ModTime(),
+		DstSize:     dst.GetSize(),
+		DstModified: dst.ModTime(),
+	}
+}
+
+func summary(actions []action) map[string]actionType {
+	m := make(map[string]actionType)
+	for _, a := range actions {
+		m[a.path] = a.typ
+	}
+	return m
+}
+
+func check(t *testing.T, got []action, want map[string]actionType) {
+	t.Helper()
+	s := summary(got)
+	if len(s) != len(want) {
+		t.Fatalf("expect %d actions, got %v", len(want), s)
+	}
+	for p, typ := range want {
+		if s[p] != typ {
+			t.Errorf("expect action %d on [%s], got %v", typ, p, s)
+		}
+	}
+}
+
+func TestDiffMirror(t *testing.T) {
+	src := tree{
+		"a":     dir("a"),
+		"a/new": file("a/new", 1, base),
+		"same":  file("same", 1, base),
+		"mod":   file("mod", 2, base),
+	}
+	dst := tree{
+		"same":    file("same", 1, base.Add(time.Hour)),
+		"mod":     file("mod", 1, base),
+		"extra":   dir("extra"),
+		"extra/x": file("extra/x", 1, base),
+	}
+	job := &model.SyncJob{Mode: model.SyncModeMirror}
+	check(t, diff(job, src, dst, nil), map[string]actionType{
+		"a":     copyToDst,
+		"a/new": copyToDst,
+		"mod":   copyToDst,
+		"extra": deleteDst,
+	})
+}
+
+func TestDiffHash(t *testing.T) {
+	src := tree{"f": &model.Object{Name: "f", Size: 1, Modified: base.Add(time.Hour), HashInfo: utils.NewHashInfo(utils.MD5, "aa")}}
+	dst := tree{"f": &model.Object{Name: "f", Size: 1, Modified: base, HashInfo: utils.NewHashInfo(utils.MD5, "AA")}}
+	job := &model.SyncJob{Mode: model.SyncModeOneWay}
+	// newer but the same content
+	check(t, diff(job, src, dst, nil), map[string]actionType{})
+	dst["f"].(*model.Object).HashInfo = utils.NewHashInfo(utils.MD5, "bb")
+	check(t, diff(job, src, dst, nil), map[string]actionType{"f": copyToDst})
+}
+
+func TestDiffOneWayDelete(t *testing.T) {
+	src := tree{}
+	dst := tree{
+		"synced": file("synced", 1, base),
+		"own":    file("own", 1, base),
+	}
+	records := map[string]model.SyncRecord{"synced": record("synced", dst["synced"], dst["synced"])}
+	job := &model.SyncJob{Mode: model.SyncModeOneWay}
+	check(t, diff(job, src, dst, records), map[string]actionType{})
+	job.Delete = true
+	check(t, diff(job, src, dst, records), map[string]actionType{"synced": deleteDst})
+}
+
+func TestDiffTwoWay(t *testing.T) {
+	old := file("f", 1, base)
+	src := tree{
+		"src_changed": file("src_changed", 2, base.Add(time.Hour)),
+		"dst_changed": old,
+		"both":        file("both", 2, base.Add(time.Hour)),
+		"src_new":     file("src_new", 1, base),
+		"dst_deleted": old,
+	}
+	dst := tree{
+		"src_changed": old,
+		"dst_changed": file("dst_changed", 3, base.Add(time.Hour)),
+		"both":        file("both", 3, base.Add(2*time.Hour)),
+		"dst_new":     file("dst_new", 1, base),
+	}
+	records := map[string]model.SyncRecord{
+		"src_changed": record("src_changed", old, old),
+		"dst_changed": record("dst_changed", old, old),
+		"both":        record("both", old, old),
+		"dst_deleted": record("dst_deleted", old, old),
+	}
+	job := &model.SyncJob{Mode: model.SyncModeTwoWay}
+	check(t, diff(job, src, dst, records), map[string]actionType{
+		"src_changed": copyToDst,
+		"dst_changed": copyToSrc,
+		"both":        copyToSrc,
+		"src_new":     copyToDst,
+		"dst_new":     copyToSrc,
+		"dst_deleted": copyToDst,
+	})
+	job.Delete = true
+	got := summary(diff(job, src, dst, records))
+	if got["dst_deleted"] != deleteSrc {
+		t.Errorf("expect the deletion to be propagated, got %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package syncjob
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	crons   = make(map[uint]*cron.Cron)
+	cronsMu sync.Mutex
+)
+
+// Init schedules all enabled jobs, it should be called after the task manager is initialized
+func Init() {
+	jobs, err := db.GetSyncJobs()
+	if err != nil {
+		log.Errorf("failed get sync jobs: %+v", err)
+		return
+	}
+	for i := range jobs {
+		schedule(&jobs[i])
+	}
+}
+
+// schedule (re)starts the cron of the job, the cron is stopped if the job is disabled
+func schedule(job *model.SyncJob) {
+	cronsMu.Lock()
+	defer cronsMu.Unlock()
+	if c, ok := crons[job.ID]; ok {
+		c.Stop()
+		delete(crons, job.ID)
+	}
+	if job.Disabled || job.Interval <= 0 {
+		return
+	}
+	id := job.ID
+	c := cron.NewCron(time.Duration(job.Interval) * time.Minute)
+	c.Do(func() {
+		if _, err := Run(context.Background(), id); err != nil {
+			log.Warnf("failed run sync job %d: %+v", id, err)
+		}
+	})
+	crons[job.ID] = c
+}
+
+func unschedule(id uint) {
+	cronsMu.Lock()
+	defer cronsMu.Unlock()
+	if c, ok := crons[id]; ok {
+		c.Stop()
+		delete(crons, id)
+	}
+}
+
+func validate(job *model.SyncJob) error {
+	job.SrcPath = utils.FixAndCleanPath(job.SrcPath)
+	job.DstPath = utils.FixAndCleanPath(job.DstPath)
+	if job.Mode == "" {
+		job.Mode = model.SyncModeOneWay
+	}
+	switch job.Mode {
+	case model.SyncModeMirror, model.SyncModeOneWay, model.SyncModeTwoWay:
+	default:
+		return errors.Errorf("unknown sync mode: %s", job.Mode)
+	}
+	if utils.IsSubPath(job.SrcPath, job.DstPath) || utils.IsSubPath(job.DstPath, job.SrcPath) {
+		return errors.New("the src path and the dst path can't contain each other")
+	}
+	if job.Interval < 0 {
+		return errors.New("interval can't be negative")
+	}
+	if job.Name == "" {
+		job.Name = job.SrcPath + " -> " + job.DstPath
+	}
+	return nil
+}
+
+func GetJobs() ([]model.SyncJob, error) {
+	return db.GetSyncJobs()
+}
+
+func GetJobById(id uint) (*model.SyncJob, error) {
+	return db.GetSyncJobById(id)
+}
+
+func CreateJob(job *model.SyncJob) error {
+	job.ID = 0
+	if err := validate(job); err != nil {
+		return err
+	}
+	if err := db.CreateSyncJob(job); err != nil {
+		return err
+	}
+	schedule(job)
+	return nil
+}
+
+func UpdateJob(job *model.SyncJob) error {
+	if err := validate(job); err != nil {
+		return err
+	}
+	old, err := db.GetSyncJobById(job.ID)
+	if err != nil {
+		return err
+	}
+	// the result of the last run is not editable
+	job.LastRun, job.Status = old.LastRun, old.Status
+	if err = db.UpdateSyncJob(job); err != nil {
+		return err
+	}
+	// the records are meaningless if the paths are changed
+	if old.SrcPath != job.SrcPath || old.DstPath != job.DstPath {
+		if err = db.ReplaceSyncRecords(job.ID, nil); err != nil {
+			return err
+		}
+	}
+	schedule(job)
+	return nil
+}
+
+func DeleteJobById(id uint) error {
+	unschedule(id)
+	return db.DeleteSyncJobById(id)
+}
+
+// Run adds a task to run the job, it fails if the job is running
+func Run(ctx context.Context, id uint) (task.TaskExtensionInfo, error) {
+	job, err := db.GetSyncJobById(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range SyncTaskManager.GetByState(tache.StatePending, tache.StateRunning, tache.StateErrored, tache.StateWaitingRetry, tache.StateCanceling) {
+		if t.JobID == id {
+			return nil, errors.Errorf("sync job [%s] is running", job.Name)
+		}
+	}
+	t := &SyncTask{
+		JobID:   job.ID,
+		JobName: job.Name,
+		SrcPath: job.SrcPath,
+		DstPath: job.DstPath,
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t.SetCreator(taskCreator)
+	SyncTaskManager.Add(t)
+	return t, nil
+}
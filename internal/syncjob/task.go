@@ -0,0 +1,208 @@
+package syncjob
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type SyncTask struct {
+	task.TaskExtension
+	JobID   uint   `json:"job_id"`
+	JobName string `json:"job_name"`
+	SrcPath string `json:"src_path"`
+	DstPath string `json:"dst_path"`
+	Status  string `json:"-"`
+}
+
+func (t *SyncTask) GetName() string {
+	return fmt.Sprintf("sync [%s](%s) with [%s]", t.JobName, t.SrcPath, t.DstPath)
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *SyncTask) Run() error {
+	job, err := db.GetSyncJobById(t.JobID)
+	if err != nil {
+		return err
+	}
+	err = t.sync(job)
+	now := time.Now()
+	job.LastRun = &now
+	if err != nil {
+		job.Status = err.Error()
+	} else {
+		job.Status = t.Status
+	}
+	if err := db.UpdateSyncJobStatus(job); err != nil {
+		log.Errorf("failed update status of sync job [%s]: %+v", job.Name, err)
+	}
+	return err
+}
+
+var SyncTaskManager *tache.Manager[*SyncTask]
+
+func (t *SyncTask) sync(job *model.SyncJob) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(job.SrcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstActualPath, err := op.GetStorageAndActualPath(job.DstPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	records, err := db.GetSyncRecords(job.ID)
+	if err != nil {
+		return errors.WithMessage(err, "failed get sync records")
+	}
+	t.Status = "listing src objs"
+	// a missing src can't be told from a typo or a failure of the driver, syncing it may wipe the dst
+	src, err := walk(t.Ctx(), srcStorage, srcActualPath, false)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s]", job.SrcPath)
+	}
+	t.Status = "listing dst objs"
+	// the dst is created by the first run, after that a missing dst is the same as a missing src,
+	// syncing it may wipe the src in the two-way mode
+	dst, err := walk(t.Ctx(), dstStorage, dstActualPath, len(records) == 0)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list dst [%s]", job.DstPath)
+	}
+	recordMap := make(map[string]model.SyncRecord, len(records))
+	for _, r := range records {
+		recordMap[r.Path] = r
+	}
+
+	actions := diff(job, src, dst, recordMap)
+	failed := make(map[string]bool)
+	var lastErr error
+	for i, a := range actions {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		t.Status = fmt.Sprintf("syncing [%s] (%d/%d)", a.path, i+1, len(actions))
+		if err := t.apply(a, srcStorage, srcActualPath, dstStorage, dstActualPath, src, dst); err != nil {
+			log.Errorf("sync job [%s]: failed sync [%s]: %+v", job.Name, a.path, err)
+			failed[a.path] = true
+			lastErr = err
+		}
+		t.SetProgress(float64(i+1) / float64(len(actions)) * 100)
+	}
+
+	if err := db.ReplaceSyncRecords(job.ID, newRecords(src, dst, recordMap, failed)); err != nil {
+		return errors.WithMessage(err, "failed save sync records")
+	}
+	if lastErr != nil {
+		return errors.WithMessagef(lastErr, "failed sync %d of %d objs, the last error", len(failed), len(actions))
+	}
+	t.Status = fmt.Sprintf("synced %d objs", len(actions))
+	return nil
+}
+
+// apply performs the action and updates the trees to the state after it
+func (t *SyncTask) apply(a action, srcStorage driver.Driver, srcRoot string, dstStorage driver.Driver, dstRoot string, src, dst tree) error {
+	switch a.typ {
+	case copyToDst:
+		obj, err := transfer(t.Ctx(), srcStorage, stdpath.Join(srcRoot, a.path), dstStorage, stdpath.Join(dstRoot, parentDir(a.path)), a.obj)
+		if err != nil {
+			return err
+		}
+		dst[a.path] = obj
+	case copyToSrc:
+		obj, err := transfer(t.Ctx(), dstStorage, stdpath.Join(dstRoot, a.path), srcStorage, stdpath.Join(srcRoot, parentDir(a.path)), a.obj)
+		if err != nil {
+			return err
+		}
+		src[a.path] = obj
+	case deleteDst:
+		if err := op.Remove(t.Ctx(), dstStorage, stdpath.Join(dstRoot, a.path)); err != nil {
+			return err
+		}
+		removeTree(dst, a.path)
+	case deleteSrc:
+		if err := op.Remove(t.Ctx(), srcStorage, stdpath.Join(srcRoot, a.path)); err != nil {
+			return err
+		}
+		removeTree(src, a.path)
+	}
+	return nil
+}
+
+// transfer copies the obj to the dir of another storage and returns the new obj
+func transfer(ctx context.Context, from driver.Driver, fromPath string, to driver.Driver, toDirPath string, obj model.Obj) (model.Obj, error) {
+	toPath := stdpath.Join(toDirPath, obj.GetName())
+	if obj.IsDir() {
+		if err := op.MakeDir(ctx, to, toPath); err != nil {
+			return nil, err
+		}
+		return op.Get(ctx, to, toPath)
+	}
+	link, _, err := op.Link(ctx, from, fromPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get [%s] link", fromPath)
+	}
+	fs := stream.FileStream{
+		Obj: obj,
+		Ctx: stream.WithDownloadLimiter(ctx, op.DownloadLimiter(ctx, from)),
+	}
+	// any link provided is seekable
+	ss, err := stream.NewSeekableStream(fs, link)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get [%s] stream", fromPath)
+	}
+	if err = op.Put(ctx, to, toDirPath, ss, nil, false); err != nil {
+		return nil, err
+	}
+	return op.Get(ctx, to, toPath)
+}
+
+// walk lists all objs under the root recursively,
+// a root which doesn't exist is regarded as empty if missingAsEmpty, otherwise it's an error
+func walk(ctx context.Context, storage driver.Driver, root string, missingAsEmpty bool) (tree, error) {
+	t := make(tree)
+	if _, err := op.Get(ctx, storage, root); err != nil {
+		if missingAsEmpty && errs.IsNotFoundError(err) {
+			return t, nil
+		}
+		return nil, err
+	}
+	var walkDir func(dir, rel string) error
+	walkDir = func(dir, rel string) error {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		objs, err := op.List(ctx, storage, dir, model.ListArgs{}, true)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			p := stdpath.Join(rel, obj.GetName())
+			t[p] = obj
+			if obj.IsDir() {
+				if err := walkDir(stdpath.Join(dir, obj.GetName()), p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return t, walkDir(root, "")
+}
@@ -0,0 +1,83 @@
+package syncjob
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestMirrorMissingSrc(t *testing.T) {
+	srcRoot, dstRoot := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(dstRoot, "a.txt"), []byte("a"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for mountPath, root := range map[string]string{"/sync_src": srcRoot, "/sync_dst": dstRoot} {
+		addition, _ := json.Marshal(map[string]string{"root_folder_path": root})
+		if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: mountPath, Addition: string(addition)}); err != nil {
+			t.Fatalf("failed create storage: %+v", err)
+		}
+	}
+	job := &model.SyncJob{Name: "mirror", SrcPath: "/sync_src/missing", DstPath: "/sync_dst", Mode: model.SyncModeMirror}
+	if err := db.CreateSyncJob(job); err != nil {
+		t.Fatalf("failed create job: %+v", err)
+	}
+	task := &SyncTask{JobID: job.ID}
+	task.SetCtx(ctx)
+	if err := task.sync(job); err == nil {
+		t.Errorf("expect the missing src to fail the sync")
+	}
+	if _, err := os.Stat(filepath.Join(dstRoot, "a.txt")); err != nil {
+		t.Errorf("expect the dst to be untouched, %+v", err)
+	}
+}
+
+func TestTwoWayMissingDst(t *testing.T) {
+	srcRoot, dstRoot := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcRoot, "a.txt"), []byte("a"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for mountPath, root := range map[string]string{"/two_way_src": srcRoot, "/two_way_dst": dstRoot} {
+		addition, _ := json.Marshal(map[string]string{"root_folder_path": root})
+		if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: mountPath, Addition: string(addition)}); err != nil {
+			t.Fatalf("failed create storage: %+v", err)
+		}
+	}
+	job := &model.SyncJob{Name: "two way", SrcPath: "/two_way_src", DstPath: "/two_way_dst/backup", Mode: model.SyncModeTwoWay, Delete: true}
+	if err := db.CreateSyncJob(job); err != nil {
+		t.Fatalf("failed create job: %+v", err)
+	}
+	// the dst is created by the first run
+	if err := db.ReplaceSyncRecords(job.ID, []model.SyncRecord{{JobID: job.ID, Path: "a.txt"}}); err != nil {
+		t.Fatalf("failed save records: %+v", err)
+	}
+	task := &SyncTask{JobID: job.ID}
+	task.SetCtx(ctx)
+	if err := task.sync(job); err == nil {
+		t.Errorf("expect the missing dst to fail the sync")
+	}
+	if _, err := os.Stat(filepath.Join(srcRoot, "a.txt")); err != nil {
+		t.Errorf("expect the src to be untouched, %+v", err)
+	}
+}
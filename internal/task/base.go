@@ -0,0 +1,75 @@
+package task
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/tache"
+	log "github.com/sirupsen/logrus"
+)
+
+// TaskExtension is embedded by the tasks created by users instead of tache.Base,
+// the creator is persisted with the task so that users can manage their own tasks
+type TaskExtension struct {
+	tache.Base
+	CreatorID uint   `json:"creator_id"`
+	Creator   string `json:"creator"`
+	creator   *model.User
+	// whether the creator is looked up, it's only needed by the recovered tasks
+	creatorLoaded bool
+}
+
+func (t *TaskExtension) SetCreator(creator *model.User) {
+	if creator == nil {
+		return
+	}
+	t.CreatorID = creator.ID
+	t.Creator = creator.Username
+	t.creator = creator
+}
+
+// InheritCreator is used by the tasks added by another task
+func (t *TaskExtension) InheritCreator(parent *TaskExtension) {
+	t.CreatorID = parent.CreatorID
+	t.Creator = parent.Creator
+	t.creator = parent.creator
+}
+
+// Ctx carries the creator as the user, so that the writes of the task are accounted to the creator
+func (t *TaskExtension) Ctx() context.Context {
+	ctx := t.Base.Ctx()
+	if t.creator == nil && t.CreatorID != 0 && !t.creatorLoaded {
+		// the task is recovered from the persisted data
+		t.creatorLoaded = true
+		user, err := op.GetUserById(t.CreatorID)
+		if err != nil {
+			log.Warnf("failed get creator of task [%s]: %+v", t.GetID(), err)
+		}
+		t.creator = user
+	}
+	if t.creator != nil {
+		ctx = context.WithValue(ctx, "user", t.creator)
+	}
+	return ctx
+}
+
+func (t *TaskExtension) GetCreatorID() uint {
+	return t.CreatorID
+}
+
+func (t *TaskExtension) GetCreator() string {
+	return t.Creator
+}
+
+// IsCreatedBy reports whether the user can manage the task
+func (t *TaskExtension) IsCreatedBy(user *model.User) bool {
+	return user != nil && (user.IsAdmin() || t.CreatorID == user.ID)
+}
+
+type TaskExtensionInfo interface {
+	tache.TaskWithInfo
+	GetCreatorID() uint
+	GetCreator() string
+	IsCreatedBy(user *model.User) bool
+}
@@ -0,0 +1,232 @@
+package trash
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// DirName is the hidden dir in the root of a storage where the removed objs are kept
+const DirName = ".alist_trash"
+
+// Enabled reports whether the removes in the storage go to the trash
+func Enabled(storage driver.Driver) bool {
+	return storage.GetStorage().Trash
+}
+
+// InTrash reports whether the actual path is in the trash of its storage
+func InTrash(actualPath string) bool {
+	return utils.IsSubPath("/"+DirName, actualPath)
+}
+
+func canMove(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Move, driver.MoveResult:
+		return true
+	}
+	return false
+}
+
+// withoutUser makes the objs moved in and out of the trash not limited or accounted by the quota,
+// they are still regarded as the remover's until purged
+func withoutUser(ctx context.Context) context.Context {
+	return context.WithValue(ctx, "user", (*model.User)(nil))
+}
+
+// Remove moves the obj into the trash of the storage,
+// or into the trash storage if the storage can't move
+func Remove(ctx context.Context, storage driver.Driver, actualPath string) error {
+	actualPath = utils.FixAndCleanPath(actualPath)
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		// same as op.Remove
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return errors.WithMessage(err, "failed to get object")
+	}
+	item := model.TrashItem{
+		Name:    obj.GetName(),
+		IsDir:   obj.IsDir(),
+		Size:    obj.GetSize(),
+		Path:    stdpath.Join(storage.GetStorage().MountPath, actualPath),
+		Deleted: time.Now(),
+	}
+	if user, _ := ctx.Value("user").(*model.User); user != nil {
+		item.UserID = user.ID
+	}
+	// every removed obj is kept in its own dir, so that the objs with the same name don't conflict
+	trashDir := stdpath.Join("/", DirName, time.Now().Format("20060102150405")+"_"+random.String(6))
+	innerCtx := withoutUser(ctx)
+	if canMove(storage) {
+		if err = op.MakeDir(innerCtx, storage, trashDir); err != nil {
+			return errors.WithMessage(err, "failed make trash dir")
+		}
+		if err = op.Move(innerCtx, storage, actualPath, trashDir); err != nil {
+			return errors.WithMessage(err, "failed move to trash")
+		}
+		item.TrashPath = stdpath.Join(storage.GetStorage().MountPath, trashDir, obj.GetName())
+	} else {
+		mountPath := setting.GetStr(conf.TrashStorage)
+		if mountPath == "" {
+			return errors.New("the storage can't move, set a trash storage to keep the removed objs")
+		}
+		trashStorage, err := op.GetStorageByMountPath(utils.FixAndCleanPath(mountPath))
+		if err != nil {
+			return errors.WithMessage(err, "failed get trash storage")
+		}
+		if err = op.MakeDir(innerCtx, trashStorage, trashDir); err != nil {
+			return errors.WithMessage(err, "failed make trash dir")
+		}
+		if err = copyTree(innerCtx, storage, actualPath, trashStorage, trashDir, obj); err != nil {
+			return errors.WithMessage(err, "failed copy to trash")
+		}
+		if err = op.Remove(innerCtx, storage, actualPath); err != nil {
+			return err
+		}
+		item.TrashPath = stdpath.Join(trashStorage.GetStorage().MountPath, trashDir, obj.GetName())
+	}
+	return db.CreateTrashItem(&item)
+}
+
+// copyTree copies the obj and its sub objs into the dir of another storage
+func copyTree(ctx context.Context, from driver.Driver, fromPath string, to driver.Driver, toDirPath string, obj model.Obj) error {
+	if utils.IsCanceled(ctx) {
+		return ctx.Err()
+	}
+	if obj.IsDir() {
+		toPath := stdpath.Join(toDirPath, obj.GetName())
+		if err := op.MakeDir(ctx, to, toPath); err != nil {
+			return err
+		}
+		objs, err := op.List(ctx, from, fromPath, model.ListArgs{})
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			if err = copyTree(ctx, from, stdpath.Join(fromPath, o.GetName()), to, toPath, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	link, _, err := op.Link(ctx, from, fromPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", fromPath)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", fromPath)
+	}
+	return op.Put(ctx, to, toDirPath, ss, nil, false)
+}
+
+func GetItems() ([]model.TrashItem, error) {
+	return db.GetTrashItems()
+}
+
+func GetItemById(id uint) (*model.TrashItem, error) {
+	return db.GetTrashItemById(id)
+}
+
+// Restore moves the obj back to the path it's removed from, it fails if the path is taken
+func Restore(ctx context.Context, item *model.TrashItem) error {
+	ctx = withoutUser(ctx)
+	src, srcPath, err := op.GetStorageAndActualPath(item.TrashPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get trash storage")
+	}
+	dst, dstPath, err := op.GetStorageAndActualPath(item.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if _, err = op.Get(ctx, dst, dstPath); err == nil {
+		return errors.Errorf("[%s] already exists", item.Path)
+	}
+	obj, err := op.Get(ctx, src, srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get the obj in trash")
+	}
+	dstDir := stdpath.Dir(dstPath)
+	if err = op.MakeDir(ctx, dst, dstDir); err != nil {
+		return err
+	}
+	if src == dst && canMove(src) {
+		err = op.Move(ctx, src, srcPath, dstDir)
+	} else if err = copyTree(ctx, src, srcPath, dst, dstDir, obj); err == nil {
+		err = op.Remove(ctx, src, srcPath)
+	}
+	if err != nil {
+		return errors.WithMessage(err, "failed restore")
+	}
+	if err = op.Remove(ctx, src, stdpath.Dir(srcPath)); err != nil {
+		log.Warnf("failed remove trash dir of [%s]: %+v", item.TrashPath, err)
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// Purge removes the obj in the trash permanently, the usage of the remover is released
+func Purge(ctx context.Context, item *model.TrashItem) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(item.TrashPath)
+	if errors.Is(err, errs.StorageNotFound) {
+		// nothing can be done if the storage is gone
+		return db.DeleteTrashItemById(item.ID)
+	}
+	if err != nil {
+		return err
+	}
+	ctx = withoutUser(ctx)
+	removerCtx := ctx
+	if remover, err := op.GetUserById(item.UserID); err == nil {
+		removerCtx = context.WithValue(ctx, "user", remover)
+	}
+	if err = op.Remove(removerCtx, storage, actualPath); err != nil {
+		return err
+	}
+	if err = op.Remove(ctx, storage, stdpath.Dir(actualPath)); err != nil {
+		log.Warnf("failed remove trash dir of [%s]: %+v", item.TrashPath, err)
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// Init purges the objs kept longer than the retention daily
+func Init() {
+	cron.NewCron(24 * time.Hour).Do(purgeExpired)
+}
+
+func purgeExpired() {
+	days := setting.GetInt(conf.TrashRetention, 30)
+	if days <= 0 {
+		return
+	}
+	items, err := db.GetTrashItemsBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed get expired trash items: %+v", err)
+		return
+	}
+	for i := range items {
+		if err = Purge(context.Background(), &items[i]); err != nil {
+			log.Errorf("failed purge [%s]: %+v", items[i].TrashPath, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package trash_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/trash"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestRemoveAndRestore(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	addition, _ := json.Marshal(map[string]string{"root_folder_path": root})
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/trash_test", Addition: string(addition), Trash: true})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	if err = fs.Remove(ctx, "/trash_test/a.txt"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	if _, err = os.Stat(filepath.Join(root, "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expect the file to be moved")
+	}
+	objs, err := fs.List(ctx, "/trash_test", &fs.ListArgs{Refresh: true})
+	if err != nil {
+		t.Fatalf("failed list: %+v", err)
+	}
+	if len(objs) != 0 {
+		t.Errorf("expect the trash to be hidden, got %d objs", len(objs))
+	}
+	items, err := trash.GetItems()
+	if err != nil || len(items) != 1 || items[0].Path != "/trash_test/a.txt" {
+		t.Fatalf("expect 1 trash item, got %+v, %+v", items, err)
+	}
+	// the trash is only accessed by the trash api for the non-admins
+	user := context.WithValue(ctx, "user", &model.User{ID: 2, Role: model.GENERAL, BasePath: "/"})
+	if _, err = fs.List(user, "/trash_test/"+trash.DirName, &fs.ListArgs{NoLog: true}); err == nil {
+		t.Errorf("expect the trash to be refused for the non-admins")
+	}
+	if err = trash.Restore(ctx, &items[0]); err != nil {
+		t.Fatalf("failed restore: %+v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "a.txt")); err != nil || string(data) != "a" {
+		t.Errorf("expect the file to be restored, got %s, %+v", data, err)
+	}
+	if items, _ = trash.GetItems(); len(items) != 0 {
+		t.Errorf("expect the trash item to be deleted after restore")
+	}
+}
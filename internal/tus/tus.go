@@ -0,0 +1,222 @@
+package tus
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// DirName is the dir under the temp dir where the chunks are staged,
+// it's kept when cleaning the temp dir on start so that the uploads survive a restart
+const DirName = "tus"
+
+// the uploads being written, a PATCH is refused while another one of the same upload is running
+var writing sync.Map
+
+func dir() string {
+	return filepath.Join(conf.Conf.TempDir, DirName)
+}
+
+func stagePath(id string) string {
+	return filepath.Join(dir(), id)
+}
+
+// Expiration is how long an upload is kept after it receives the last chunk
+func Expiration() time.Duration {
+	return time.Duration(setting.GetInt(conf.TusExpiration, 24)) * time.Hour
+}
+
+func ExpiresAt(u *model.TusUpload) time.Time {
+	return u.Updated.Add(Expiration())
+}
+
+// Init removes the expired uploads periodically
+func Init() {
+	if err := os.MkdirAll(dir(), 0o777); err != nil {
+		log.Errorf("failed create tus dir: %+v", err)
+	}
+	clean()
+	cron.NewCron(time.Hour).Do(clean)
+}
+
+func clean() {
+	uploads, err := db.GetIdleTusUploads(time.Now().Add(-Expiration()))
+	if err != nil {
+		log.Errorf("failed get idle uploads: %+v", err)
+		return
+	}
+	for _, u := range uploads {
+		if err = Terminate(u.ID); err != nil {
+			log.Errorf("failed remove expired upload [%s]: %+v", u.Path, err)
+		}
+	}
+	// the staged files without an upload are left by a crash
+	uploads, err = db.GetTusUploads()
+	if err != nil {
+		log.Errorf("failed get uploads: %+v", err)
+		return
+	}
+	ids := make(map[string]struct{}, len(uploads))
+	for _, u := range uploads {
+		// the staged file is lost, e.g. the temp dir is cleaned by hand, the client has to start over
+		if !utils.Exists(stagePath(u.ID)) {
+			if err = db.DeleteTusUploadById(u.ID); err != nil {
+				log.Errorf("failed remove upload [%s] without staged file: %+v", u.Path, err)
+			}
+			continue
+		}
+		ids[u.ID] = struct{}{}
+	}
+	entries, _ := os.ReadDir(dir())
+	for _, e := range entries {
+		if _, ok := ids[e.Name()]; !ok {
+			_ = os.Remove(stagePath(e.Name()))
+		}
+	}
+}
+
+// Create starts an upload of size bytes to the path for the user in ctx,
+// the permissions should be checked by the caller
+func Create(ctx context.Context, path string, size int64, metadata string) (*model.TusUpload, error) {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil {
+		return nil, errors.New("user is required")
+	}
+	if size < 0 {
+		return nil, errors.New("upload length can't be negative")
+	}
+	if err := op.CheckQuota(ctx, size, 1); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	u := &model.TusUpload{
+		ID:       random.String(32),
+		UserID:   user.ID,
+		Path:     utils.FixAndCleanPath(path),
+		Size:     size,
+		Metadata: metadata,
+		Created:  now,
+		Updated:  now,
+	}
+	if err := os.MkdirAll(dir(), 0o777); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.Create(stagePath(u.ID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	_ = f.Close()
+	if err = db.CreateTusUpload(u); err != nil {
+		_ = os.Remove(stagePath(u.ID))
+		return nil, err
+	}
+	return u, nil
+}
+
+// Get returns the upload if it's not expired and its staged file is there
+func Get(id string) (*model.TusUpload, error) {
+	u, err := db.GetTusUploadById(id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(ExpiresAt(u)) || !utils.Exists(stagePath(id)) {
+		_ = Terminate(id)
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	return u, nil
+}
+
+// Write appends the chunk at the offset, the received bytes are kept even if the chunk is interrupted,
+// so that the client can resume from the new offset. The file is handed to an upload task once all bytes are received.
+func Write(ctx context.Context, u *model.TusUpload, offset int64, r io.Reader) (task.TaskExtensionInfo, error) {
+	if _, loaded := writing.LoadOrStore(u.ID, struct{}{}); loaded {
+		return nil, errors.WithStack(errs.UploadInProgress)
+	}
+	defer writing.Delete(u.ID)
+	// reload in case another chunk is written after u is loaded
+	cur, err := db.GetTusUploadById(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	*u = *cur
+	if offset != u.Offset {
+		return nil, errors.WithStack(errs.UploadOffsetMismatch)
+	}
+	if err = write(u, r); err != nil {
+		return nil, err
+	}
+	if u.Offset < u.Size {
+		return nil, nil
+	}
+	return complete(ctx, u)
+}
+
+func write(u *model.TusUpload, r io.Reader) error {
+	f, err := os.OpenFile(stagePath(u.ID), os.O_WRONLY, 0o666)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if _, err = f.Seek(u.Offset, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	n, copyErr := utils.CopyWithBuffer(f, io.LimitReader(r, u.Size-u.Offset))
+	u.Offset += n
+	u.Updated = time.Now()
+	if err = db.UpdateTusUploadOffset(u); err != nil {
+		return err
+	}
+	return errors.WithStack(copyErr)
+}
+
+// complete hands the staged file to an upload task, the file is removed once the task finishes
+func complete(ctx context.Context, u *model.TusUpload) (task.TaskExtensionInfo, error) {
+	f, err := os.Open(stagePath(u.ID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dstDir, name := stdpath.Split(u.Path)
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     u.Size,
+			Modified: time.Now(),
+		},
+		Mimetype: utils.GetMimeType(name),
+	}
+	s.SetTmpFile(f)
+	t, err := fs.PutAsTask(ctx, dstDir, s)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	// the staged file belongs to the task now
+	return t, db.DeleteTusUploadById(u.ID)
+}
+
+// Terminate removes the upload and its staged file
+func Terminate(id string) error {
+	if err := os.Remove(stagePath(id)); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return db.DeleteTusUploadById(id)
+}
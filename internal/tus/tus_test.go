@@ -0,0 +1,84 @@
+package tus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestWriteResume(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 1})
+	u, err := Create(ctx, "/a/b.txt", 10, "")
+	if err != nil {
+		t.Fatalf("failed create upload: %+v", err)
+	}
+	if _, err = Write(ctx, u, 0, strings.NewReader("hello")); err != nil {
+		t.Fatalf("failed write: %+v", err)
+	}
+	// the upload is resumed by id, e.g. after a restart
+	u, err = Get(u.ID)
+	if err != nil {
+		t.Fatalf("failed get upload: %+v", err)
+	}
+	if u.Offset != 5 {
+		t.Fatalf("expect offset 5, got %d", u.Offset)
+	}
+	if _, err = Write(ctx, u, 0, strings.NewReader("hello")); !errors.Is(err, errs.UploadOffsetMismatch) {
+		t.Errorf("expect offset mismatch, got %+v", err)
+	}
+	// not completed yet, so no upload task is needed
+	if _, err = Write(ctx, u, 5, strings.NewReader("worl")); err != nil {
+		t.Fatalf("failed write: %+v", err)
+	}
+	data, err := os.ReadFile(stagePath(u.ID))
+	if err != nil {
+		t.Fatalf("failed read staged file: %+v", err)
+	}
+	if string(data) != "helloworl" || u.Offset != 9 {
+		t.Errorf("expect helloworl at offset 9, got %s at %d", data, u.Offset)
+	}
+	if err = Terminate(u.ID); err != nil {
+		t.Fatalf("failed terminate: %+v", err)
+	}
+	if _, err = os.Stat(stagePath(u.ID)); !os.IsNotExist(err) {
+		t.Errorf("expect the staged file to be removed")
+	}
+}
+
+func TestStagedFileLost(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 1})
+	u, err := Create(ctx, "/a/lost.txt", 10, "")
+	if err != nil {
+		t.Fatalf("failed create upload: %+v", err)
+	}
+	if err = os.Remove(stagePath(u.ID)); err != nil {
+		t.Fatal(err)
+	}
+	// the client starts over instead of failing on every chunk
+	if _, err = Get(u.ID); !errs.IsObjectNotFound(err) {
+		t.Errorf("expect the upload without staged file to be not found, got %+v", err)
+	}
+	if _, err = db.GetTusUploadById(u.ID); err == nil {
+		t.Errorf("expect the upload without staged file to be removed")
+	}
+}
@@ -0,0 +1,154 @@
+// Package doctext extracts the plain text of the documents to be indexed
+package doctext
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+var ErrNotSupported = errors.New("the document type is not supported")
+
+// Limits bounds the extraction of the documents, the zero values mean no limit
+type Limits struct {
+	// the max bytes read from the document, including the ones decompressed from the zipped documents
+	MaxSize int64
+	// the max bytes of the text, the rest of the document is skipped
+	MaxText int
+}
+
+func (l Limits) reader(r io.Reader) *io.LimitedReader {
+	n := l.MaxSize
+	if n <= 0 {
+		n = math.MaxInt64
+	}
+	return &io.LimitedReader{R: r, N: n}
+}
+
+func (l Limits) full(n int) bool {
+	return l.MaxText > 0 && n >= l.MaxText
+}
+
+type extractor func(r io.ReaderAt, size int64, limits Limits) (string, error)
+
+var extractors = map[string]extractor{
+	".txt":      extractPlain,
+	".md":       extractPlain,
+	".markdown": extractPlain,
+	".csv":      extractPlain,
+	".log":      extractPlain,
+	".pdf":      extractPDF,
+	".docx":     extractZippedXML("word/document.xml"),
+	".odt":      extractZippedXML("content.xml"),
+}
+
+// Supported reports whether the text of the file can be extracted by the name
+func Supported(name string) bool {
+	_, ok := extractors[strings.ToLower(path.Ext(name))]
+	return ok
+}
+
+// Extract returns the plain text of the document, the type is decided by the extension of the name,
+// the text is truncated by the limits
+func Extract(name string, r io.ReaderAt, size int64, limits Limits) (string, error) {
+	e, ok := extractors[strings.ToLower(path.Ext(name))]
+	if !ok {
+		return "", ErrNotSupported
+	}
+	text, err := e(r, size, limits)
+	if err != nil {
+		return "", err
+	}
+	if limits.MaxText > 0 && len(text) > limits.MaxText {
+		text = text[:limits.MaxText]
+	}
+	return strings.ToValidUTF8(text, ""), nil
+}
+
+func extractPlain(r io.ReaderAt, size int64, limits Limits) (string, error) {
+	if limits.MaxText > 0 && size > int64(limits.MaxText) {
+		size = int64(limits.MaxText)
+	}
+	var buf strings.Builder
+	_, err := io.Copy(&buf, limits.reader(io.NewSectionReader(r, 0, size)))
+	return buf.String(), err
+}
+
+func extractPDF(r io.ReaderAt, size int64, limits Limits) (text string, err error) {
+	// the pdf library panics on some malformed files
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("malformed pdf: %v", e)
+		}
+	}()
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if limits.MaxText > 0 {
+		plain = io.LimitReader(plain, int64(limits.MaxText))
+	}
+	_, err = buf.ReadFrom(plain)
+	return buf.String(), err
+}
+
+// extractZippedXML extracts the text of the xml file in the zip, such as the docx and odt,
+// the paragraphs are separated by the line breaks, the xml is decompressed up to the max size
+func extractZippedXML(name string) extractor {
+	return func(r io.ReaderAt, size int64, limits Limits) (string, error) {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return "", err
+		}
+		f, err := zr.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		var buf strings.Builder
+		lr := limits.reader(f)
+		decoder := xml.NewDecoder(lr)
+		for !limits.full(buf.Len()) {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if lr.N <= 0 {
+					// the xml is cut by the max size, take the text so far
+					break
+				}
+				return "", err
+			}
+			switch t := token.(type) {
+			case xml.CharData:
+				buf.Write(t)
+			case xml.EndElement:
+				switch t.Name.Local {
+				// paragraphs and headings of docx and odt
+				case "p", "h":
+					buf.WriteByte('\n')
+				}
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "tab", "br", "s", "line-break":
+					buf.WriteByte(' ')
+				}
+			}
+		}
+		return buf.String(), nil
+	}
+}
@@ -0,0 +1,81 @@
+package doctext
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func zipped(t *testing.T, name, content string) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestExtract(t *testing.T) {
+	docx := zipped(t, "word/document.xml", `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Hello</w:t></w:r><w:r><w:tab/><w:t>world</w:t></w:r></w:p><w:p><w:r><w:t>Second</w:t></w:r></w:p>
+</w:body></w:document>`)
+	odt := zipped(t, "content.xml", `<?xml version="1.0"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:text><text:h>Title</text:h><text:p>Some<text:s/>text</text:p></office:text></office:body></office:document-content>`)
+	md := bytes.NewReader([]byte("# Readme\n\nplain text"))
+	tests := []struct {
+		name   string
+		r      *bytes.Reader
+		expect []string
+	}{
+		{"a.DOCX", docx, []string{"Hello world\n", "Second\n"}},
+		{"a.odt", odt, []string{"Title\n", "Some text\n"}},
+		{"a.md", md, []string{"# Readme", "plain text"}},
+	}
+	for _, tt := range tests {
+		if !Supported(tt.name) {
+			t.Errorf("expect %s to be supported", tt.name)
+		}
+		text, err := Extract(tt.name, tt.r, tt.r.Size(), Limits{})
+		if err != nil {
+			t.Fatalf("failed extract %s: %+v", tt.name, err)
+		}
+		for _, e := range tt.expect {
+			if !strings.Contains(text, e) {
+				t.Errorf("expect %q in the text of %s, got %q", e, tt.name, text)
+			}
+		}
+	}
+	if _, err := Extract("a.mp4", md, md.Size(), Limits{}); err != ErrNotSupported {
+		t.Errorf("expect the video not to be supported, got %v", err)
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	docx := zipped(t, "word/document.xml", `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`+
+		strings.Repeat(`<w:p><w:r><w:t>Hello world</w:t></w:r></w:p>`, 10000)+`</w:body></w:document>`)
+	text, err := Extract("a.docx", docx, docx.Size(), Limits{MaxSize: 1 << 10})
+	if err != nil {
+		t.Fatalf("failed extract the docx cut by the max size: %+v", err)
+	}
+	if !strings.Contains(text, "Hello world\n") || len(text) > 1<<10 {
+		t.Errorf("expect the text decompressed up to the max size, got %d bytes", len(text))
+	}
+	text, err = Extract("a.docx", docx, docx.Size(), Limits{MaxText: 100})
+	if err != nil {
+		t.Fatalf("failed extract the docx: %+v", err)
+	}
+	if len(text) != 100 {
+		t.Errorf("expect the text truncated to 100 bytes, got %d", len(text))
+	}
+}
@@ -5,24 +5,42 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 )
 
 var SecretKey []byte
 
+// UserClaims is the claims of the access token, the ID is the id of the session
 type UserClaims struct {
 	Username string `json:"username"`
 	PwdTS    int64  `json:"pwd_ts"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(user *model.User) (tokenString string, err error) {
+type TokenResp struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// seconds before the token is expired
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+func accessTokenExpiresIn() time.Duration {
+	if conf.Conf.AccessTokenExpiresIn > 0 {
+		return time.Duration(conf.Conf.AccessTokenExpiresIn) * time.Minute
+	}
+	return time.Duration(conf.Conf.TokenExpiresIn) * time.Hour
+}
+
+func GenerateToken(user *model.User, sessionID string) (tokenString string, err error) {
 	claim := UserClaims{
 		Username: user.Username,
 		PwdTS:    user.PwdTS,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(conf.Conf.TokenExpiresIn) * time.Hour)),
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenExpiresIn())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		}}
@@ -31,6 +49,43 @@ func GenerateToken(user *model.User) (tokenString string, err error) {
 	return tokenString, err
 }
 
+// Login creates a session of the request for the user, and generates the tokens of it
+func Login(c *gin.Context, user *model.User) (*TokenResp, error) {
+	session, refreshToken, err := op.CreateSession(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		return nil, err
+	}
+	return tokenResp(user, session.ID, refreshToken)
+}
+
+// Refresh rotates the refresh token and generates a new access token of the session
+func Refresh(c *gin.Context, refreshToken string) (*TokenResp, error) {
+	session, refreshToken, err := op.RefreshSession(refreshToken, c.ClientIP())
+	if err != nil {
+		return nil, err
+	}
+	user, err := op.GetUserById(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errors.New("current user is disabled")
+	}
+	return tokenResp(user, session.ID, refreshToken)
+}
+
+func tokenResp(user *model.User, sessionID, refreshToken string) (*TokenResp, error) {
+	token, err := GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResp{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenExpiresIn().Seconds()),
+	}, nil
+}
+
 func ParseToken(tokenString string) (*UserClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return SecretKey, nil
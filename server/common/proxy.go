@@ -19,6 +19,7 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// Proxy serves the file of the link, the rate is limited by the download limiter of the request context
 func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.Obj) error {
 	if link.MFile != nil {
 		defer link.MFile.Close()
@@ -31,7 +32,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		if _, ok := mFile.(*os.File); !ok {
 			mFile = &stream.RateLimitFile{
 				File:    mFile,
-				Limiter: stream.ServerDownloadLimit,
+				Limiter: stream.DownloadLimiter(r.Context()),
 				Ctx:     r.Context(),
 			}
 		}
@@ -41,7 +42,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		attachHeader(w, file)
 		return net.ServeHTTP(w, r, file.GetName(), file.ModTime(), file.GetSize(), &stream.RateLimitRangeReadCloser{
 			RangeReadCloserIF: link.RangeReadCloser,
-			Limiter:           stream.ServerDownloadLimit,
+			Limiter:           stream.DownloadLimiter(r.Context()),
 		})
 	} else if link.Concurrency != 0 || link.PartSize != 0 {
 		attachHeader(w, file)
@@ -67,7 +68,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		}
 		return net.ServeHTTP(w, r, file.GetName(), file.ModTime(), file.GetSize(), &stream.RateLimitRangeReadCloser{
 			RangeReadCloserIF: &model.RangeReadCloser{RangeReader: rangeReader},
-			Limiter:           stream.ServerDownloadLimit,
+			Limiter:           stream.DownloadLimiter(r.Context()),
 		})
 	} else {
 		//transparent proxy
@@ -85,7 +86,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		}
 		_, err = utils.CopyWithBuffer(w, &stream.RateLimitReader{
 			Reader:  res.Body,
-			Limiter: stream.ServerDownloadLimit,
+			Limiter: stream.DownloadLimiter(r.Context()),
 			Ctx:     r.Context(),
 		})
 		return err
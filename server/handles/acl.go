@@ -0,0 +1,150 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListGroups(c *gin.Context) {
+	groups, err := op.GetGroups()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, groups)
+}
+
+func GetGroup(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	group, err := op.GetGroupById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, group)
+}
+
+func CreateGroup(c *gin.Context) {
+	var req model.Group
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	if err := op.CreateGroup(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{"id": req.ID})
+}
+
+func UpdateGroup(c *gin.Context) {
+	var req model.Group
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := op.GetGroupById(req.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if err := op.UpdateGroup(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// DeleteGroup deletes the group and its acl rules,
+// the id is left in the users' groups but nothing applies to it anymore
+func DeleteGroup(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteGroupById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListAclRules(c *gin.Context) {
+	rules, err := op.GetAclRules()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, rules)
+}
+
+func GetAclRule(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	rule, err := op.GetAclRuleById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, rule)
+}
+
+func CreateAclRule(c *gin.Context) {
+	var req model.AclRule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	if err := op.CreateAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{"id": req.ID})
+}
+
+func UpdateAclRule(c *gin.Context) {
+	var req model.AclRule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := op.GetAclRuleById(req.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if err := op.UpdateAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteAclRule(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteAclRuleById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,94 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListApiTokens(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	tokens, err := op.GetApiTokensByUser(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+type CreateApiTokenReq struct {
+	Name       string     `json:"name" binding:"required"`
+	Permission int32      `json:"permission"`
+	BasePath   string     `json:"base_path"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type CreateApiTokenResp struct {
+	model.ApiToken
+	// only shown once
+	Token string `json:"token"`
+	// the access key id of the s3 server is the key id
+	S3Secret string `json:"s3_secret"`
+}
+
+func CreateApiToken(c *gin.Context) {
+	var req CreateApiTokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest can't create api tokens", 403)
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		common.ErrorStrResp(c, "The expiry is in the past", 400)
+		return
+	}
+	token := model.ApiToken{
+		Name:       req.Name,
+		Permission: req.Permission,
+		BasePath:   req.BasePath,
+		ExpiresAt:  req.ExpiresAt,
+	}
+	tokenStr, err := op.CreateApiToken(user, &token)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s3Secret, err := op.ApiTokenS3Secret(&token)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, CreateApiTokenResp{
+		ApiToken: token,
+		Token:    tokenStr,
+		S3Secret: s3Secret,
+	})
+}
+
+// DeleteApiToken revokes the token of the current user
+func DeleteApiToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	token, err := op.GetApiTokenById(uint(id))
+	if err != nil || token.UserID != user.ID {
+		common.ErrorStrResp(c, "api token not found", 404)
+		return
+	}
+	if err = op.DeleteApiTokenById(token.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
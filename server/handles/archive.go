@@ -0,0 +1,383 @@
+package handles
+
+import (
+	"fmt"
+	"net/url"
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type ArchiveMetaReq struct {
+	Path        string `json:"path" form:"path"`
+	Password    string `json:"password" form:"password"`
+	ArchivePass string `json:"archive_pass" form:"archive_pass"`
+	Refresh     bool   `json:"refresh" form:"refresh"`
+}
+
+type ArchiveContentResp struct {
+	ObjResp
+	Children []ArchiveContentResp `json:"children"`
+}
+
+type ArchiveMetaResp struct {
+	Comment   string               `json:"comment"`
+	Encrypted bool                 `json:"encrypted"`
+	Content   []ArchiveContentResp `json:"content"`
+	Sort      *model.Sort          `json:"sort,omitempty"`
+	RawURL    string               `json:"raw_url"`
+	Sign      string               `json:"sign"`
+}
+
+func toArchiveContentResp(tree []model.ObjTree) []ArchiveContentResp {
+	if tree == nil {
+		return nil
+	}
+	return utils.MustSliceConvert(tree, func(obj model.ObjTree) ArchiveContentResp {
+		return ArchiveContentResp{
+			ObjResp: ObjResp{
+				Name:        obj.GetName(),
+				Size:        obj.GetSize(),
+				IsDir:       obj.IsDir(),
+				Modified:    obj.ModTime(),
+				Created:     obj.CreateTime(),
+				HashInfoStr: obj.GetHash().String(),
+				HashInfo:    obj.GetHash().Export(),
+				Type:        utils.GetObjType(obj.GetName(), obj.IsDir()),
+			},
+			Children: toArchiveContentResp(obj.GetChildren()),
+		}
+	})
+}
+
+// checkArchiveAccess joins the path with the base path of the user and checks the permissions,
+// the response is written if it returns false
+func checkArchiveAccess(c *gin.Context, path, password string) (string, *model.Meta, bool) {
+	user := c.MustGet("user").(*model.User)
+	if !user.CanReadArchives() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return "", nil, false
+	}
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return "", nil, false
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return "", nil, false
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccess(user, meta, reqPath, password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return "", nil, false
+	}
+	if !op.AclAllowed(user, reqPath, model.AclRead, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return "", nil, false
+	}
+	return reqPath, meta, true
+}
+
+func archiveErrorResp(c *gin.Context, err error) {
+	if errs.IsWrongArchivePassword(err) {
+		common.ErrorResp(c, err, 202)
+		return
+	}
+	common.ErrorResp(c, err, 500)
+}
+
+func FsArchiveMeta(c *gin.Context) {
+	var req ArchiveMetaReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath, meta, ok := checkArchiveAccess(c, req.Path, req.Password)
+	if !ok {
+		return
+	}
+	archiveArgs := model.ArchiveArgs{
+		LinkArgs: model.LinkArgs{
+			Header:  c.Request.Header,
+			Type:    c.Query("type"),
+			HttpReq: c.Request,
+		},
+		Password: req.ArchivePass,
+	}
+	ret, err := fs.ArchiveMeta(c, reqPath, model.ArchiveMetaArgs{
+		ArchiveArgs: archiveArgs,
+		Refresh:     req.Refresh,
+	})
+	if err != nil {
+		archiveErrorResp(c, err)
+		return
+	}
+	s := ""
+	if isEncrypt(meta, reqPath) || setting.GetBool(conf.SignAll) {
+		s = sign.Sign(reqPath)
+	}
+	api := "/ae"
+	if ret.DriverProviding {
+		api = "/ad"
+	}
+	common.SuccessResp(c, ArchiveMetaResp{
+		Comment:   ret.GetComment(),
+		Encrypted: ret.IsEncrypted(),
+		Content:   toArchiveContentResp(ret.GetTree()),
+		Sort:      ret.Sort,
+		RawURL:    fmt.Sprintf("%s%s%s", common.GetApiUrl(c.Request), api, utils.EncodePath(reqPath, true)),
+		Sign:      s,
+	})
+}
+
+type ArchiveListReq struct {
+	ArchiveMetaReq
+	model.PageReq
+	InnerPath string `json:"inner_path" form:"inner_path"`
+}
+
+type ArchiveListResp struct {
+	Content []ObjResp `json:"content"`
+	Total   int64     `json:"total"`
+}
+
+func FsArchiveList(c *gin.Context) {
+	var req ArchiveListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	reqPath, _, ok := checkArchiveAccess(c, req.Path, req.Password)
+	if !ok {
+		return
+	}
+	objs, err := fs.ArchiveList(c, reqPath, model.ArchiveListArgs{
+		ArchiveInnerArgs: model.ArchiveInnerArgs{
+			ArchiveArgs: model.ArchiveArgs{
+				LinkArgs: model.LinkArgs{
+					Header:  c.Request.Header,
+					Type:    c.Query("type"),
+					HttpReq: c.Request,
+				},
+				Password: req.ArchivePass,
+			},
+			InnerPath: utils.FixAndCleanPath(req.InnerPath),
+		},
+		Refresh: req.Refresh,
+	})
+	if err != nil {
+		archiveErrorResp(c, err)
+		return
+	}
+	total, objs := pagination(objs, &req.PageReq)
+	common.SuccessResp(c, ArchiveListResp{
+		Content: utils.MustSliceConvert(objs, func(obj model.Obj) ObjResp {
+			return ObjResp{
+				Name:        obj.GetName(),
+				Size:        obj.GetSize(),
+				IsDir:       obj.IsDir(),
+				Modified:    obj.ModTime(),
+				Created:     obj.CreateTime(),
+				HashInfoStr: obj.GetHash().String(),
+				HashInfo:    obj.GetHash().Export(),
+				Type:        utils.GetObjType(obj.GetName(), obj.IsDir()),
+			}
+		}),
+		Total: int64(total),
+	})
+}
+
+type ArchiveDecompressReq struct {
+	SrcDir        string `json:"src_dir" form:"src_dir"`
+	Name          string `json:"name" form:"name"`
+	DstDir        string `json:"dst_dir" form:"dst_dir"`
+	Password      string `json:"password" form:"password"`
+	ArchivePass   string `json:"archive_pass" form:"archive_pass"`
+	InnerPath     string `json:"inner_path" form:"inner_path"`
+	CacheFull     bool   `json:"cache_full" form:"cache_full"`
+	PutIntoNewDir bool   `json:"put_into_new_dir" form:"put_into_new_dir"`
+}
+
+func FsArchiveDecompress(c *gin.Context) {
+	var req ArchiveDecompressReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if !user.CanDecompress() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	srcPath, err := user.JoinPath(stdpath.Join(req.SrcDir, req.Name))
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	// the archive is read as by FsGet
+	srcMeta, err := op.GetNearestMeta(srcPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanAccess(user, srcMeta, srcPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if !op.AclAllowed(user, srcPath, model.AclRead, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !user.CanWrite() {
+		meta, err := op.GetNearestMeta(dstDir)
+		if err != nil {
+			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				common.ErrorResp(c, err, 500, true)
+				return
+			}
+		}
+		if !common.CanWrite(meta, dstDir) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	if !checkLock(c, true, dstDir) {
+		return
+	}
+	t, err := fs.ArchiveDecompress(c, srcPath, dstDir, model.ArchiveDecompressArgs{
+		ArchiveInnerArgs: model.ArchiveInnerArgs{
+			ArchiveArgs: model.ArchiveArgs{
+				LinkArgs: model.LinkArgs{
+					Header:  c.Request.Header,
+					Type:    c.Query("type"),
+					HttpReq: c.Request,
+				},
+				Password: req.ArchivePass,
+			},
+			InnerPath: utils.FixAndCleanPath(req.InnerPath),
+		},
+		CacheFull:     req.CacheFull,
+		PutIntoNewDir: req.PutIntoNewDir,
+	})
+	if err != nil {
+		archiveErrorResp(c, err)
+		return
+	}
+	var addedTasks []task.TaskExtensionInfo
+	if t != nil {
+		addedTasks = append(addedTasks, t)
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos(addedTasks),
+	})
+}
+
+func archiveInnerArgs(c *gin.Context) model.ArchiveInnerArgs {
+	return model.ArchiveInnerArgs{
+		ArchiveArgs: model.ArchiveArgs{
+			LinkArgs: model.LinkArgs{
+				IP:      c.ClientIP(),
+				Header:  c.Request.Header,
+				Type:    c.Query("type"),
+				HttpReq: c.Request,
+			},
+			Password: c.Query("pass"),
+		},
+		InnerPath: utils.FixAndCleanPath(c.Query("inner")),
+	}
+}
+
+// ArchiveDown serves a file in the archive with the link provided by the driver,
+// or falls back to ArchiveInternalExtract if the driver can't extract
+func ArchiveDown(c *gin.Context) {
+	archiveRawPath := c.MustGet("path").(string)
+	args := archiveInnerArgs(c)
+	link, file, err := fs.ArchiveDriverExtract(c, archiveRawPath, args)
+	if errors.Is(err, errs.DriverExtractNotSupported) {
+		ArchiveInternalExtract(c)
+		return
+	}
+	if err != nil {
+		archiveErrorResp(c, err)
+		return
+	}
+	storage, err := fs.GetStorage(archiveRawPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if link.URL != "" && !canProxy(storage, file.GetName()) {
+		c.Header("Referrer-Policy", "no-referrer")
+		c.Header("Cache-Control", "max-age=0, no-cache, no-store, must-revalidate")
+		c.Redirect(302, link.URL)
+		return
+	}
+	limitDownload(c, storage)
+	err = common.Proxy(c.Writer, c.Request, link, file)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+	}
+}
+
+// ArchiveInternalExtract serves a file in the archive extracted by the internal archive tools
+func ArchiveInternalExtract(c *gin.Context) {
+	archiveRawPath := c.MustGet("path").(string)
+	args := archiveInnerArgs(c)
+	rc, size, err := fs.ArchiveInternalExtract(c, archiveRawPath, args)
+	if err != nil {
+		archiveErrorResp(c, err)
+		return
+	}
+	defer func() {
+		if err := rc.Close(); err != nil {
+			log.Errorf("failed to close file streamer, %v", err)
+		}
+	}()
+	fileName := stdpath.Base(args.InnerPath)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fileName, url.PathEscape(fileName)))
+	c.Header("Content-Type", utils.GetMimeType(fileName))
+	if size >= 0 {
+		c.Header("Content-Length", strconv.FormatInt(size, 10))
+	}
+	c.Status(200)
+	if c.Request.Method == "HEAD" {
+		return
+	}
+	if storage, err := fs.GetStorage(archiveRawPath, &fs.GetStoragesArgs{}); err == nil {
+		limitDownload(c, storage)
+	}
+	_, err = utils.CopyWithBuffer(c.Writer, &stream.RateLimitReader{
+		Reader:  rc,
+		Limiter: stream.DownloadLimiter(c.Request.Context()),
+		Ctx:     c,
+	})
+	if err != nil {
+		log.Warnf("failed to write extracted file [%s]%s: %v", archiveRawPath, args.InnerPath, err)
+	}
+}
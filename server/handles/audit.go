@@ -0,0 +1,73 @@
+package handles
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+func ListAuditLogs(c *gin.Context) {
+	var req model.AuditLogReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	logs, total, err := db.GetAuditLogs(&req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
+
+// ExportAuditLogs writes the logs matched by the filters as json lines, the earliest first
+func ExportAuditLogs(c *gin.Context) {
+	var req model.AuditLogReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	c.Status(200)
+	enc := utils.Json.NewEncoder(c.Writer)
+	err := db.WalkAuditLogs(&req, func(l *model.AuditLog) error {
+		return enc.Encode(l)
+	})
+	if err != nil {
+		// the response has been started, the error can only be logged
+		log.Errorf("failed export audit logs: %+v", err)
+	}
+}
+
+// the subjects of the admin actions by id are looked up before the actions, they may be gone after
+
+func storageSubject(id uint) string {
+	if storage, err := db.GetStorageById(id); err == nil {
+		return storage.MountPath
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func userSubject(id uint) string {
+	if user, err := db.GetUserById(id); err == nil {
+		return user.Username
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func metaSubject(id uint) string {
+	if meta, err := db.GetMetaById(id); err == nil {
+		return meta.Path
+	}
+	return fmt.Sprintf("#%d", id)
+}
@@ -3,15 +3,13 @@ package handles
 import (
 	"bytes"
 	"encoding/base64"
-	"fmt"
 	"image/png"
 	"time"
 
 	"github.com/OpenListTeam/go-cache"
-	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/notify"
 	"github.com/alist-org/alist/v3/internal/op"
-	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pquerna/otp/totp"
@@ -64,18 +62,14 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	if err != nil {
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
-		if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnCopySucceeded) {
-			go op.Notify(fmt.Sprintf("%s登录Alist失败:", ip), err.Error())
-		}
+		notify.Send(notify.EventLoginFailed, notify.Data{"IP": ip, "Username": req.Username, "Error": err.Error()})
 		return
 	}
 	// validate password hash
 	if err := user.ValidatePwdStaticHash(req.Password); err != nil {
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
-		if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnCopySucceeded) {
-			go op.Notify(fmt.Sprintf("%s登录Alist失败:", ip), err.Error())
-		}
+		notify.Send(notify.EventLoginFailed, notify.Data{"IP": ip, "Username": req.Username, "Error": err.Error()})
 		return
 	}
 	// check 2FA
@@ -83,28 +77,25 @@ func loginHash(c *gin.Context, req *LoginReq) {
 		if !totp.Validate(req.OtpCode, user.OtpSecret) {
 			common.ErrorStrResp(c, "Invalid 2FA code", 402)
 			loginCache.Set(ip, count+1)
-			if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnCopySucceeded) {
-				go op.Notify(fmt.Sprintf("%s登录Alist失败:", ip), "Invalid 2FA code")
-			}
+			notify.Send(notify.EventLoginFailed, notify.Data{"IP": ip, "Username": req.Username, "Error": "Invalid 2FA code"})
 			return
 		}
 	}
 	// generate token
-	token, err := common.GenerateToken(user)
+	tokens, err := common.Login(c, user)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
 	}
-	common.SuccessResp(c, gin.H{"token": token})
-	if setting.GetBool(conf.NotifyEnabled) && setting.GetBool(conf.NotifyOnCopySucceeded) {
-		go op.Notify("Alist登录成功", fmt.Sprintf("登录IP:%s", ip))
-	}
+	common.SuccessResp(c, tokens)
+	notify.Send(notify.EventLogin, notify.Data{"IP": ip, "Username": user.Username})
 	loginCache.Del(ip)
 }
 
 type UserResp struct {
 	model.User
-	Otp bool `json:"otp"`
+	Otp   bool             `json:"otp"`
+	Usage *model.UserUsage `json:"usage,omitempty"`
 }
 
 // CurrentUser get current user by token
@@ -118,6 +109,14 @@ func CurrentUser(c *gin.Context) {
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
+	if !user.IsGuest() {
+		usage, err := op.GetUserUsage(user.ID)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		userResp.Usage = usage
+	}
 	common.SuccessResp(c, userResp)
 }
 
@@ -135,9 +134,16 @@ func UpdateCurrent(c *gin.Context) {
 	user.SsoID = req.SsoID
 	if err := op.UpdateUser(user); err != nil {
 		common.ErrorResp(c, err, 500)
-	} else {
-		common.SuccessResp(c)
+		return
+	}
+	// the refresh tokens outlive the password otherwise
+	if req.Password != "" {
+		if err := op.DeleteSessionsByUser(user.ID); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
 	}
+	common.SuccessResp(c)
 }
 
 func Generate2FA(c *gin.Context) {
@@ -1,17 +1,21 @@
 package handles
 
 import (
+	"context"
 	"fmt"
 	"io"
 	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
@@ -30,12 +34,13 @@ func Down(c *gin.Context) {
 		Proxy(c)
 		return
 	} else {
-		link, _, err := fs.Link(c, rawPath, model.LinkArgs{
+		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
 			IP:      c.ClientIP(),
 			Header:  c.Request.Header,
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
 		})
+		audit.Download(c, rawPath, file, err)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -91,6 +96,7 @@ func Proxy(c *gin.Context) {
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
 		})
+		audit.Download(c, rawPath, file, err)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -106,6 +112,7 @@ func Proxy(c *gin.Context) {
 				return
 			}
 		}
+		limitDownload(c, storage)
 		err = common.Proxy(c.Writer, c.Request, link, file)
 		if err != nil {
 			common.ErrorResp(c, err, 500, true)
@@ -117,6 +124,18 @@ func Proxy(c *gin.Context) {
 	}
 }
 
+// limitDownload sets the download limiter of the request, the signed links aren't bound to the users,
+// so the downloads without a user are limited as the guest's
+func limitDownload(c *gin.Context, storage driver.Driver) {
+	ctx := context.Context(c)
+	if _, ok := c.Get("user"); !ok {
+		if guest, err := op.GetGuest(); err == nil {
+			ctx = context.WithValue(ctx, "user", guest)
+		}
+	}
+	c.Request = c.Request.WithContext(stream.WithDownloadLimiter(c.Request.Context(), op.DownloadLimiter(ctx, storage)))
+}
+
 // TODO need optimize
 // when can be proxy?
 // 1. text file
@@ -0,0 +1,58 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/dedup"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ScanDuplicatesReq struct {
+	Paths []string `json:"paths" binding:"required"`
+}
+
+func ScanDuplicates(c *gin.Context) {
+	var req ScanDuplicatesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, err := dedup.Scan(c, req.Paths)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{"task": getTaskInfo(t)})
+}
+
+func ListDuplicates(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	sets, total, err := dedup.GetSets(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: sets,
+		Total:   total,
+	})
+}
+
+// ResolveDuplicates keeps one file of a duplicate set and deletes or links the others
+func ResolveDuplicates(c *gin.Context) {
+	var req model.DuplicateResolveReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := dedup.Resolve(c, req, common.GetApiUrl(c.Request)); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -2,6 +2,7 @@ package handles
 
 import (
 	"fmt"
+	stdpath "path"
 	"regexp"
 
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -53,6 +54,9 @@ func FsBatchRename(c *gin.Context) {
 			continue
 		}
 		filePath := fmt.Sprintf("%s/%s", reqPath, renameObject.SrcName)
+		if !checkLock(c, true, filePath, stdpath.Join(reqPath, renameObject.NewName)) {
+			return
+		}
 		if err := fs.Rename(c, filePath, renameObject.NewName); err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -97,6 +101,9 @@ func FsRecursiveMove(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
+	if !checkLock(c, true, srcDir) {
+		return
+	}
 
 	rootFiles, err := fs.List(c, srcDir, &fs.ListArgs{})
 	if err != nil {
@@ -137,6 +144,9 @@ func FsRecursiveMove(c *gin.Context) {
 			}
 
 			// move
+			if !checkLock(c, false, stdpath.Join(dstDir, movingFile.GetName())) {
+				return
+			}
 			err := fs.Move(c, movingFileName, dstDir, movingFiles.IsEmpty())
 			if err != nil {
 				common.ErrorResp(c, err, 500)
@@ -199,6 +209,9 @@ func FsRegexRename(c *gin.Context) {
 		if srcRegexp.MatchString(file.GetName()) {
 			filePath := fmt.Sprintf("%s/%s", reqPath, file.GetName())
 			newFileName := srcRegexp.ReplaceAllString(file.GetName(), req.NewNameRegex)
+			if !checkLock(c, true, filePath, stdpath.Join(reqPath, newFileName)) {
+				return
+			}
 			if err := fs.Rename(c, filePath, newFileName); err != nil {
 				common.ErrorResp(c, err, 500)
 				return
@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"io"
 	stdpath "path"
+	"strings"
 
-	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/internal/task"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
@@ -36,7 +37,12 @@ func FsMkdir(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	if !user.CanWrite() {
+	if applied, allowed := op.CheckAcl(user, reqPath, model.AclWrite); applied {
+		if !allowed {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	} else if !user.CanWrite() {
 		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
 		if err != nil {
 			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
@@ -49,6 +55,9 @@ func FsMkdir(c *gin.Context) {
 			return
 		}
 	}
+	if !checkLock(c, false, reqPath) {
+		return
+	}
 	if err := fs.MakeDir(c, reqPath); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -74,10 +83,6 @@ func FsMove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -88,6 +93,21 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !op.AclAllowed(user, dstDir, model.AclWrite, user.CanMove()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	for _, name := range req.Names {
+		if !op.AclAllowed(user, stdpath.Join(srcDir, name), model.AclDelete, user.CanMove()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	for _, name := range req.Names {
+		if !checkLock(c, true, stdpath.Join(srcDir, name), stdpath.Join(dstDir, name)) {
+			return
+		}
+	}
 	for i, name := range req.Names {
 		err := fs.Move(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
 		if err != nil {
@@ -109,10 +129,6 @@ func FsCopy(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanCopy() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -123,7 +139,22 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	var addedTasks []tache.TaskWithInfo
+	if !op.AclAllowed(user, dstDir, model.AclWrite, user.CanCopy()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	for _, name := range req.Names {
+		if !op.AclAllowed(user, stdpath.Join(srcDir, name), model.AclRead, user.CanCopy()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	for _, name := range req.Names {
+		if !checkLock(c, true, stdpath.Join(dstDir, name)) {
+			return
+		}
+	}
+	var addedTasks []task.TaskExtensionInfo
 	for i, name := range req.Names {
 		t, err := fs.Copy(c, stdpath.Join(srcDir, name), dstDir, req.Override, len(req.Names) > i+1)
 		if t != nil {
@@ -162,9 +193,15 @@ func FsCopyItem(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanCopy() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
+	for _, name := range req.Names {
+		if !op.AclAllowed(user, name.SrcFile, model.AclRead, user.CanCopy()) ||
+			!op.AclAllowed(user, name.DstDir, model.AclWrite, user.CanCopy()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if !checkLock(c, true, stdpath.Join(name.DstDir, stdpath.Base(name.SrcFile))) {
+			return
+		}
 	}
 	// srcDir, err := user.JoinPath(req.SrcDir)
 	// if err != nil {
@@ -176,7 +213,7 @@ func FsCopyItem(c *gin.Context) {
 	// 	common.ErrorResp(c, err, 403)
 	// 	return
 	// }
-	var addedTasks []tache.TaskWithInfo
+	var addedTasks []task.TaskExtensionInfo
 	for i, name := range req.Names {
 		t, err := fs.Copy(c, name.SrcFile, name.DstDir, req.Override, len(req.Names) > i+1)
 		if t != nil {
@@ -204,15 +241,18 @@ func FsRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !op.AclAllowed(user, reqPath, model.AclWrite, user.CanRename()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !checkLock(c, true, reqPath, stdpath.Join(stdpath.Dir(reqPath), req.Name)) {
+		return
+	}
 	if err := fs.Rename(c, reqPath, req.Name); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -236,15 +276,22 @@ func FsRemove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqDir, err := user.JoinPath(req.Dir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	for _, name := range req.Names {
+		if !op.AclAllowed(user, stdpath.Join(reqDir, name), model.AclDelete, user.CanRemove()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	for _, name := range req.Names {
+		if !checkLock(c, true, stdpath.Join(reqDir, name)) {
+			return
+		}
+	}
 	for _, name := range req.Names {
 		err := fs.Remove(c, stdpath.Join(reqDir, name))
 		if err != nil {
@@ -268,15 +315,18 @@ func FsRemoveEmptyDirectory(c *gin.Context) {
 	}
 
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !op.AclAllowed(user, srcDir, model.AclDelete, user.CanRemove()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !checkLock(c, true, srcDir) {
+		return
+	}
 
 	meta, err := op.GetNearestMeta(srcDir)
 	if err != nil {
@@ -396,3 +446,20 @@ func Link(c *gin.Context) {
 	common.SuccessResp(c, link)
 	return
 }
+
+// checkLock responds 423 if any of the paths is locked by a WebDAV client, the children
+// are checked too if tree is true. The lock holder may pass its token in the Lock-Token header.
+func checkLock(c *gin.Context, tree bool, paths ...string) bool {
+	token := strings.Trim(c.GetHeader("Lock-Token"), "<>")
+	for _, p := range paths {
+		if err := op.CheckWebdavLock(p, tree, token); err != nil {
+			if errors.Is(err, errs.Locked) {
+				common.ErrorResp(c, err, 423)
+			} else {
+				common.ErrorResp(c, err, 500)
+			}
+			return false
+		}
+	}
+	return true
+}
@@ -79,6 +79,10 @@ func FsList(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
+	if !op.AclAllowed(user, reqPath, model.AclList, true) && !op.AclCanTraverse(user, reqPath) {
+		common.ErrorStrResp(c, "Permission denied", 403)
+		return
+	}
 	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
@@ -99,7 +103,7 @@ func FsList(c *gin.Context) {
 		Total:    int64(total),
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
-		Write:    user.CanWrite() || common.CanWrite(meta, reqPath),
+		Write:    op.AclAllowed(user, reqPath, model.AclWrite, user.CanWrite() || common.CanWrite(meta, reqPath)),
 		Provider: provider,
 	})
 }
@@ -137,6 +141,10 @@ func FsDirs(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
+	if !op.AclAllowed(user, reqPath, model.AclList, true) && !op.AclCanTraverse(user, reqPath) {
+		common.ErrorStrResp(c, "Permission denied", 403)
+		return
+	}
 	objs, err := fs.List(c, reqPath, &fs.ListArgs{})
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -268,6 +276,11 @@ func FsGet(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	if obj.IsDir() && !op.AclVisible(user, reqPath) ||
+		!obj.IsDir() && !op.AclAllowed(user, reqPath, model.AclRead, true) {
+		common.ErrorStrResp(c, "Permission denied", 403)
+		return
+	}
 	var rawURL string
 
 	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
@@ -400,3 +413,49 @@ func FsOther(c *gin.Context) {
 	}
 	common.SuccessResp(c, res)
 }
+
+type FsSpaceReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	Refresh  bool   `json:"refresh" form:"refresh"`
+}
+
+// FsSpace returns the space usage of the storage which the path is in
+func FsSpace(c *gin.Context) {
+	var req FsSpaceReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) || !op.AclVisible(user, reqPath) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
+		common.ErrorStrResp(c, "Refresh without permission", 403)
+		return
+	}
+	details, err := fs.GetStorageDetails(c, reqPath, req.Refresh)
+	if err != nil {
+		if errs.IsNotImplement(err) {
+			common.ErrorStrResp(c, "the storage doesn't report the space usage", 501)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, details)
+}
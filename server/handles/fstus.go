@@ -0,0 +1,185 @@
+package handles
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/tus"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// the tus protocol needs the http status codes, so the errors are not wrapped by common.ErrorResp
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+)
+
+func tusError(c *gin.Context, err error, code int) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.String(code, err.Error())
+	c.Abort()
+}
+
+func tusExpires(c *gin.Context, u *model.TusUpload) {
+	c.Header("Upload-Expires", tus.ExpiresAt(u).UTC().Format(http.TimeFormat))
+}
+
+// tusUpload loads the upload of the request, an upload can only be accessed by its creator
+func tusUpload(c *gin.Context) (*model.TusUpload, bool) {
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		tusError(c, errors.New("unsupported tus version"), http.StatusPreconditionFailed)
+		return nil, false
+	}
+	user := c.MustGet("user").(*model.User)
+	u, err := tus.Get(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errs.IsObjectNotFound(err) {
+			tusError(c, errors.New("upload not found"), http.StatusNotFound)
+		} else {
+			tusError(c, err, http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	if u.UserID != user.ID {
+		tusError(c, errors.New("upload not found"), http.StatusNotFound)
+		return nil, false
+	}
+	return u, true
+}
+
+func TusOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Status(http.StatusNoContent)
+}
+
+// TusCreate creates an upload to the File-Path, the permissions are checked by middlewares.FsUp
+func TusCreate(c *gin.Context) {
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		tusError(c, errors.New("unsupported tus version"), http.StatusPreconditionFailed)
+		return
+	}
+	path, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		tusError(c, err, http.StatusBadRequest)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	path, err = user.JoinPath(path)
+	if err != nil {
+		tusError(c, err, http.StatusForbidden)
+		return
+	}
+	if err = op.CheckWebdavLock(path, false, strings.Trim(c.GetHeader("Lock-Token"), "<>")); err != nil {
+		if errors.Is(err, errs.Locked) {
+			tusError(c, err, http.StatusLocked)
+		} else {
+			tusError(c, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		tusError(c, errors.New("Upload-Length is required"), http.StatusBadRequest)
+		return
+	}
+	storage, err := fs.GetStorage(path, &fs.GetStoragesArgs{})
+	if err != nil {
+		tusError(c, err, http.StatusBadRequest)
+		return
+	}
+	if storage.Config().NoUpload {
+		tusError(c, errs.UploadNotSupported, http.StatusMethodNotAllowed)
+		return
+	}
+	u, err := tus.Create(c, path, size, c.GetHeader("Upload-Metadata"))
+	if errors.Is(err, errs.QuotaExceeded) {
+		tusError(c, err, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		tusError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Location", common.GetApiUrl(c.Request)+"/api/fs/tus/"+u.ID)
+	tusExpires(c, u)
+	c.Status(http.StatusCreated)
+}
+
+func TusHead(c *gin.Context) {
+	u, ok := tusUpload(c)
+	if !ok {
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(u.Size, 10))
+	if u.Metadata != "" {
+		c.Header("Upload-Metadata", u.Metadata)
+	}
+	tusExpires(c, u)
+	c.Status(http.StatusOK)
+}
+
+// TusPatch appends a chunk, the file is handed to an upload task once all bytes are received
+func TusPatch(c *gin.Context) {
+	u, ok := tusUpload(c)
+	if !ok {
+		return
+	}
+	if c.ContentType() != "application/offset+octet-stream" {
+		tusError(c, errors.New("unsupported content type"), http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil {
+		tusError(c, errors.New("Upload-Offset is required"), http.StatusBadRequest)
+		return
+	}
+	defer c.Request.Body.Close()
+	_, err = tus.Write(c, u, offset, c.Request.Body)
+	if errors.Is(err, errs.UploadOffsetMismatch) || errors.Is(err, errs.UploadInProgress) {
+		tusError(c, err, http.StatusConflict)
+		return
+	}
+	if errors.Is(err, errs.QuotaExceeded) {
+		tusError(c, err, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		tusError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	tusExpires(c, u)
+	c.Status(http.StatusNoContent)
+}
+
+func TusDelete(c *gin.Context) {
+	u, ok := tusUpload(c)
+	if !ok {
+		return
+	}
+	if err := tus.Terminate(u.ID); err != nil {
+		tusError(c, err, http.StatusInternalServerError)
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Status(http.StatusNoContent)
+}
@@ -7,7 +7,7 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/alist-org/alist/v3/pkg/tache"
+	"github.com/alist-org/alist/v3/internal/task"
 
 	"github.com/alist-org/alist/v3/internal/stream"
 
@@ -42,6 +42,9 @@ func FsStream(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !checkLock(c, false, path) {
+		return
+	}
 	dir, name := stdpath.Split(path)
 	sizeStr := c.GetHeader("Content-Length")
 	size, err := strconv.ParseInt(sizeStr, 10, 64)
@@ -59,9 +62,9 @@ func FsStream(c *gin.Context) {
 		Mimetype:     c.GetHeader("Content-Type"),
 		WebPutAsTask: asTask,
 	}
-	var t tache.TaskWithInfo
+	var t task.TaskExtensionInfo
 	if asTask {
-		t, err = fs.PutAsTask(dir, s)
+		t, err = fs.PutAsTask(c, dir, s)
 	} else {
 		err = fs.PutDirectly(c, dir, s, true)
 	}
@@ -93,6 +96,9 @@ func FsForm(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !checkLock(c, false, path) {
+		return
+	}
 	storage, err := fs.GetStorage(path, &fs.GetStoragesArgs{})
 	if err != nil {
 		common.ErrorResp(c, err, 400)
@@ -124,12 +130,12 @@ func FsForm(c *gin.Context) {
 		Mimetype:     file.Header.Get("Content-Type"),
 		WebPutAsTask: asTask,
 	}
-	var t tache.TaskWithInfo
+	var t task.TaskExtensionInfo
 	if asTask {
 		s.Reader = struct {
 			io.Reader
 		}{f}
-		t, err = fs.PutAsTask(dir, &s)
+		t, err = fs.PutAsTask(c, dir, &s)
 	} else {
 		ss, err := stream.NewSeekableStream(s, nil)
 		if err != nil {
@@ -6,7 +6,6 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search"
-	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -24,14 +23,7 @@ func BuildIndex(c *gin.Context) {
 		return
 	}
 	go func() {
-		ctx := context.Background()
-		err := search.Clear(ctx)
-		if err != nil {
-			log.Errorf("clear index error: %+v", err)
-			return
-		}
-		err = search.BuildIndex(context.Background(), []string{"/"},
-			conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), true)
+		err := search.Rebuild(context.Background())
 		if err != nil {
 			log.Errorf("build index error: %+v", err)
 		}
@@ -0,0 +1,33 @@
+package handles
+
+import (
+	"errors"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/notify"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type NotifyTestReq struct {
+	// empty means all enabled channels
+	Channel string `json:"channel" form:"channel"`
+}
+
+// NotifyTest sends a test notification with the saved settings
+func NotifyTest(c *gin.Context) {
+	var req NotifyTestReq
+	if err := c.ShouldBind(&req); err != nil && !errors.Is(err, io.EOF) {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := notify.Test(c, req.Channel); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListNotifyTypes(c *gin.Context) {
+	common.SuccessResp(c, notify.Types())
+}
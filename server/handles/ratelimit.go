@@ -0,0 +1,12 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListThrottled lists the users and the client ips throttled recently
+func ListThrottled(c *gin.Context) {
+	common.SuccessResp(c, ratelimit.Stats())
+}
@@ -0,0 +1,74 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListS3AccessKeys lists the keys of the current user, admins can list the keys of another user with user_id
+func ListS3AccessKeys(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	userID := user.ID
+	if user.IsAdmin() && c.Query("user_id") != "" {
+		id, err := strconv.Atoi(c.Query("user_id"))
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		userID = uint(id)
+	}
+	keys, err := op.GetS3AccessKeysByUser(userID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, keys)
+}
+
+type CreateS3AccessKeyReq struct {
+	ReadOnly bool   `json:"read_only"`
+	Remark   string `json:"remark"`
+}
+
+func CreateS3AccessKey(c *gin.Context) {
+	var req CreateS3AccessKeyReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest can't create s3 access keys", 403)
+		return
+	}
+	key, err := op.CreateS3AccessKey(user, req.ReadOnly, req.Remark)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, key)
+}
+
+// DeleteS3AccessKey revokes the key, only the owner and admins can revoke it
+func DeleteS3AccessKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	key, err := op.GetS3AccessKeyById(uint(id))
+	if err != nil || (!user.IsAdmin() && key.UserID != user.ID) {
+		common.ErrorStrResp(c, "s3 access key not found", 404)
+		return
+	}
+	if err = op.DeleteS3AccessKeyById(key.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
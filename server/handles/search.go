@@ -57,9 +57,19 @@ func Search(c *gin.Context) {
 		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			continue
 		}
-		if !common.CanAccess(user, meta, path.Join(node.Parent, node.Name), req.Password) {
+		nodePath := path.Join(node.Parent, node.Name)
+		if !common.CanAccess(user, meta, nodePath, req.Password) {
 			continue
 		}
+		// the same acl rules as the list of the parent
+		if !op.AclAllowed(user, node.Parent, model.AclList, true) && !op.AclCanTraverse(user, node.Parent) ||
+			!op.AclVisible(user, nodePath) {
+			continue
+		}
+		// the snippets are the content, which can't be read without the permission
+		if !node.IsDir && !op.AclAllowed(user, nodePath, model.AclRead, true) {
+			node.Snippets = nil
+		}
 		filteredNodes = append(filteredNodes, node)
 	}
 	common.SuccessResp(c, common.PageResp{
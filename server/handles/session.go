@@ -0,0 +1,96 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken generates a new access token by the refresh token, the refresh token is rotated
+func RefreshToken(c *gin.Context) {
+	var req RefreshTokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	tokens, err := common.Refresh(c, req.RefreshToken)
+	if err != nil {
+		common.ErrorResp(c, err, 401)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+// Logout revokes the session of the request
+func Logout(c *gin.Context) {
+	if err := op.DeleteSessionById(c.GetString("session_id")); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func listSessions(c *gin.Context, userID uint) {
+	sessions, err := op.GetSessionsByUser(userID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	current := c.GetString("session_id")
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+	common.SuccessResp(c, sessions)
+}
+
+// ListSessions lists the sessions of the current user
+func ListSessions(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	listSessions(c, user.ID)
+}
+
+// RevokeSession revokes a session of the current user
+func RevokeSession(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	session, err := op.GetSession(c.Query("id"))
+	if err != nil || session.UserID != user.ID {
+		common.ErrorStrResp(c, "session not found", 404)
+		return
+	}
+	if err = op.DeleteSessionById(session.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ListUserSessions lists the sessions of any user for the admins
+func ListUserSessions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	listSessions(c, uint(id))
+}
+
+// LogoutUser revokes all the sessions of the user
+func LogoutUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = op.DeleteSessionsByUser(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
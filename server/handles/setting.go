@@ -4,10 +4,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
-	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils/random"
 	"github.com/alist-org/alist/v3/server/common"
@@ -52,15 +52,15 @@ func SaveSettings(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.SaveSettingItems(req); err != nil {
+	keys := make([]string, len(req))
+	for i := range req {
+		keys[i] = req[i].Key
+	}
+	err := op.SaveSettingItems(req)
+	audit.Record(c, audit.Entry{Operation: model.AuditSettingSave, SrcPath: strings.Join(keys, ",")}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
-		if req[0].Group == 10 {
-			title := setting.GetStr(conf.SiteTitle)
-			if setting.GetBool(conf.NotifyEnabled) {
-				go op.Notify(title+"测试通知", "欢迎使用!!!!")
-			}
-		}
 		common.SuccessResp(c)
 		static.UpdateIndex()
 	}
@@ -100,7 +100,9 @@ func ListSettings(c *gin.Context) {
 
 func DeleteSetting(c *gin.Context) {
 	key := c.Query("key")
-	if err := op.DeleteSettingItemByKey(key); err != nil {
+	err := op.DeleteSettingItemByKey(key)
+	audit.Record(c, audit.Entry{Operation: model.AuditSettingDelete, SrcPath: key}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
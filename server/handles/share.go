@@ -0,0 +1,341 @@
+package handles
+
+import (
+	"net/http"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type CreateShareReq struct {
+	Path         string     `json:"path" binding:"required"`
+	Expires      *time.Time `json:"expires"`
+	Password     string     `json:"password"`
+	MaxDownloads int64      `json:"max_downloads"`
+	AllowPreview bool       `json:"allow_preview"`
+	AllowUpload  bool       `json:"allow_upload"`
+}
+
+type ShareResp struct {
+	model.Share
+	HasPassword bool   `json:"has_password"`
+	URL         string `json:"url"`
+}
+
+func shareResp(c *gin.Context, s model.Share) ShareResp {
+	return ShareResp{Share: s, HasPassword: s.HasPassword(), URL: common.GetApiUrl(c.Request) + "/s/" + s.ID}
+}
+
+// ListShares lists the shares created by the current user, admins can list all with all=true
+func ListShares(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	var shares []model.Share
+	var err error
+	if user.IsAdmin() && c.Query("all") == "true" {
+		shares, err = op.GetShares()
+	} else {
+		shares, err = op.GetSharesByCreator(user.ID)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]ShareResp, 0, len(shares))
+	for _, s := range shares {
+		resp = append(resp, shareResp(c, s))
+	}
+	common.SuccessResp(c, resp)
+}
+
+func CreateShare(c *gin.Context) {
+	var req CreateShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest can't create shares", 403)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccess(user, meta, reqPath, "") || !op.AclAllowed(user, reqPath, model.AclShare, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if req.AllowUpload {
+		if !obj.IsDir() {
+			common.ErrorStrResp(c, "Only folders can be shared with upload", 400)
+			return
+		}
+		if !op.AclAllowed(user, reqPath, model.AclWrite, user.CanWrite() || common.CanWrite(meta, reqPath)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	s := model.Share{
+		Path:         reqPath,
+		Expires:      req.Expires,
+		MaxDownloads: req.MaxDownloads,
+		AllowPreview: req.AllowPreview,
+		AllowUpload:  req.AllowUpload,
+	}
+	s.SetPassword(req.Password)
+	if err = op.CreateShare(user, &s); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, shareResp(c, s))
+}
+
+// DeleteShare revokes the share, only the creator and admins can revoke it
+func DeleteShare(c *gin.Context) {
+	id := c.Query("id")
+	user := c.MustGet("user").(*model.User)
+	s, err := op.GetShareById(id)
+	if err != nil || (!user.IsAdmin() && s.CreatorID != user.ID) {
+		common.ErrorStrResp(c, "share not found", 404)
+		return
+	}
+	if err = op.DeleteShareById(id); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type ShareObjResp struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	IsDir    bool      `json:"is_dir"`
+	Modified time.Time `json:"modified"`
+	Type     int       `json:"type"`
+}
+
+type ShareListResp struct {
+	Name         string         `json:"name"`
+	Path         string         `json:"path"`
+	Content      []ShareObjResp `json:"content"`
+	AllowPreview bool           `json:"allow_preview"`
+	AllowUpload  bool           `json:"allow_upload"`
+}
+
+// shareOf resolves the share of the request and its creator,
+// the creator is set as the user of the request so that the metas and the acl rules of the creator apply
+func shareOf(c *gin.Context) (*model.Share, *model.User, string, bool) {
+	s, err := op.GetShare(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.ErrorStrResp(c, "share not found", 404)
+		} else if errors.Is(err, errs.ShareExpired) {
+			common.ErrorResp(c, err, 410)
+		} else {
+			common.ErrorResp(c, err, 500, true)
+		}
+		return nil, nil, "", false
+	}
+	if s.HasPassword() {
+		password := c.Query("pwd")
+		if password == "" {
+			password = c.GetHeader("Share-Password")
+		}
+		if !s.ValidatePassword(password) {
+			common.ErrorStrResp(c, "password is incorrect", 401)
+			return nil, nil, "", false
+		}
+	}
+	creator, err := op.GetUserById(s.CreatorID)
+	if err != nil || creator.Disabled {
+		common.ErrorStrResp(c, "share not found", 404)
+		return nil, nil, "", false
+	}
+	c.Set("user", creator)
+	// the sub path is cleaned before joining so that it can't go out of the shared path
+	reqPath := stdpath.Join(s.Path, utils.FixAndCleanPath(c.Param("path")))
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return nil, nil, "", false
+	}
+	c.Set("meta", meta)
+	return s, creator, reqPath, true
+}
+
+// ShareGet serves /s/:id, a folder is listed and a file is downloaded through Down,
+// a file requested with preview=true is counted as a view if the share allows preview,
+// and as a download too if the share has a download limit
+func ShareGet(c *gin.Context) {
+	s, creator, reqPath, ok := shareOf(c)
+	if !ok {
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			common.ErrorResp(c, err, 404)
+		} else {
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	if obj.IsDir() {
+		if !op.AclAllowed(creator, reqPath, model.AclList, true) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		objs, err := fs.List(c, reqPath, &fs.ListArgs{})
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		if err = op.AddShareView(s.ID); err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		content := make([]ShareObjResp, 0, len(objs))
+		for _, o := range objs {
+			content = append(content, ShareObjResp{
+				Name:     o.GetName(),
+				Size:     o.GetSize(),
+				IsDir:    o.IsDir(),
+				Modified: o.ModTime(),
+				Type:     utils.GetFileType(o.GetName()),
+			})
+		}
+		common.SuccessResp(c, ShareListResp{
+			Name:         stdpath.Base(reqPath),
+			Path:         utils.FixAndCleanPath(c.Param("path")),
+			Content:      content,
+			AllowPreview: s.AllowPreview,
+			AllowUpload:  s.AllowUpload,
+		})
+		return
+	}
+	if !op.AclAllowed(creator, reqPath, model.AclRead, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if c.Request.Method != "HEAD" && startsFromBeginning(c.Request) {
+		if c.Query("preview") == "true" {
+			if !s.AllowPreview {
+				common.ErrorStrResp(c, "preview is not allowed", 403)
+				return
+			}
+			// the preview is the whole file as well, so it can't get around the download limit
+			if s.MaxDownloads > 0 {
+				err = op.AddShareDownload(s.ID)
+			}
+			if err == nil {
+				err = op.AddShareView(s.ID)
+			}
+		} else {
+			err = op.AddShareDownload(s.ID)
+		}
+		if errors.Is(err, errs.ShareExhausted) {
+			common.ErrorResp(c, err, 410)
+			return
+		}
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("path", reqPath)
+	Down(c)
+}
+
+// startsFromBeginning reports whether the request reads the file from the first byte,
+// so that the following range requests of a player or a resumed download are counted once
+func startsFromBeginning(r *http.Request) bool {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(r.Header.Get("Range")), "bytes=")
+	if !ok {
+		// no range or an unknown unit, the whole file is served
+		return true
+	}
+	first, _, _ := strings.Cut(spec, ",")
+	start, _, _ := strings.Cut(first, "-")
+	return strings.TrimSpace(start) == "0"
+}
+
+// SharePut uploads the request body as a file into the shared folder,
+// the file is written as the creator so that the quota of the creator applies, existing files are never overwritten
+func SharePut(c *gin.Context) {
+	s, creator, reqPath, ok := shareOf(c)
+	if !ok {
+		return
+	}
+	if !s.AllowUpload {
+		common.ErrorStrResp(c, "upload is not allowed", 403)
+		return
+	}
+	if reqPath == s.Path {
+		common.ErrorStrResp(c, "file name is required", 400)
+		return
+	}
+	dir, name := stdpath.Split(reqPath)
+	meta, _ := c.MustGet("meta").(*model.Meta)
+	if !op.AclAllowed(creator, dir, model.AclWrite, creator.CanWrite() || common.CanWrite(meta, dir)) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if _, err := fs.Get(c, reqPath, &fs.GetArgs{NoLog: true}); err == nil {
+		common.ErrorStrResp(c, "file already exists", 409)
+		return
+	}
+	if !checkLock(c, false, reqPath) {
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Content-Length"), 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	defer c.Request.Body.Close()
+	err = fs.PutDirectly(c, dir, &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: getLastModified(c),
+		},
+		Reader:   c.Request.Body,
+		Mimetype: c.GetHeader("Content-Type"),
+	}, true)
+	if errors.Is(err, errs.QuotaExceeded) {
+		common.ErrorResp(c, err, 507)
+		return
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
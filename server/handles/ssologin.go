@@ -260,10 +260,12 @@ func OIDCLoginCallback(c *gin.Context) {
 				common.ErrorResp(c, err, 400)
 			}
 		}
-		token, err := common.GenerateToken(user)
+		tokens, err := common.Login(c, user)
 		if err != nil {
 			common.ErrorResp(c, err, 400)
+			return
 		}
+		token := tokens.Token
 		if useCompatibility {
 			c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
 			return
@@ -426,10 +428,12 @@ func SSOLoginCallback(c *gin.Context) {
 			return
 		}
 	}
-	token, err := common.GenerateToken(user)
+	tokens, err := common.Login(c, user)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
+		return
 	}
+	token := tokens.Token
 	if usecompatibility {
 		c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
 		return
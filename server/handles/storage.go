@@ -3,9 +3,13 @@ package handles
 import (
 	"context"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -27,18 +31,71 @@ func ListStorages(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, common.PageResp{
-		Content: storages,
+		Content: withDetails(c, storages),
 		Total:   total,
 	})
 }
 
+type StorageResp struct {
+	model.Storage
+	Details *model.StorageDetails `json:"details,omitempty"`
+}
+
+// the storages which don't respond in time are listed without the details,
+// they are cached once fetched so they are likely listed the next time
+const detailsTimeout = 3 * time.Second
+
+func withDetails(ctx context.Context, storages []model.Storage) []StorageResp {
+	resp := make([]StorageResp, len(storages))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := range storages {
+		resp[i].Storage = storages[i]
+		if storages[i].Disabled {
+			continue
+		}
+		storage, err := op.GetStorageByMountPath(storages[i].MountPath)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			details, err := op.GetStorageDetails(context.WithoutCancel(ctx), storage)
+			if err != nil {
+				if !errs.IsNotImplement(err) {
+					log.Warnf("failed get details of storage [%s]: %+v", storages[i].MountPath, err)
+				}
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			resp[i].Details = details
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(detailsTimeout):
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]StorageResp(nil), resp...)
+}
+
 func CreateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if id, err := op.CreateStorage(c, req); err != nil {
+	id, err := op.CreateStorage(c, req)
+	audit.Record(c, audit.Entry{Operation: model.AuditStorageCreate, SrcPath: req.MountPath}, err)
+	if err != nil {
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
 		}, true)
@@ -69,7 +126,9 @@ func UpdateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.UpdateStorage(c, req); err != nil {
+	err := op.UpdateStorage(c, req)
+	audit.Record(c, audit.Entry{Operation: model.AuditStorageUpdate, SrcPath: req.MountPath}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -83,7 +142,10 @@ func DeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteStorageById(c, uint(id)); err != nil {
+	subject := storageSubject(uint(id))
+	err = op.DeleteStorageById(c, uint(id))
+	audit.Record(c, audit.Entry{Operation: model.AuditStorageDelete, SrcPath: subject}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -97,7 +159,10 @@ func ForceDeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.ForceDeleteStorageById(c, uint(id)); err != nil {
+	subject := storageSubject(uint(id))
+	err = op.ForceDeleteStorageById(c, uint(id))
+	audit.Record(c, audit.Entry{Operation: model.AuditStorageDelete, SrcPath: subject}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -111,7 +176,10 @@ func DisableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DisableStorage(c, uint(id)); err != nil {
+	subject := storageSubject(uint(id))
+	err = op.DisableStorage(c, uint(id))
+	audit.Record(c, audit.Entry{Operation: model.AuditStorageDisable, SrcPath: subject}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -125,7 +193,10 @@ func EnableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.EnableStorage(c, uint(id)); err != nil {
+	subject := storageSubject(uint(id))
+	err = op.EnableStorage(c, uint(id))
+	audit.Record(c, audit.Entry{Operation: model.AuditStorageEnable, SrcPath: subject}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
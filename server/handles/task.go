@@ -3,8 +3,12 @@ package handles
 import (
 	"math"
 
+	"github.com/alist-org/alist/v3/internal/dedup"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/syncjob"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/tache"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -12,16 +16,18 @@ import (
 )
 
 type TaskInfo struct {
-	ID       string      `json:"id"`
-	Name     string      `json:"name"`
-	State    tache.State `json:"state"`
-	Status   string      `json:"status"`
-	Progress float64     `json:"progress"`
-	Size     int64       `json:"size"`
-	Error    string      `json:"error"`
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Creator   string      `json:"creator"`
+	CreatorID uint        `json:"creator_id"`
+	State     tache.State `json:"state"`
+	Status    string      `json:"status"`
+	Progress  float64     `json:"progress"`
+	Size      int64       `json:"size"`
+	Error     string      `json:"error"`
 }
 
-func getTaskInfo[T tache.TaskWithInfo](task T) TaskInfo {
+func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
 	errMsg := ""
 	if task.GetErr() != nil {
 		errMsg = task.GetErr().Error()
@@ -32,62 +38,97 @@ func getTaskInfo[T tache.TaskWithInfo](task T) TaskInfo {
 		progress = 100
 	}
 	return TaskInfo{
-		ID:       task.GetID(),
-		Name:     task.GetName(),
-		State:    task.GetState(),
-		Status:   task.GetStatus(),
-		Size:     task.GetSize(),
-		Progress: progress,
-		Error:    errMsg,
+		ID:        task.GetID(),
+		Name:      task.GetName(),
+		Creator:   task.GetCreator(),
+		CreatorID: task.GetCreatorID(),
+		State:     task.GetState(),
+		Status:    task.GetStatus(),
+		Size:      task.GetSize(),
+		Progress:  progress,
+		Error:     errMsg,
 	}
 }
 
-func getTaskInfos[T tache.TaskWithInfo](tasks []T) []TaskInfo {
+func getTaskInfos[T task.TaskExtensionInfo](tasks []T) []TaskInfo {
 	return utils.MustSliceConvert(tasks, getTaskInfo[T])
 }
 
-func taskRoute[T tache.TaskWithInfo](g *gin.RouterGroup, manager *tache.Manager[T]) {
+// filterByCreator keeps the tasks the current user can manage, admins can manage all tasks
+func filterByCreator[T task.TaskExtensionInfo](c *gin.Context, tasks []T) []T {
+	user := c.MustGet("user").(*model.User)
+	return utils.SliceFilter(tasks, func(t T) bool {
+		return t.IsCreatedBy(user)
+	})
+}
+
+// getTargetedTask returns the task specified by tid if the current user can manage it,
+// the response is written if it returns false
+func getTargetedTask[T task.TaskExtensionInfo](c *gin.Context, manager *tache.Manager[T]) (T, bool) {
+	tid := c.Query("tid")
+	t, ok := manager.GetByID(tid)
+	if !ok || !t.IsCreatedBy(c.MustGet("user").(*model.User)) {
+		common.ErrorStrResp(c, "task not found", 404)
+		return t, false
+	}
+	return t, true
+}
+
+func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager *tache.Manager[T]) {
 	g.GET("/undone", func(c *gin.Context) {
-		common.SuccessResp(c, getTaskInfos(manager.GetByState(tache.StatePending, tache.StateRunning,
-			tache.StateCanceling, tache.StateErrored, tache.StateFailing, tache.StateWaitingRetry, tache.StateBeforeRetry)))
+		common.SuccessResp(c, getTaskInfos(filterByCreator(c, manager.GetByState(tache.StatePending, tache.StateRunning,
+			tache.StateCanceling, tache.StateErrored, tache.StateFailing, tache.StateWaitingRetry, tache.StateBeforeRetry))))
 	})
 	g.GET("/done", func(c *gin.Context) {
-		common.SuccessResp(c, getTaskInfos(manager.GetByState(tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)))
+		common.SuccessResp(c, getTaskInfos(filterByCreator(c, manager.GetByState(tache.StateCanceled, tache.StateFailed, tache.StateSucceeded))))
 	})
 	g.POST("/info", func(c *gin.Context) {
-		tid := c.Query("tid")
-		task, ok := manager.GetByID(tid)
+		t, ok := getTargetedTask(c, manager)
 		if !ok {
-			common.ErrorStrResp(c, "task not found", 404)
 			return
 		}
-		common.SuccessResp(c, getTaskInfo(task))
+		common.SuccessResp(c, getTaskInfo(t))
 	})
 	g.POST("/cancel", func(c *gin.Context) {
-		tid := c.Query("tid")
-		manager.Cancel(tid)
+		t, ok := getTargetedTask(c, manager)
+		if !ok {
+			return
+		}
+		manager.Cancel(t.GetID())
 		common.SuccessResp(c)
 	})
 	g.POST("/delete", func(c *gin.Context) {
-		tid := c.Query("tid")
-		manager.Remove(tid)
+		t, ok := getTargetedTask(c, manager)
+		if !ok {
+			return
+		}
+		manager.Remove(t.GetID())
 		common.SuccessResp(c)
 	})
 	g.POST("/retry", func(c *gin.Context) {
-		tid := c.Query("tid")
-		manager.Retry(tid)
+		t, ok := getTargetedTask(c, manager)
+		if !ok {
+			return
+		}
+		manager.Retry(t.GetID())
 		common.SuccessResp(c)
 	})
 	g.POST("/clear_done", func(c *gin.Context) {
-		manager.RemoveByState(tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)
+		for _, t := range filterByCreator(c, manager.GetByState(tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)) {
+			manager.Remove(t.GetID())
+		}
 		common.SuccessResp(c)
 	})
 	g.POST("/clear_succeeded", func(c *gin.Context) {
-		manager.RemoveByState(tache.StateSucceeded)
+		for _, t := range filterByCreator(c, manager.GetByState(tache.StateSucceeded)) {
+			manager.Remove(t.GetID())
+		}
 		common.SuccessResp(c)
 	})
 	g.POST("/retry_failed", func(c *gin.Context) {
-		manager.RetryAllFailed()
+		for _, t := range filterByCreator(c, manager.GetByState(tache.StateFailed)) {
+			manager.Retry(t.GetID())
+		}
 		common.SuccessResp(c)
 	})
 }
@@ -95,6 +136,9 @@ func taskRoute[T tache.TaskWithInfo](g *gin.RouterGroup, manager *tache.Manager[
 func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/upload"), fs.UploadTaskManager)
 	taskRoute(g.Group("/copy"), fs.CopyTaskManager)
+	taskRoute(g.Group("/decompress"), fs.ArchiveDecompressTaskManager)
+	taskRoute(g.Group("/sync"), syncjob.SyncTaskManager)
+	taskRoute(g.Group("/dedup"), dedup.ScanTaskManager)
 	taskRoute(g.Group("/offline_download"), tool.DownloadTaskManager)
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
 }
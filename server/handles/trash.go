@@ -0,0 +1,140 @@
+package handles
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/trash"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// ListTrash lists the removed objs under the base path of the user,
+// the paths are relative to the base path as the other fs apis
+func ListTrash(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	items, err := trash.GetItems()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]model.TrashItem, 0, len(items))
+	for _, item := range items {
+		visible, err := canSeeTrashItem(user, &item)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !visible {
+			continue
+		}
+		item.Path = utils.FixAndCleanPath(strings.TrimPrefix(item.Path, utils.FixAndCleanPath(user.BasePath)))
+		// the trash may be out of the base path
+		if !user.IsAdmin() {
+			item.TrashPath = ""
+		}
+		resp = append(resp, item)
+	}
+	common.SuccessResp(c, resp)
+}
+
+type TrashReq struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// canSeeTrashItem reports whether the item is under the base path of the user,
+// and its original path can be accessed and seen by the user as in the fs list
+func canSeeTrashItem(user *model.User, item *model.TrashItem) (bool, error) {
+	if !utils.IsSubPath(user.BasePath, item.Path) {
+		return false, nil
+	}
+	meta, err := op.GetNearestMeta(item.Path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false, err
+	}
+	return common.CanAccess(user, meta, item.Path, "") && op.AclVisible(user, item.Path), nil
+}
+
+// trashItems loads the items, the items the user can't see are regarded as not found
+func trashItems(c *gin.Context, user *model.User, ids []uint) ([]*model.TrashItem, bool) {
+	items := make([]*model.TrashItem, 0, len(ids))
+	for _, id := range ids {
+		item, err := trash.GetItemById(id)
+		if err != nil {
+			common.ErrorStrResp(c, "trash item not found", 404)
+			return nil, false
+		}
+		visible, err := canSeeTrashItem(user, item)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return nil, false
+		}
+		if !visible {
+			common.ErrorStrResp(c, "trash item not found", 404)
+			return nil, false
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
+
+// RestoreTrash moves the objs back, it requires the permission to write the original dirs
+func RestoreTrash(c *gin.Context) {
+	var req TrashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	items, ok := trashItems(c, user, req.IDs)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		dir := stdpath.Dir(item.Path)
+		meta, err := op.GetNearestMeta(dir)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !op.AclAllowed(user, dir, model.AclWrite, user.CanWrite() || common.CanWrite(meta, dir)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if err = trash.Restore(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
+
+// PurgeTrash removes the objs permanently, it requires the permission to remove them
+func PurgeTrash(c *gin.Context) {
+	var req TrashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	items, ok := trashItems(c, user, req.IDs)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		if !op.AclAllowed(user, item.Path, model.AclDelete, user.CanRemove()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if err := trash.Purge(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
@@ -1,8 +1,11 @@
 package handles
 
 import (
+	"math"
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -42,7 +45,9 @@ func CreateUser(c *gin.Context) {
 	req.SetPassword(req.Password)
 	req.Password = ""
 	req.Authn = "[]"
-	if err := op.CreateUser(&req); err != nil {
+	err := op.CreateUser(&req)
+	audit.Record(c, audit.Entry{Operation: model.AuditUserCreate, SrcPath: req.Username}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -78,11 +83,20 @@ func UpdateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "admin user can not be disabled", 400)
 		return
 	}
-	if err := op.UpdateUser(&req); err != nil {
+	err = op.UpdateUser(&req)
+	audit.Record(c, audit.Entry{Operation: model.AuditUserUpdate, SrcPath: req.Username}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
-	} else {
-		common.SuccessResp(c)
+		return
+	}
+	// the refresh tokens outlive the password otherwise
+	if req.PwdHash != user.PwdHash {
+		if err = op.DeleteSessionsByUser(req.ID); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
 	}
+	common.SuccessResp(c)
 }
 
 func DeleteUser(c *gin.Context) {
@@ -92,7 +106,10 @@ func DeleteUser(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteUserById(uint(id)); err != nil {
+	subject := userSubject(uint(id))
+	err = op.DeleteUserById(uint(id))
+	audit.Record(c, audit.Entry{Operation: model.AuditUserDelete, SrcPath: subject}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -137,3 +154,53 @@ func DelUserCache(c *gin.Context) {
 	}
 	common.SuccessResp(c)
 }
+
+func GetUserUsage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	usage, err := op.GetUserUsage(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, usage)
+}
+
+// RecalculateUsage recalculates the usage of the user with id,
+// or all users with quota if id is not given
+func RecalculateUsage(c *gin.Context) {
+	var users []model.User
+	if idStr := c.Query("id"); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		user, err := op.GetUserById(uint(id))
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		users = append(users, *user)
+	} else {
+		all, _, err := op.GetUsers(1, math.MaxInt32)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		for _, user := range all {
+			if user.HasQuota() {
+				users = append(users, user)
+			}
+		}
+	}
+	if err := fs.RecalculateUsage(users); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -111,12 +111,12 @@ func FinishAuthnLogin(c *gin.Context) {
 		return
 	}
 
-	token, err := common.GenerateToken(user)
+	tokens, err := common.Login(c, user)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
 	}
-	common.SuccessResp(c, gin.H{"token": token})
+	common.SuccessResp(c, tokens)
 }
 
 func BeginAuthnRegistration(c *gin.Context) {
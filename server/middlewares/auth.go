@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"crypto/subtle"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -45,6 +46,10 @@ func Auth(c *gin.Context) {
 		c.Next()
 		return
 	}
+	if strings.HasPrefix(token, model.ApiTokenPrefix) {
+		authApiToken(c, token)
+		return
+	}
 	userClaims, err := common.ParseToken(token)
 	if err != nil {
 		common.ErrorResp(c, err, 401)
@@ -63,6 +68,15 @@ func Auth(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	// validate session, it may be revoked
+	session, err := op.GetSession(userClaims.ID)
+	if err != nil || session.UserID != user.ID {
+		common.ErrorStrResp(c, "Session has been revoked, login please", 401)
+		c.Abort()
+		return
+	}
+	op.TouchSession(session)
+	c.Set("session_id", session.ID)
 	if user.Disabled {
 		common.ErrorStrResp(c, "Current user is disabled, replace please", 401)
 		c.Abort()
@@ -99,6 +113,10 @@ func Authn(c *gin.Context) {
 		c.Next()
 		return
 	}
+	if strings.HasPrefix(token, model.ApiTokenPrefix) {
+		authApiToken(c, token)
+		return
+	}
 	userClaims, err := common.ParseToken(token)
 	if err != nil {
 		common.ErrorResp(c, err, 401)
@@ -117,6 +135,15 @@ func Authn(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	// validate session, it may be revoked
+	session, err := op.GetSession(userClaims.ID)
+	if err != nil || session.UserID != user.ID {
+		common.ErrorStrResp(c, "Session has been revoked, login please", 401)
+		c.Abort()
+		return
+	}
+	op.TouchSession(session)
+	c.Set("session_id", session.ID)
 	if user.Disabled {
 		common.ErrorStrResp(c, "Current user is disabled, replace please", 401)
 		c.Abort()
@@ -127,6 +154,31 @@ func Authn(c *gin.Context) {
 	c.Next()
 }
 
+// authApiToken sets the user restricted by the api token
+func authApiToken(c *gin.Context, token string) {
+	user, err := op.GetUserByApiToken(token)
+	if err != nil {
+		common.ErrorResp(c, err, 401)
+		c.Abort()
+		return
+	}
+	c.Set("user", user)
+	log.Debugf("use api token: %+v", user)
+	c.Next()
+}
+
+// NoApiToken refuses the requests authenticated by the api tokens,
+// such as managing the credentials of the user
+func NoApiToken(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.TokenID != 0 {
+		common.ErrorStrResp(c, "Not allowed with an api token", 403)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
 func AuthAdmin(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	if !user.IsAdmin() {
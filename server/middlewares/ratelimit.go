@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits the requests by the budget, a request is keyed by its user once authenticated,
+// or by its client ip. It's used both before and after the authentication of a route,
+// so that the guesses of the passwords are limited too, a key is only charged once by a request
+func RateLimit(l *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		key := "ip:" + c.ClientIP()
+		if user, ok := c.Value("user").(*model.User); ok && !user.IsGuest() {
+			key = ratelimit.UserKey(user.ID)
+		}
+		charged := c.GetStringSlice("rate_limit_keys")
+		if slices.Contains(charged, l.Name()+"/"+key) {
+			c.Next()
+			return
+		}
+		c.Set("rate_limit_keys", append(charged, l.Name()+"/"+key))
+		release, retryAfter, ok := l.Acquire(key)
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, common.Resp[interface{}]{
+				Code:    http.StatusTooManyRequests,
+				Message: "too many requests, retry later please",
+			})
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
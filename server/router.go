@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/handles"
@@ -55,25 +56,44 @@ func Init(e *gin.Engine) {
 	if conf.Conf.MaxConnections > 0 {
 		g.Use(middlewares.MaxAllowed(conf.Conf.MaxConnections))
 	}
-	WebDav(g.Group("/dav"))
-	S3(g.Group("/s3"))
+	WebDav(g.Group("/dav", middlewares.RateLimit(ratelimit.WebDAV)))
+	S3(g.Group("/s3", middlewares.RateLimit(ratelimit.S3)))
 
-	g.GET("/d/*path", middlewares.Down, handles.Down)
-	g.GET("/p/*path", middlewares.Down, handles.Proxy)
-	g.HEAD("/d/*path", middlewares.Down, handles.Down)
-	g.HEAD("/p/*path", middlewares.Down, handles.Proxy)
+	down := g.Group("", middlewares.RateLimit(ratelimit.Download))
+	down.GET("/d/*path", middlewares.Down, handles.Down)
+	down.GET("/p/*path", middlewares.Down, handles.Proxy)
+	down.HEAD("/d/*path", middlewares.Down, handles.Down)
+	down.HEAD("/p/*path", middlewares.Down, handles.Proxy)
+	down.GET("/ad/*path", middlewares.Down, handles.ArchiveDown)
+	down.GET("/ae/*path", middlewares.Down, handles.ArchiveInternalExtract)
+	down.HEAD("/ad/*path", middlewares.Down, handles.ArchiveDown)
+	down.HEAD("/ae/*path", middlewares.Down, handles.ArchiveInternalExtract)
+	// public share links, the sub path is relative to the shared folder
+	down.GET("/s/:id", handles.ShareGet)
+	down.GET("/s/:id/*path", handles.ShareGet)
+	down.HEAD("/s/:id", handles.ShareGet)
+	down.HEAD("/s/:id/*path", handles.ShareGet)
+	down.PUT("/s/:id/*path", handles.SharePut)
 
-	api := g.Group("/api")
-	auth := api.Group("", middlewares.Auth)
-	webauthn := api.Group("/authn", middlewares.Authn)
+	// the requests are limited by the client ip before the authentication, and by the user after it
+	api := g.Group("/api", middlewares.RateLimit(ratelimit.API))
+	auth := api.Group("", middlewares.Auth, middlewares.RateLimit(ratelimit.API))
+	webauthn := api.Group("/authn", middlewares.Authn, middlewares.NoApiToken, middlewares.RateLimit(ratelimit.API))
 
 	api.POST("/auth/login", handles.Login)
 	api.POST("/auth/login/hash", handles.LoginHash)
 	api.POST("/auth/login/ldap", handles.LoginLdap)
+	api.POST("/auth/refresh", handles.RefreshToken)
+	auth.POST("/auth/logout", middlewares.NoApiToken, handles.Logout)
 	auth.GET("/me", handles.CurrentUser)
-	auth.POST("/me/update", handles.UpdateCurrent)
-	auth.POST("/auth/2fa/generate", handles.Generate2FA)
-	auth.POST("/auth/2fa/verify", handles.Verify2FA)
+	auth.POST("/me/update", middlewares.NoApiToken, handles.UpdateCurrent)
+	auth.GET("/me/tokens", handles.ListApiTokens)
+	auth.POST("/me/tokens/create", middlewares.NoApiToken, handles.CreateApiToken)
+	auth.POST("/me/tokens/delete", middlewares.NoApiToken, handles.DeleteApiToken)
+	auth.GET("/me/sessions", middlewares.NoApiToken, handles.ListSessions)
+	auth.POST("/me/sessions/revoke", middlewares.NoApiToken, handles.RevokeSession)
+	auth.POST("/auth/2fa/generate", middlewares.NoApiToken, handles.Generate2FA)
+	auth.POST("/auth/2fa/verify", middlewares.NoApiToken, handles.Verify2FA)
 
 	// auth
 	api.GET("/auth/sso", handles.SSOLoginRedirect)
@@ -100,6 +120,10 @@ func Init(e *gin.Engine) {
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 
 	_fs(auth.Group("/fs"))
+	_share(auth.Group("/share"))
+	_s3Key(auth.Group("/s3_key", middlewares.NoApiToken))
+	// users manage the tasks created by themselves, admins manage all
+	handles.SetupTaskRoute(auth.Group("/task"))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Debug || flags.Dev {
 		debug(g.Group("/debug"))
@@ -125,6 +149,24 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
+	user.GET("/usage", handles.GetUserUsage)
+	user.POST("/recalc_usage", handles.RecalculateUsage)
+	user.GET("/sessions", handles.ListUserSessions)
+	user.POST("/logout", handles.LogoutUser)
+
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/get", handles.GetGroup)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
+
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListAclRules)
+	acl.GET("/get", handles.GetAclRule)
+	acl.POST("/create", handles.CreateAclRule)
+	acl.POST("/update", handles.UpdateAclRule)
+	acl.POST("/delete", handles.DeleteAclRule)
 
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
@@ -155,6 +197,29 @@ func admin(g *gin.RouterGroup) {
 	task := g.Group("/task")
 	handles.SetupTaskRoute(task)
 
+	syncJob := g.Group("/sync_job")
+	syncJob.GET("/list", handles.ListSyncJobs)
+	syncJob.GET("/get", handles.GetSyncJob)
+	syncJob.POST("/create", handles.CreateSyncJob)
+	syncJob.POST("/update", handles.UpdateSyncJob)
+	syncJob.POST("/delete", handles.DeleteSyncJob)
+	syncJob.POST("/run", handles.RunSyncJob)
+
+	duplicate := g.Group("/duplicate")
+	duplicate.POST("/scan", handles.ScanDuplicates)
+	duplicate.GET("/list", handles.ListDuplicates)
+	duplicate.POST("/resolve", handles.ResolveDuplicates)
+
+	g.GET("/rate_limit/list", handles.ListThrottled)
+
+	auditLog := g.Group("/audit")
+	auditLog.GET("/list", handles.ListAuditLogs)
+	auditLog.GET("/export", handles.ExportAuditLogs)
+
+	notify := g.Group("/notify")
+	notify.GET("/types", handles.ListNotifyTypes)
+	notify.POST("/test", handles.NotifyTest)
+
 	ms := g.Group("/message")
 	ms.POST("/get", message.HttpInstance.GetHandle)
 	ms.POST("/send", message.HttpInstance.SendHandle)
@@ -173,6 +238,9 @@ func _fs(g *gin.RouterGroup) {
 	g.Any("/get", handles.FsGet)
 	g.Any("/other", handles.FsOther)
 	g.Any("/dirs", handles.FsDirs)
+	g.Any("/space", handles.FsSpace)
+	g.Any("/archive/meta", handles.FsArchiveMeta)
+	g.Any("/archive/list", handles.FsArchiveList)
 	g.POST("/mkdir", handles.FsMkdir)
 	g.POST("/rename", handles.FsRename)
 	g.POST("/batch_rename", handles.FsBatchRename)
@@ -183,14 +251,37 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/copy_item", handles.FsCopyItem)
 	g.POST("/remove", handles.FsRemove)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
+	g.GET("/trash/list", handles.ListTrash)
+	g.POST("/trash/restore", handles.RestoreTrash)
+	g.POST("/trash/purge", handles.PurgeTrash)
+	g.POST("/archive/decompress", handles.FsArchiveDecompress)
 	g.PUT("/put", middlewares.FsUp, handles.FsStream)
 	g.PUT("/form", middlewares.FsUp, handles.FsForm)
+	// resumable uploads by the tus protocol
+	g.OPTIONS("/tus", handles.TusOptions)
+	g.POST("/tus", middlewares.FsUp, handles.TusCreate)
+	g.HEAD("/tus/:id", handles.TusHead)
+	g.PATCH("/tus/:id", handles.TusPatch)
+	g.DELETE("/tus/:id", handles.TusDelete)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	//g.POST("/add_aria2", handles.AddOfflineDownload)
 	//g.POST("/add_qbit", handles.AddQbittorrent)
 	g.POST("/add_offline_download", handles.AddOfflineDownload)
 }
 
+// the keys of the built-in s3 server, users manage their own keys
+func _s3Key(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListS3AccessKeys)
+	g.POST("/create", handles.CreateS3AccessKey)
+	g.POST("/delete", handles.DeleteS3AccessKey)
+}
+
+func _share(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListShares)
+	g.POST("/create", handles.CreateShare)
+	g.POST("/delete", handles.DeleteShare)
+}
+
 func Cors(r *gin.Engine) {
 	config := cors.DefaultConfig()
 	//config.AllowAllOrigins = true
@@ -202,5 +293,5 @@ func Cors(r *gin.Engine) {
 
 func InitS3(e *gin.Engine) {
 	Cors(e)
-	S3Server(e.Group("/"))
+	S3Server(e.Group("/", middlewares.RateLimit(ratelimit.S3)))
 }
@@ -0,0 +1,205 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Mikubill/gofakes3"
+	"github.com/Mikubill/gofakes3/signature"
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+)
+
+// ErrAccessDenied is not defined by gofakes3, it's responded with 500 if returned by the backend,
+// so the permissions of the writes are also checked before the request is served by gofakes3
+const ErrAccessDenied gofakes3.ErrorCode = "AccessDenied"
+
+// ErrLocked is responded with 423 when the object is locked by a WebDAV client
+const ErrLocked gofakes3.ErrorCode = "Locked"
+
+// ErrSlowDown is responded with 503 when the requests of the user are throttled
+const ErrSlowDown gofakes3.ErrorCode = "SlowDown"
+
+// ErrOperationAborted is responded with 409 when the multipart upload is being completed
+const ErrOperationAborted gofakes3.ErrorCode = "OperationAborted"
+
+// credential is who the request acts as
+type credential struct {
+	secret   string
+	user     *model.User
+	readOnly bool
+}
+
+// getCredential resolves the access key, the key in the settings acts as the admin,
+// the key id of an api token acts as the user restricted by the token,
+// the anonymous requests are allowed as the admin only if there is no key at all
+func getCredential(accessKeyID string) (*credential, error) {
+	globalKey, globalSecret := setting.GetStr(conf.S3AccessKeyId), setting.GetStr(conf.S3SecretAccessKey)
+	if accessKeyID == "" {
+		hasKeys, err := op.HasS3AccessKeys()
+		if err != nil {
+			return nil, err
+		}
+		if globalKey != "" || globalSecret != "" || hasKeys {
+			return nil, errors.New("the request is not signed")
+		}
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return nil, err
+		}
+		return &credential{user: admin}, nil
+	}
+	if accessKeyID == globalKey {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return nil, err
+		}
+		return &credential{secret: globalSecret, user: admin}, nil
+	}
+	if strings.HasPrefix(accessKeyID, model.ApiTokenPrefix) {
+		token, user, err := op.GetApiTokenUserByKeyId(accessKeyID)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := op.ApiTokenS3Secret(token)
+		if err != nil {
+			return nil, err
+		}
+		return &credential{secret: secret, user: user}, nil
+	}
+	key, err := op.GetS3AccessKeyByAccessKeyId(accessKeyID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := op.GetUserById(key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errors.New("the user is disabled")
+	}
+	return &credential{secret: key.Secret, user: user, readOnly: key.ReadOnly}, nil
+}
+
+// accessKeyOf parses the access key id from the Authorization header of the signature v4,
+// or from the query of a presigned url
+func accessKeyOf(r *http.Request) string {
+	cred := r.URL.Query().Get("X-Amz-Credential")
+	if cred == "" {
+		var found bool
+		if _, cred, found = strings.Cut(r.Header.Get("Authorization"), "Credential="); !found {
+			return ""
+		}
+	}
+	accessKeyID, _, _ := strings.Cut(cred, "/")
+	return strings.TrimSpace(accessKeyID)
+}
+
+// the client address isn't passed to the backend, so it's missing in the audit logs
+func withUser(user *model.User) context.Context {
+	ctx := context.WithValue(context.Background(), "user", user)
+	return audit.WithClient(ctx, "", model.AuditProtocolS3)
+}
+
+// bucketVisible reports whether the bucket is listed for the user, it should be under the base path of the user
+func bucketVisible(user *model.User, bucket Bucket) bool {
+	return utils.IsSubPath(user.BasePath, bucket.Path) && op.AclVisible(user, bucket.Path) && canAccess(user, bucket.Path)
+}
+
+// canAccess checks the metas of the path, the passwords can't be provided by s3 clients
+func canAccess(user *model.User, p string) bool {
+	meta, err := op.GetNearestMeta(p)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false
+	}
+	return common.CanAccess(user, meta, p, "")
+}
+
+func canRead(user *model.User, p string, perm int32) bool {
+	return utils.IsSubPath(user.BasePath, p) && canAccess(user, p) && op.AclAllowed(user, p, perm, true)
+}
+
+func canWrite(user *model.User, dir string) bool {
+	if !utils.IsSubPath(user.BasePath, dir) {
+		return false
+	}
+	meta, _ := op.GetNearestMeta(dir)
+	return op.AclAllowed(user, dir, model.AclWrite, user.CanWrite() || common.CanWrite(meta, dir))
+}
+
+func canRemove(user *model.User, p string) bool {
+	return utils.IsSubPath(user.BasePath, p) && op.AclAllowed(user, p, model.AclDelete, user.CanRemove())
+}
+
+// authorize checks the writes of the request in the same way as the web ui,
+// the objs of a multi-delete are in the body, they are checked by the backend one by one
+func authorize(r *http.Request, cred *credential) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if cred.readOnly {
+		return false
+	}
+	fp, ok := targetPath(r, cred)
+	if !ok {
+		return true
+	}
+	// aborting a multipart upload removes nothing
+	if r.Method == http.MethodDelete && !r.URL.Query().Has("uploadId") {
+		return canRemove(cred.user, fp)
+	}
+	return canWrite(cred.user, path.Dir(fp))
+}
+
+// targetPath returns the full path of the object in the request,
+// the requests on a bucket and to the unknown buckets are left to the backend
+func targetPath(r *http.Request, cred *credential) (string, bool) {
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucketName == "" || key == "" {
+		return "", false
+	}
+	bucket, err := getBucketByName(cred.user, bucketName)
+	if err != nil {
+		// responded as no such bucket by the backend
+		return "", false
+	}
+	return path.Join(bucket.Path, key), true
+}
+
+// checkLock refuses the writes to the objects locked by a WebDAV client
+func checkLock(r *http.Request, cred *credential) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	fp, ok := targetPath(r, cred)
+	if !ok {
+		return nil
+	}
+	if r.Method == http.MethodDelete {
+		if r.URL.Query().Has("uploadId") {
+			return nil
+		}
+		return op.CheckWebdavLock(fp, true)
+	}
+	return op.CheckWebdavLock(fp, false)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, code gofakes3.ErrorCode, status int) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(signature.EncodeResponse(gofakes3.ErrorResponse{Code: code, Message: string(code)}))
+}
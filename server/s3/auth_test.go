@@ -0,0 +1,60 @@
+package s3
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestAccessKey(t *testing.T) {
+	user := &model.User{Username: "s3", Password: "s3", BasePath: "/", Role: model.GENERAL}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	key, err := op.CreateS3AccessKey(user, true, "")
+	if err != nil {
+		t.Fatalf("failed create key: %+v", err)
+	}
+	r := httptest.NewRequest("GET", "/bucket/a.txt", nil)
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+key.AccessKeyID+"/20240101/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc")
+	if got := accessKeyOf(r); got != key.AccessKeyID {
+		t.Fatalf("expect access key %s, got %s", key.AccessKeyID, got)
+	}
+	if _, err = getCredential(""); err == nil {
+		t.Errorf("expect the anonymous requests to be refused once a key exists")
+	}
+	cred, err := getCredential(key.AccessKeyID)
+	if err != nil {
+		t.Fatalf("failed get credential: %+v", err)
+	}
+	if cred.user.ID != user.ID || cred.secret != key.Secret {
+		t.Errorf("expect the credential of user %d, got %d", user.ID, cred.user.ID)
+	}
+	if !authorize(r, cred) {
+		t.Errorf("expect a read-only key to read")
+	}
+	if authorize(httptest.NewRequest("PUT", "/bucket/a.txt", nil), cred) {
+		t.Errorf("expect a read-only key not to write")
+	}
+	if err = op.DeleteS3AccessKeyById(key.ID); err != nil {
+		t.Fatalf("failed delete key: %+v", err)
+	}
+	if _, err = getCredential(key.AccessKeyID); err == nil {
+		t.Errorf("expect the revoked key to be refused")
+	}
+}
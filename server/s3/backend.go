@@ -9,10 +9,10 @@ import (
 	"io"
 	"path"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Mikubill/gofakes3"
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -21,6 +21,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/ncw/swift/v2"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -31,26 +32,46 @@ var (
 // s3Backend implements the gofacess3.Backend interface to make an S3
 // backend for gofakes3
 type s3Backend struct {
-	meta *sync.Map
+	// the requests are signed with it, empty for the anonymous requests
+	accessKeyID string
 }
 
 // newBackend creates a new SimpleBucketBackend.
-func newBackend() gofakes3.Backend {
+func newBackend(accessKeyID string) gofakes3.Backend {
 	return &s3Backend{
-		meta: new(sync.Map),
+		accessKeyID: accessKeyID,
 	}
 }
 
-// ListBuckets always returns the default bucket.
+// user resolves the user of the access key on every call, so that the changes of the user apply at once
+func (b *s3Backend) user() (*model.User, error) {
+	cred, err := getCredential(b.accessKeyID)
+	if err != nil {
+		return nil, ErrAccessDenied
+	}
+	return cred.user, nil
+}
+
+// ListBuckets returns the buckets visible to the user.
 func (b *s3Backend) ListBuckets() ([]gofakes3.BucketInfo, error) {
+	user, err := b.user()
+	if err != nil {
+		return nil, err
+	}
 	buckets, err := getAndParseBuckets()
 	if err != nil {
 		return nil, err
 	}
 	var response []gofakes3.BucketInfo
-	ctx := context.Background()
+	ctx := withUser(user)
 	for _, b := range buckets {
-		node, _ := fs.Get(ctx, b.Path, &fs.GetArgs{})
+		if !bucketVisible(user, b) {
+			continue
+		}
+		node, err := fs.Get(ctx, b.Path, &fs.GetArgs{})
+		if err != nil {
+			continue
+		}
 		response = append(response, gofakes3.BucketInfo{
 			// Name:         gofakes3.URLEncode(b.Name),
 			Name:         b.Name,
@@ -62,7 +83,11 @@ func (b *s3Backend) ListBuckets() ([]gofakes3.BucketInfo, error) {
 
 // ListBucket lists the objects in the given bucket.
 func (b *s3Backend) ListBucket(bucketName string, prefix *gofakes3.Prefix, page gofakes3.ListBucketPage) (*gofakes3.ObjectList, error) {
-	bucket, err := getBucketByName(bucketName)
+	user, err := b.user()
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := getBucketByName(user, bucketName)
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +108,7 @@ func (b *s3Backend) ListBucket(bucketName string, prefix *gofakes3.Prefix, page
 	response := gofakes3.NewObjectList()
 	path, remaining := prefixParser(prefix)
 
-	err = b.entryListR(bucketPath, path, remaining, prefix.HasDelimiter, response)
+	err = b.entryListR(user, bucketPath, path, remaining, prefix.HasDelimiter, response)
 	if err == gofakes3.ErrNoSuchKey {
 		// AWS just returns an empty list
 		response = gofakes3.NewObjectList()
@@ -98,14 +123,21 @@ func (b *s3Backend) ListBucket(bucketName string, prefix *gofakes3.Prefix, page
 //
 // Note that the metadata is not supported yet.
 func (b *s3Backend) HeadObject(bucketName, objectName string) (*gofakes3.Object, error) {
-	ctx := context.Background()
-	bucket, err := getBucketByName(bucketName)
+	user, err := b.user()
+	if err != nil {
+		return nil, err
+	}
+	ctx := withUser(user)
+	bucket, err := getBucketByName(user, bucketName)
 	if err != nil {
 		return nil, err
 	}
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canRead(user, fp, model.AclRead) {
+		return nil, gofakes3.KeyNotFound(objectName)
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -124,9 +156,8 @@ func (b *s3Backend) HeadObject(bucketName, objectName string) (*gofakes3.Object,
 		"Content-Type":  utils.GetMimeType(fp),
 	}
 
-	if val, ok := b.meta.Load(fp); ok {
-		metaMap := val.(map[string]string)
-		for k, v := range metaMap {
+	if objMeta, err := op.GetS3ObjectMeta(fp); err == nil {
+		for k, v := range objMeta {
 			meta[k] = v
 		}
 	}
@@ -142,14 +173,21 @@ func (b *s3Backend) HeadObject(bucketName, objectName string) (*gofakes3.Object,
 
 // GetObject fetchs the object from the filesystem.
 func (b *s3Backend) GetObject(bucketName, objectName string, rangeRequest *gofakes3.ObjectRangeRequest) (obj *gofakes3.Object, err error) {
-	ctx := context.Background()
-	bucket, err := getBucketByName(bucketName)
+	user, err := b.user()
+	if err != nil {
+		return nil, err
+	}
+	ctx := withUser(user)
+	bucket, err := getBucketByName(user, bucketName)
 	if err != nil {
 		return nil, err
 	}
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canRead(user, fp, model.AclRead) {
+		return nil, gofakes3.KeyNotFound(objectName)
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -161,6 +199,7 @@ func (b *s3Backend) GetObject(bucketName, objectName string, rangeRequest *gofak
 	}
 
 	link, file, err := fs.Link(ctx, fp, model.LinkArgs{})
+	audit.Download(ctx, fp, file, err)
 	if err != nil {
 		return nil, err
 	}
@@ -232,13 +271,13 @@ func (b *s3Backend) GetObject(bucketName, objectName string, rangeRequest *gofak
 		"Content-Type":  utils.GetMimeType(fp),
 	}
 
-	if val, ok := b.meta.Load(fp); ok {
-		metaMap := val.(map[string]string)
-		for k, v := range metaMap {
+	if objMeta, err := op.GetS3ObjectMeta(fp); err == nil {
+		for k, v := range objMeta {
 			meta[k] = v
 		}
 	}
 
+	storage, _ := fs.GetStorage(fp, &fs.GetStoragesArgs{})
 	return &gofakes3.Object{
 		// Name: gofakes3.URLEncode(objectName),
 		Name: objectName,
@@ -246,7 +285,11 @@ func (b *s3Backend) GetObject(bucketName, objectName string, rangeRequest *gofak
 		Metadata: meta,
 		Size:     size,
 		Range:    rnge,
-		Contents: rdr,
+		Contents: &stream.RateLimitReader{
+			Reader:  rdr,
+			Limiter: op.DownloadLimiter(ctx, storage),
+			Ctx:     ctx,
+		},
 	}, nil
 }
 
@@ -262,8 +305,12 @@ func (b *s3Backend) PutObject(
 	meta map[string]string,
 	input io.Reader, size int64,
 ) (result gofakes3.PutObjectResult, err error) {
-	ctx := context.Background()
-	bucket, err := getBucketByName(bucketName)
+	user, err := b.user()
+	if err != nil {
+		return result, err
+	}
+	ctx := withUser(user)
+	bucket, err := getBucketByName(user, bucketName)
 	if err != nil {
 		return result, err
 	}
@@ -271,6 +318,9 @@ func (b *s3Backend) PutObject(
 
 	fp := path.Join(bucketPath, objectName)
 	reqPath := path.Dir(fp)
+	if !canWrite(user, reqPath) {
+		return result, ErrAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	_, err = fs.Get(context.WithValue(ctx, "meta", fmeta), reqPath, &fs.GetArgs{})
 	if err != nil {
@@ -310,7 +360,9 @@ func (b *s3Backend) PutObject(
 		return result, err
 	}
 
-	b.meta.Store(fp, meta)
+	if err = op.SaveS3ObjectMeta(fp, objectMeta(meta)); err != nil {
+		utils.Log.Errorf("serve s3: failed save meta of [%s]: %+v", fp, err)
+	}
 
 	return result, nil
 }
@@ -318,8 +370,15 @@ func (b *s3Backend) PutObject(
 // DeleteMulti deletes multiple objects in a single request.
 func (b *s3Backend) DeleteMulti(bucketName string, objects ...string) (result gofakes3.MultiDeleteResult, rerr error) {
 	for _, object := range objects {
-		if err := b.deleteObject(bucketName, object); err != nil {
-			utils.Log.Errorf("serve s3", "delete object failed: %v", err)
+		if err := b.deleteObject(bucketName, object); err == ErrAccessDenied || err == ErrLocked {
+			code := err.(gofakes3.ErrorCode)
+			result.Error = append(result.Error, gofakes3.ErrorResult{
+				Code:    code,
+				Message: string(code),
+				Key:     object,
+			})
+		} else if err != nil {
+			utils.Log.Errorf("serve s3: delete object failed: %v", err)
 			result.Error = append(result.Error, gofakes3.ErrorResult{
 				Code:    gofakes3.ErrInternal,
 				Message: gofakes3.ErrInternal.Message(),
@@ -342,14 +401,26 @@ func (b *s3Backend) DeleteObject(bucketName, objectName string) (result gofakes3
 
 // deleteObject deletes the object from the filesystem.
 func (b *s3Backend) deleteObject(bucketName, objectName string) error {
-	ctx := context.Background()
-	bucket, err := getBucketByName(bucketName)
+	user, err := b.user()
+	if err != nil {
+		return err
+	}
+	ctx := withUser(user)
+	bucket, err := getBucketByName(user, bucketName)
 	if err != nil {
 		return err
 	}
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canRemove(user, fp) {
+		return ErrAccessDenied
+	}
+	if err = op.CheckWebdavLock(fp, true); errors.Is(err, errs.Locked) {
+		return ErrLocked
+	} else if err != nil {
+		return err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	// S3 does not report an error when attemping to delete a key that does not exist, so
 	// we need to skip IsNotExist errors.
@@ -373,12 +444,16 @@ func (b *s3Backend) DeleteBucket(name string) error {
 
 // BucketExists checks if the bucket exists.
 func (b *s3Backend) BucketExists(name string) (exists bool, err error) {
+	user, err := b.user()
+	if err != nil {
+		return false, err
+	}
 	buckets, err := getAndParseBuckets()
 	if err != nil {
 		return false, err
 	}
 	for _, b := range buckets {
-		if b.Name == name {
+		if b.Name == name && bucketVisible(user, b) {
 			return true, nil
 		}
 	}
@@ -387,13 +462,16 @@ func (b *s3Backend) BucketExists(name string) (exists bool, err error) {
 
 // CopyObject copy specified object from srcKey to dstKey.
 func (b *s3Backend) CopyObject(srcBucket, srcKey, dstBucket, dstKey string, meta map[string]string) (result gofakes3.CopyObjectResult, err error) {
+	user, err := b.user()
+	if err != nil {
+		return result, err
+	}
 	if srcBucket == dstBucket && srcKey == dstKey {
-		//TODO: update meta
-		return result, nil
+		// copying an obj to itself is how the clients update the metadata
+		return result, b.replaceMeta(user, dstBucket, dstKey, meta)
 	}
-
-	ctx := context.Background()
-	srcB, err := getBucketByName(srcBucket)
+	ctx := withUser(user)
+	srcB, err := getBucketByName(user, srcBucket)
 	if err != nil {
 		return result, err
 	}
@@ -430,3 +508,18 @@ func (b *s3Backend) CopyObject(srcBucket, srcKey, dstBucket, dstKey string, meta
 		LastModified: gofakes3.NewContentTime(srcNode.ModTime()),
 	}, nil
 }
+
+func (b *s3Backend) replaceMeta(user *model.User, bucketName, objectName string, meta map[string]string) error {
+	if meta["X-Amz-Metadata-Directive"] != "REPLACE" {
+		return nil
+	}
+	bucket, err := getBucketByName(user, bucketName)
+	if err != nil {
+		return err
+	}
+	fp := path.Join(bucket.Path, objectName)
+	if !canWrite(user, path.Dir(fp)) {
+		return ErrAccessDenied
+	}
+	return op.SaveS3ObjectMeta(fp, objectMeta(meta))
+}
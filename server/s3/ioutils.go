@@ -2,7 +2,13 @@
 // Package s3 implements a fake s3 server for alist
 package s3
 
-import "io"
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
 
 type noOpReadCloser struct{}
 
@@ -34,3 +40,48 @@ func (rwc *readerWithCloser) Close() error {
 	}
 	return nil
 }
+
+// chunkedReader decodes the aws-chunked body, the chunk signatures and the trailers are skipped
+// since the request is verified by the seed signature
+type chunkedReader struct {
+	r      *bufio.Reader
+	remain int64
+	done   bool
+}
+
+func newChunkedReader(r io.Reader) *chunkedReader {
+	return &chunkedReader{r: bufio.NewReader(r)}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	if c.remain == 0 {
+		// <hex size>[;chunk-signature=<sig>]\r\n
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		sizeStr, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeStr, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid chunk size %q", sizeStr)
+		}
+		if size == 0 {
+			c.done = true
+			return 0, io.EOF
+		}
+		c.remain = size
+	}
+	if int64(len(p)) > c.remain {
+		p = p[:c.remain]
+	}
+	n, err := c.r.Read(p)
+	c.remain -= int64(n)
+	if c.remain == 0 && err == nil {
+		// the \r\n after the data
+		_, err = c.r.Discard(2)
+	}
+	return n, err
+}
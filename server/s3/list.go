@@ -7,12 +7,13 @@ import (
 	"strings"
 
 	"github.com/Mikubill/gofakes3"
+	"github.com/alist-org/alist/v3/internal/model"
 )
 
-func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, response *gofakes3.ObjectList) error {
+func (b *s3Backend) entryListR(user *model.User, bucket, fdPath, name string, addPrefix bool, response *gofakes3.ObjectList) error {
 	fp := path.Join(bucket, fdPath)
 
-	dirEntries, err := getDirEntries(fp)
+	dirEntries, err := getDirEntries(user, fp)
 	if err != nil {
 		return err
 	}
@@ -33,7 +34,11 @@ func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, resp
 				response.AddPrefix(objectPath)
 				continue
 			}
-			err := b.entryListR(bucket, path.Join(fdPath, object), "", false, response)
+			err := b.entryListR(user, bucket, path.Join(fdPath, object), "", false, response)
+			// the sub folders can't be listed by the user are skipped
+			if err == gofakes3.ErrNoSuchKey {
+				continue
+			}
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,311 @@
+package s3
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Mikubill/gofakes3"
+	"github.com/Mikubill/gofakes3/signature"
+	"github.com/Mikubill/gofakes3/xml"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/s3upload"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// the multipart uploads are served here instead of gofakes3, which keeps the parts in memory,
+// the parts are staged by s3upload and the obj is put by an upload task once completed
+
+const (
+	xmlns                 = "http://s3.amazonaws.com/doc/2006-03-01/"
+	defaultMaxUploads     = 1000
+	defaultMaxUploadParts = 1000
+	maxUploadPartNumber   = 10000
+)
+
+type initiateMultipartUploadResult struct {
+	XMLName xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	gofakes3.InitiateMultipartUpload
+}
+
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	gofakes3.CompleteMultipartUploadResult
+}
+
+type listMultipartUploadsResult struct {
+	XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	gofakes3.ListMultipartUploadsResult
+}
+
+// multipart serves the request if it's about the multipart uploads, it returns false otherwise
+func (s *server) multipart(w http.ResponseWriter, r *http.Request, cred *credential) bool {
+	query := r.URL.Query()
+	_, uploads := query["uploads"]
+	uploadID := query.Get("uploadId")
+	if !uploads && uploadID == "" {
+		return false
+	}
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	bucket, err := getBucketByName(cred.user, bucketName)
+	if err != nil {
+		handleError(w, r, err)
+		return true
+	}
+	if uploads && r.Method == http.MethodGet {
+		err = listUploads(w, r, cred.user, bucket)
+	} else if key == "" {
+		err = gofakes3.ErrMethodNotAllowed
+	} else if uploads && r.Method == http.MethodPost {
+		err = createUpload(w, r, cred.user, bucket, key)
+	} else if uploads {
+		err = gofakes3.ErrMethodNotAllowed
+	} else {
+		var u *model.S3Upload
+		u, err = s3upload.Get(uploadID)
+		// the uploads of other users are not found
+		if err != nil || u.UserID != cred.user.ID || u.Bucket != bucket.Name || u.ObjectKey != key {
+			handleError(w, r, gofakes3.ErrNoSuchUpload)
+			return true
+		}
+		switch r.Method {
+		case http.MethodGet:
+			err = listParts(w, r, u)
+		case http.MethodPut:
+			err = putPart(w, r, cred.user, u)
+		case http.MethodPost:
+			err = completeUpload(w, r, cred.user, u)
+		case http.MethodDelete:
+			if err = s3upload.Abort(u.ID); err == nil {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			err = gofakes3.ErrMethodNotAllowed
+		}
+	}
+	if err != nil {
+		handleError(w, r, err)
+	}
+	return true
+}
+
+func createUpload(w http.ResponseWriter, r *http.Request, user *model.User, bucket Bucket, key string) error {
+	fp := path.Join(bucket.Path, key)
+	u, err := s3upload.Create(withUser(user), bucket.Name, key, fp, objectMeta(headerMeta(r.Header)))
+	if err != nil {
+		return err
+	}
+	return writeXML(w, initiateMultipartUploadResult{
+		Xmlns: xmlns,
+		InitiateMultipartUpload: gofakes3.InitiateMultipartUpload{
+			Bucket:   bucket.Name,
+			Key:      key,
+			UploadID: gofakes3.UploadID(u.ID),
+		},
+	})
+}
+
+func putPart(w http.ResponseWriter, r *http.Request, user *model.User, u *model.S3Upload) error {
+	defer r.Body.Close()
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		return gofakes3.ErrNotImplemented
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number <= 0 || number > maxUploadPartNumber {
+		return gofakes3.ErrInvalidPart
+	}
+	size, err := strconv.ParseInt(r.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return gofakes3.ErrMissingContentLength
+	}
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		body = newChunkedReader(r.Body)
+		size, err = strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil {
+			return gofakes3.ErrMissingContentLength
+		}
+	}
+	var contentMD5 []byte
+	if v := r.Header.Get("Content-MD5"); v != "" {
+		if contentMD5, err = base64.StdEncoding.DecodeString(v); err != nil {
+			return gofakes3.ErrInvalidDigest
+		}
+	}
+	p, err := s3upload.WritePart(withUser(user), u, number, body, size, contentMD5)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", `"`+p.ETag+`"`)
+	return nil
+}
+
+func completeUpload(w http.ResponseWriter, r *http.Request, user *model.User, u *model.S3Upload) error {
+	defer r.Body.Close()
+	var in gofakes3.CompleteMultipartUploadRequest
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if err = xml.Unmarshal(body, &in); err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, err.Error())
+	}
+	parts := make([]s3upload.CompletedPart, 0, len(in.Parts))
+	for _, p := range in.Parts {
+		parts = append(parts, s3upload.CompletedPart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	etag, _, err := s3upload.Complete(withUser(user), u, parts)
+	if err != nil {
+		return err
+	}
+	return writeXML(w, completeMultipartUploadResult{
+		Xmlns: xmlns,
+		CompleteMultipartUploadResult: gofakes3.CompleteMultipartUploadResult{
+			Bucket: u.Bucket,
+			Key:    u.ObjectKey,
+			ETag:   `"` + etag + `"`,
+		},
+	})
+}
+
+// listUploads lists the uploads of the user in the bucket ordered by the key and the time,
+// the markers are respected but the delimiter is not supported
+func listUploads(w http.ResponseWriter, r *http.Request, user *model.User, bucket Bucket) error {
+	query := r.URL.Query()
+	maxUploads := int64(defaultMaxUploads)
+	if v := query.Get("max-uploads"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return gofakes3.ErrInvalidURI
+		}
+		if n > 0 && n < maxUploads {
+			maxUploads = n
+		}
+	}
+	uploads, err := s3upload.GetUploads(user.ID, bucket.Name)
+	if err != nil {
+		return err
+	}
+	prefix, keyMarker, uploadIDMarker := query.Get("prefix"), query.Get("key-marker"), query.Get("upload-id-marker")
+	res := gofakes3.ListMultipartUploadsResult{
+		Bucket:         bucket.Name,
+		KeyMarker:      keyMarker,
+		UploadIDMarker: gofakes3.UploadID(uploadIDMarker),
+		MaxUploads:     maxUploads,
+		Prefix:         prefix,
+	}
+	// the uploads of the key marker are listed after the upload id marker
+	afterIDMarker := false
+	for _, u := range uploads {
+		if keyMarker != "" && u.ObjectKey <= keyMarker {
+			if u.ObjectKey < keyMarker || uploadIDMarker == "" || !afterIDMarker {
+				afterIDMarker = afterIDMarker || u.ID == uploadIDMarker
+				continue
+			}
+		}
+		if !strings.HasPrefix(u.ObjectKey, prefix) {
+			continue
+		}
+		if int64(len(res.Uploads)) >= maxUploads {
+			res.IsTruncated = true
+			last := res.Uploads[len(res.Uploads)-1]
+			res.NextKeyMarker, res.NextUploadIDMarker = last.Key, last.UploadID
+			break
+		}
+		res.Uploads = append(res.Uploads, gofakes3.ListMultipartUploadItem{
+			Key:          u.ObjectKey,
+			UploadID:     gofakes3.UploadID(u.ID),
+			StorageClass: gofakes3.StorageStandard,
+			Initiated:    gofakes3.NewContentTime(u.Created),
+		})
+	}
+	return writeXML(w, listMultipartUploadsResult{Xmlns: xmlns, ListMultipartUploadsResult: res})
+}
+
+func listParts(w http.ResponseWriter, r *http.Request, u *model.S3Upload) error {
+	query := r.URL.Query()
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+	maxParts := int64(defaultMaxUploadParts)
+	if n, err := strconv.ParseInt(query.Get("max-parts"), 10, 64); err == nil && n > 0 && n < maxParts {
+		maxParts = n
+	}
+	parts, err := s3upload.GetParts(u.ID)
+	if err != nil {
+		return err
+	}
+	res := gofakes3.ListMultipartUploadPartsResult{
+		Bucket:           u.Bucket,
+		Key:              u.ObjectKey,
+		UploadID:         gofakes3.UploadID(u.ID),
+		StorageClass:     gofakes3.StorageStandard,
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+	for _, p := range parts {
+		if p.PartNumber <= marker {
+			continue
+		}
+		if int64(len(res.Parts)) >= maxParts {
+			res.IsTruncated = true
+			break
+		}
+		res.Parts = append(res.Parts, gofakes3.ListMultipartUploadPartItem{
+			PartNumber:   p.PartNumber,
+			LastModified: gofakes3.NewContentTime(p.Updated),
+			ETag:         `"` + p.ETag + `"`,
+			Size:         p.Size,
+		})
+		res.NextPartNumberMarker = p.PartNumber
+	}
+	return writeXML(w, res)
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) error {
+	w.Header().Set("Content-Type", "application/xml")
+	_, err := w.Write(signature.EncodeResponse(v))
+	return err
+}
+
+// handleError responds the error in the same way as gofakes3
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errs.S3InvalidPart):
+		err = gofakes3.ErrInvalidPart
+	case errors.Is(err, errs.S3InvalidPartOrder):
+		err = gofakes3.ErrInvalidPartOrder
+	case errors.Is(err, errs.S3IncompleteBody):
+		err = gofakes3.ErrIncompleteBody
+	case errors.Is(err, errs.S3BadDigest):
+		err = gofakes3.ErrBadDigest
+	case errors.Is(err, errs.QuotaExceeded), errors.Is(err, errs.PermissionDenied):
+		err = ErrAccessDenied
+	case errors.Is(err, errs.UploadInProgress):
+		err = ErrOperationAborted
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = gofakes3.ErrNoSuchUpload
+	}
+	var e interface{ ErrorCode() gofakes3.ErrorCode }
+	if !errors.As(err, &e) {
+		utils.Log.Errorf("serve s3: %+v", err)
+		writeError(w, r, gofakes3.ErrInternal, http.StatusInternalServerError)
+		return
+	}
+	code := e.ErrorCode()
+	status := code.Status()
+	switch code {
+	case ErrAccessDenied:
+		status = http.StatusForbidden
+	case ErrOperationAborted:
+		status = http.StatusConflict
+	}
+	writeError(w, r, code, status)
+}
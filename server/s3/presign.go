@@ -0,0 +1,146 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the presigned urls are signed in the query string, which is not supported by gofakes3,
+// see https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+
+const (
+	signV4Algorithm  = "AWS4-HMAC-SHA256"
+	iso8601Format    = "20060102T150405Z"
+	maxPresignExpiry = 7 * 24 * time.Hour
+	// the clock of the client may be a little ahead
+	maxPresignSkew = 15 * time.Minute
+)
+
+func isPresigned(r *http.Request) bool {
+	return r.URL.Query().Has("X-Amz-Signature")
+}
+
+// verifyPresigned checks the signature and the expiry of a presigned url,
+// only the objs can be got or put by presigned urls
+func verifyPresigned(r *http.Request, secret string, now time.Time) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+	default:
+		return errors.Errorf("%s is not allowed by presigned urls", r.Method)
+	}
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return errors.New("unsupported algorithm")
+	}
+	// <access key>/<date>/<region>/<service>/aws4_request
+	cred := strings.Split(query.Get("X-Amz-Credential"), "/")
+	if len(cred) != 5 || cred[4] != "aws4_request" {
+		return errors.New("malformed credential")
+	}
+	date, err := time.Parse(iso8601Format, query.Get("X-Amz-Date"))
+	if err != nil {
+		return errors.New("malformed date")
+	}
+	if date.Format("20060102") != cred[1] {
+		return errors.New("the date of the credential doesn't match")
+	}
+	expires, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires <= 0 || time.Duration(expires)*time.Second > maxPresignExpiry {
+		return errors.New("invalid expires")
+	}
+	if now.Add(maxPresignSkew).Before(date) {
+		return errors.New("the request is not valid yet")
+	}
+	if now.After(date.Add(time.Duration(expires) * time.Second)) {
+		return errors.New("the request is expired")
+	}
+	signedHeaders := query.Get("X-Amz-SignedHeaders")
+	if signedHeaders == "" {
+		return errors.New("missing signed headers")
+	}
+	payload := query.Get("X-Amz-Content-Sha256")
+	if payload == "" {
+		payload = "UNSIGNED-PAYLOAD"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		uriEncode(r.URL.Path, false),
+		canonicalQuery(query),
+		canonicalHeaders(r, signedHeaders),
+		signedHeaders,
+		payload,
+	}, "\n")
+	scope := strings.Join(cred[1:], "/")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{signV4Algorithm, query.Get("X-Amz-Date"), scope, hex.EncodeToString(hashed[:])}, "\n")
+	key := []byte("AWS4" + secret)
+	for _, s := range cred[1:] {
+		key = hmacSHA256(key, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(signature), []byte(query.Get("X-Amz-Signature"))) {
+		return errors.New("the signature does not match")
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes the query without the signature, sorted by the keys
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for k, vs := range query {
+		if k == "X-Amz-Signature" {
+			continue
+		}
+		for _, v := range vs {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(r *http.Request, signedHeaders string) string {
+	var b strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		b.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+	return b.String()
+}
+
+// uriEncode encodes every byte except the unreserved characters as aws does,
+// the slashes are kept in the paths
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+	return b.String()
+}
@@ -4,24 +4,98 @@ package s3
 
 import (
 	"context"
+	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/Mikubill/gofakes3"
+	"github.com/Mikubill/gofakes3/signature"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
 )
 
+// server verifies the signature and serves the request by the gofakes3 of the access key,
+// every access key has its own backend so that the backend knows who the request acts as
+type server struct {
+	// access key id -> http.Handler
+	fakers sync.Map
+}
+
 // Make a new S3 Server to serve the remote
 func NewServer(ctx context.Context) (h http.Handler, err error) {
+	return &server{}, nil
+}
+
+func (s *server) faker(accessKeyID string) http.Handler {
+	if h, ok := s.fakers.Load(accessKeyID); ok {
+		return h.(http.Handler)
+	}
 	var newLogger logger
 	faker := gofakes3.New(
-		newBackend(),
+		newBackend(accessKeyID),
 		// gofakes3.WithHostBucket(!opt.pathBucketMode),
 		gofakes3.WithLogger(newLogger),
 		gofakes3.WithRequestID(rand.Uint64()),
 		gofakes3.WithoutVersioning(),
-		gofakes3.WithV4Auth(authlistResolver()),
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
+	h, _ := s.fakers.LoadOrStore(accessKeyID, faker.Server())
+	return h.(http.Handler)
+}
 
-	return faker.Server(), nil
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	accessKeyID := accessKeyOf(r)
+	cred, err := getCredential(accessKeyID)
+	if err != nil {
+		utils.Log.Warnf("serve s3: access denied: %s => %s: %+v", r.RemoteAddr, r.URL, err)
+		writeError(w, r, ErrAccessDenied, http.StatusForbidden)
+		return
+	}
+	if accessKeyID != "" && isPresigned(r) {
+		if err = verifyPresigned(r, cred.secret, time.Now()); err != nil {
+			utils.Log.Warnf("serve s3: access denied: %s => %s: %+v", r.RemoteAddr, r.URL, err)
+			writeError(w, r, ErrAccessDenied, http.StatusForbidden)
+			return
+		}
+	} else if accessKeyID != "" {
+		// the secret is refreshed on every request, the revoked keys are refused by getCredential
+		signature.StoreKeys(map[string]string{accessKeyID: cred.secret})
+		if result := signature.V4SignVerify(r); result != signature.ErrNone {
+			utils.Log.Warnf("serve s3: access denied: %s => %s", r.RemoteAddr, r.URL)
+			resp := signature.GetAPIError(result)
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(resp.HTTPStatusCode)
+			_, _ = w.Write(signature.EncodeAPIErrorToResponse(resp))
+			return
+		}
+	}
+	// the requests are limited by the client ip before, and by the user once authenticated
+	release, retryAfter, ok := ratelimit.S3.Acquire(ratelimit.UserKey(cred.user.ID))
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+		writeError(w, r, ErrSlowDown, http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+	if !authorize(r, cred) {
+		writeError(w, r, ErrAccessDenied, http.StatusForbidden)
+		return
+	}
+	if err = checkLock(r, cred); errors.Is(err, errs.Locked) {
+		writeError(w, r, ErrLocked, http.StatusLocked)
+		return
+	} else if err != nil {
+		utils.Log.Errorf("serve s3: check lock failed: %+v", err)
+		writeError(w, r, gofakes3.ErrInternal, http.StatusInternalServerError)
+		return
+	}
+	if s.multipart(w, r, cred) {
+		return
+	}
+	s.faker(accessKeyID).ServeHTTP(w, r)
 }
@@ -5,6 +5,7 @@ package s3
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"strings"
 
 	"github.com/Mikubill/gofakes3"
@@ -27,21 +28,25 @@ func getAndParseBuckets() ([]Bucket, error) {
 	return res, err
 }
 
-func getBucketByName(name string) (Bucket, error) {
+// getBucketByName returns the bucket only if it's visible to the user
+func getBucketByName(user *model.User, name string) (Bucket, error) {
 	buckets, err := getAndParseBuckets()
 	if err != nil {
 		return Bucket{}, err
 	}
 	for _, b := range buckets {
-		if b.Name == name {
+		if b.Name == name && bucketVisible(user, b) {
 			return b, nil
 		}
 	}
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
-func getDirEntries(path string) ([]model.Obj, error) {
-	ctx := context.Background()
+func getDirEntries(user *model.User, path string) ([]model.Obj, error) {
+	if !canRead(user, path, model.AclList) {
+		return nil, gofakes3.ErrNoSuchKey
+	}
+	ctx := withUser(user)
 	meta, _ := op.GetNearestMeta(path)
 	fi, err := fs.Get(context.WithValue(ctx, "meta", meta), path, &fs.GetArgs{})
 	if errs.IsNotFoundError(err) {
@@ -148,13 +153,28 @@ func prefixParser(p *gofakes3.Prefix) (path, remaining string) {
 // 	}
 // }
 
-func authlistResolver() map[string]string {
-	s3accesskeyid := setting.GetStr(conf.S3AccessKeyId)
-	s3secretaccesskey := setting.GetStr(conf.S3SecretAccessKey)
-	if s3accesskeyid == "" && s3secretaccesskey == "" {
-		return nil
+// objectMeta keeps the metadata of the obj in the headers of the request, the others like the signature are dropped
+func objectMeta(meta map[string]string) map[string]string {
+	res := make(map[string]string)
+	for k, v := range meta {
+		switch k {
+		case "Content-Type", "Content-Encoding", "Content-Disposition", "Content-Language", "Cache-Control", "Expires":
+			res[k] = v
+		default:
+			if strings.HasPrefix(k, "X-Amz-Meta-") {
+				res[k] = v
+			}
+		}
+	}
+	return res
+}
+
+func headerMeta(header http.Header) map[string]string {
+	meta := make(map[string]string, len(header))
+	for k, v := range header {
+		if len(v) > 0 {
+			meta[k] = v[0]
+		}
 	}
-	authList := make(map[string]string)
-	authList[s3accesskeyid] = s3secretaccesskey
-	return authList
+	return meta
 }
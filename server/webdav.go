@@ -7,11 +7,15 @@ import (
 	"path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/middlewares"
 	"github.com/alist-org/alist/v3/server/webdav"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -22,12 +26,12 @@ var handler *webdav.Handler
 func WebDav(dav *gin.RouterGroup) {
 	handler = &webdav.Handler{
 		Prefix:     path.Join(conf.URL.Path, "/dav"),
-		LockSystem: webdav.NewMemLS(),
+		LockSystem: webdav.NewDBLS(),
 		Logger: func(request *http.Request, err error) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
 	}
-	dav.Use(WebDAVAuth)
+	dav.Use(WebDAVAuth, middlewares.RateLimit(ratelimit.WebDAV))
 	dav.Any("/*path", ServeWebDAV)
 	dav.Any("", ServeWebDAV)
 	dav.Handle("PROPFIND", "/*path", ServeWebDAV)
@@ -43,12 +47,17 @@ func WebDav(dav *gin.RouterGroup) {
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	ctx := context.WithValue(c.Request.Context(), "user", user)
+	ctx = audit.WithClient(ctx, c.ClientIP(), model.AuditProtocolWebdav)
 	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
 
 func WebDAVAuth(c *gin.Context) {
 	guest, _ := op.GetGuest()
 	username, password, ok := c.Request.BasicAuth()
+	if bt := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); !ok && strings.HasPrefix(bt, model.ApiTokenPrefix) {
+		// the api token can also be the bearer token
+		password, ok = bt, true
+	}
 	if !ok {
 		bt := c.GetHeader("Authorization")
 		log.Debugf("[webdav auth] token: %s", bt)
@@ -78,8 +87,8 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	user, err := op.GetUserByName(username)
-	if err != nil || user.ValidateRawPassword(password) != nil {
+	user, err := webdavUser(username, password)
+	if err != nil {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
@@ -112,3 +121,23 @@ func WebDAVAuth(c *gin.Context) {
 	c.Set("user", user)
 	c.Next()
 }
+
+// webdavUser checks the basic auth, the password can be an api token of the user
+func webdavUser(username, password string) (*model.User, error) {
+	if strings.HasPrefix(password, model.ApiTokenPrefix) {
+		user, err := op.GetUserByApiToken(password)
+		if err != nil {
+			return nil, err
+		}
+		// the username is omitted with the bearer token
+		if username != "" && username != user.Username {
+			return nil, errs.InvalidApiToken
+		}
+		return user, nil
+	}
+	user, err := op.GetUserByName(username)
+	if err != nil {
+		return nil, err
+	}
+	return user, user.ValidateRawPassword(password)
+}
@@ -0,0 +1,190 @@
+package webdav
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// NewDBLS returns a LockSystem which stores the locks in the database,
+// so the locks survive restarts and are shared by all the instances using the same database.
+// The expired locks are ignored once they expire and swept periodically.
+func NewDBLS() LockSystem {
+	cron.NewCron(10 * time.Minute).Do(func() {
+		if err := db.DeleteExpiredWebdavLocks(time.Now()); err != nil {
+			log.Errorf("failed sweep expired webdav locks: %+v", err)
+		}
+	})
+	return &dbLS{held: make(map[string]bool)}
+}
+
+type dbLS struct {
+	mu sync.Mutex
+	// held are the tokens held by the Confirm calls of this instance,
+	// a lock is only held during a single request, so it's not shared
+	held map[string]bool
+}
+
+func toDetails(l *model.WebdavLock) LockDetails {
+	return LockDetails{
+		Root:      l.Root,
+		Duration:  l.Duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+	}
+}
+
+func (m *dbLS) Confirm(now time.Time, name0, name1 string, conditions ...Condition) (func(), error) {
+	var tokens []string
+	for _, c := range conditions {
+		if c.Token != "" {
+			tokens = append(tokens, c.Token)
+		}
+	}
+	locks, err := db.GetWebdavLocksByTokens(tokens, now)
+	if err != nil {
+		return nil, err
+	}
+	byToken := make(map[string]*model.WebdavLock, len(locks))
+	for i := range locks {
+		byToken[locks[i].Token] = &locks[i]
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n0, n1 string
+	if name0 != "" {
+		if n0 = m.lookup(byToken, slashClean(name0), conditions...); n0 == "" {
+			return nil, ErrConfirmationFailed
+		}
+	}
+	if name1 != "" {
+		if n1 = m.lookup(byToken, slashClean(name1), conditions...); n1 == "" {
+			return nil, ErrConfirmationFailed
+		}
+	}
+
+	// Don't hold the same lock twice.
+	if n1 == n0 {
+		n1 = ""
+	}
+	for _, t := range []string{n0, n1} {
+		if t != "" {
+			m.held[t] = true
+		}
+	}
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.held, n0)
+		delete(m.held, n1)
+	}, nil
+}
+
+// lookup returns the token of the lock that locks the named resource, see memLS.lookup
+func (m *dbLS) lookup(byToken map[string]*model.WebdavLock, name string, conditions ...Condition) string {
+	for _, c := range conditions {
+		l := byToken[c.Token]
+		if l == nil || m.held[l.Token] {
+			continue
+		}
+		if name == l.Root {
+			return l.Token
+		}
+		if l.ZeroDepth {
+			continue
+		}
+		if l.Root == "/" || strings.HasPrefix(name, l.Root+"/") {
+			return l.Token
+		}
+	}
+	return ""
+}
+
+func (m *dbLS) Create(now time.Time, details LockDetails) (string, error) {
+	details.Root = slashClean(details.Root)
+	// the expired lock on the same root would break the unique constraint
+	if err := db.DeleteExpiredWebdavLocks(now); err != nil {
+		return "", err
+	}
+	locks, err := db.GetWebdavLocksOnPath(details.Root, !details.ZeroDepth, now)
+	if err != nil {
+		return "", err
+	}
+	if len(locks) > 0 {
+		return "", ErrLocked
+	}
+	l := &model.WebdavLock{
+		Token:     "opaquelocktoken:" + uuid.NewString(),
+		Root:      details.Root,
+		ZeroDepth: details.ZeroDepth,
+		OwnerXML:  details.OwnerXML,
+		Duration:  details.Duration,
+		Created:   now,
+	}
+	if l.Duration >= 0 {
+		l.Expiry = now.Add(l.Duration)
+	}
+	if err := db.CreateWebdavLock(l); err != nil {
+		// another instance may lock the same root in the meantime
+		if locks, _ := db.GetWebdavLocksOnPath(details.Root, false, now); len(locks) > 0 {
+			return "", ErrLocked
+		}
+		return "", err
+	}
+	return l.Token, nil
+}
+
+func (m *dbLS) Refresh(now time.Time, token string, duration time.Duration) (LockDetails, error) {
+	l, err := db.GetWebdavLockByToken(token, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return LockDetails{}, ErrNoSuchLock
+	}
+	if err != nil {
+		return LockDetails{}, err
+	}
+	if m.isHeld(token) {
+		return LockDetails{}, ErrLocked
+	}
+	l.Duration = duration
+	if l.Duration >= 0 {
+		l.Expiry = now.Add(l.Duration)
+	}
+	if err = db.UpdateWebdavLockExpiry(l); err != nil {
+		return LockDetails{}, err
+	}
+	return toDetails(l), nil
+}
+
+func (m *dbLS) Unlock(now time.Time, token string) error {
+	if m.isHeld(token) {
+		return ErrLocked
+	}
+	if _, err := db.GetWebdavLockByToken(token, now); errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoSuchLock
+	} else if err != nil {
+		return err
+	}
+	deleted, err := db.DeleteWebdavLockByToken(token)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNoSuchLock
+	}
+	return nil
+}
+
+func (m *dbLS) isHeld(token string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.held[token]
+}
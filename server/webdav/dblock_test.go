@@ -0,0 +1,83 @@
+package webdav
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestDBLS(t *testing.T) {
+	now := time.Now()
+	// two instances share the locks by the database
+	ls0, ls1 := NewDBLS(), NewDBLS()
+	token, err := ls0.Create(now, LockDetails{Root: "/a/b", Duration: time.Minute})
+	if err != nil {
+		t.Fatalf("failed create lock: %+v", err)
+	}
+	for _, d := range []LockDetails{
+		{Root: "/a/b", ZeroDepth: true, Duration: -1},
+		{Root: "/a/b/c", ZeroDepth: true, Duration: -1},
+		{Root: "/a", Duration: -1},
+	} {
+		if _, err = ls1.Create(now, d); err != ErrLocked {
+			t.Errorf("expect %s to be locked, got %v", d.Root, err)
+		}
+	}
+	sibling, err := ls1.Create(now, LockDetails{Root: "/a/bc", ZeroDepth: true, Duration: -1})
+	if err != nil {
+		t.Fatalf("failed create lock on the sibling: %+v", err)
+	}
+
+	if err = op.CheckWebdavLock("/a/b/c", false); !errors.Is(err, errs.Locked) {
+		t.Errorf("expect the child to be locked, got %v", err)
+	}
+	if err = op.CheckWebdavLock("/a", true, sibling); !errors.Is(err, errs.Locked) {
+		t.Errorf("expect the parent tree to be locked, got %v", err)
+	}
+	if err = op.CheckWebdavLock("/a/b/c", false, token); err != nil {
+		t.Errorf("expect the lock holder to write, got %v", err)
+	}
+
+	release, err := ls1.Confirm(now, "/a/b/c", "", Condition{Token: token})
+	if err != nil {
+		t.Fatalf("failed confirm: %+v", err)
+	}
+	if err = ls1.Unlock(now, token); err != ErrLocked {
+		t.Errorf("expect the held lock not to be unlocked, got %v", err)
+	}
+	release()
+	if _, err = ls1.Confirm(now, "/a/bc/d", "", Condition{Token: sibling}); err != ErrConfirmationFailed {
+		t.Errorf("expect the zero depth lock not to cover the child, got %v", err)
+	}
+
+	if _, err = ls1.Refresh(now, token, time.Hour); err != nil {
+		t.Fatalf("failed refresh: %+v", err)
+	}
+	if _, err = ls0.Create(now.Add(time.Minute*2), LockDetails{Root: "/a/b", Duration: -1}); err != ErrLocked {
+		t.Errorf("expect the refreshed lock to be alive, got %v", err)
+	}
+	// expired, so the root can be locked again
+	later := now.Add(time.Hour * 2)
+	if _, err = ls0.Create(later, LockDetails{Root: "/a/b", Duration: -1}); err != nil {
+		t.Errorf("failed create lock on the expired root: %+v", err)
+	}
+	if err = ls0.Unlock(later, token); err != ErrNoSuchLock {
+		t.Errorf("expect the expired lock to be gone, got %v", err)
+	}
+}
@@ -416,6 +416,10 @@ func (b *byExpiry) Pop() interface{} {
 
 const infiniteTimeout = -1
 
+// temporaryTimeout is the timeout of the locks created for a single request, they are refreshed
+// until the request is done, so that a persistent lock left by a crashed instance expires soon.
+const temporaryTimeout = time.Minute
+
 // parseTimeout parses the Timeout HTTP header, as per section 10.7. If s is
 // empty, an infiniteTimeout is returned.
 func parseTimeout(s string) (time.Duration, error) {
@@ -13,11 +13,16 @@ import (
 	"mime"
 	"net/http"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
 )
 
 // Proppatch describes a property update instruction as defined in RFC 4918.
@@ -162,13 +167,74 @@ var liveProps = map[xml.Name]struct {
 	},
 }
 
+// quotaProps are the protected properties defined by RFC 4331, they are only
+// reported for collections and are not returned by allprop.
+var quotaProps = map[xml.Name]bool{
+	{Space: "DAV:", Local: "quota-available-bytes"}: true,
+	{Space: "DAV:", Local: "quota-used-bytes"}:      true,
+}
+
+// win32LastModifiedTime is set by Windows Explorer after uploading a file,
+// it's applied to the modification time if the driver supports that.
+var win32LastModifiedTime = xml.Name{Space: "urn:schemas-microsoft-com:", Local: "Win32LastModifiedTime"}
+
+// findDeadProps returns the dead properties of resource name, they may have been
+// loaded into ctx by handlePropfind for all the resources walked.
+func findDeadProps(ctx context.Context, name string) (map[xml.Name]Property, error) {
+	var props []model.WebdavProp
+	if loaded, ok := ctx.Value("deadProps").(map[string][]model.WebdavProp); ok {
+		props = loaded[name]
+	} else {
+		var err error
+		if props, err = op.GetWebdavProps(name); err != nil {
+			return nil, err
+		}
+	}
+	deadProps := make(map[xml.Name]Property, len(props))
+	for _, p := range props {
+		pn := xml.Name{Space: p.Space, Local: p.Local}
+		deadProps[pn] = Property{XMLName: pn, Lang: p.Lang, InnerXML: []byte(p.InnerXML)}
+	}
+	return deadProps, nil
+}
+
+// findQuota returns the used and available bytes of collection name, the quota of the user
+// takes precedence over the space usage of the storage. ok is false if neither is known or limited.
+func findQuota(ctx context.Context, name string) (used, available int64, ok bool) {
+	details, err := fs.GetStorageDetails(ctx, name)
+	if err != nil && !errs.IsNotImplement(err) {
+		log.Warnf("failed get storage details of %s: %+v", name, err)
+	}
+	// the storages without a limit report no total space, they don't cap the quota
+	if details != nil && details.TotalSpace == 0 {
+		details = nil
+	}
+	user := ctx.Value("user").(*model.User)
+	if user.QuotaSize > 0 {
+		usage, err := op.GetUserUsage(user.ID)
+		if err != nil {
+			log.Errorf("failed get usage of user [%s]: %+v", user.Username, err)
+			return 0, 0, false
+		}
+		used, available = usage.UsedSize, max(user.QuotaSize-usage.UsedSize, 0)
+		if details != nil && details.FreeSpace < available {
+			available = details.FreeSpace
+		}
+		return used, available, true
+	}
+	if details == nil {
+		return 0, 0, false
+	}
+	return details.UsedSpace, details.FreeSpace, true
+}
+
 // TODO(nigeltao) merge props and allprop?
 
 // Props returns the status of the properties named pnames for resource name.
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
+func props(ctx context.Context, ls LockSystem, name string, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -180,23 +246,37 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 	//}
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// ??? what is this for?
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	deadProps, err = dph.DeadProps()
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
+	deadProps, err := findDeadProps(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 
 	pstatOK := Propstat{Status: http.StatusOK}
 	pstatNotFound := Propstat{Status: http.StatusNotFound}
+	quotaFound := false
+	var quotaUsed, quotaAvailable int64
 	for _, pn := range pnames {
 		// If this file has dead properties, check if they contain pn.
 		if dp, ok := deadProps[pn]; ok {
 			pstatOK.Props = append(pstatOK.Props, dp)
 			continue
 		}
+		if quotaProps[pn] && isDir {
+			if !quotaFound {
+				quotaUsed, quotaAvailable, quotaFound = findQuota(ctx, name)
+			}
+			if quotaFound {
+				v := quotaUsed
+				if pn.Local == "quota-available-bytes" {
+					v = quotaAvailable
+				}
+				pstatOK.Props = append(pstatOK.Props, Property{
+					XMLName:  pn,
+					InnerXML: []byte(strconv.FormatInt(v, 10)),
+				})
+				continue
+			}
+		}
 		// Otherwise, it must either be a live property or we don't know it.
 		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
 			innerXML, err := prop.findFn(ctx, ls, fi.GetName(), fi)
@@ -217,7 +297,7 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 }
 
 // Propnames returns the property names defined for resource name.
-func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, error) {
+func propnames(ctx context.Context, ls LockSystem, name string, fi model.Obj) ([]xml.Name, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -229,14 +309,10 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 	//}
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// ??? what is this for?
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	deadProps, err = dph.DeadProps()
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
+	deadProps, err := findDeadProps(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 
 	pnames := make([]xml.Name, 0, len(liveProps)+len(deadProps))
 	for pn, prop := range liveProps {
@@ -258,8 +334,8 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Name) ([]Propstat, error) {
-	pnames, err := propnames(ctx, ls, fi)
+func allprop(ctx context.Context, ls LockSystem, name string, fi model.Obj, include []xml.Name) ([]Propstat, error) {
+	pnames, err := propnames(ctx, ls, name, fi)
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +349,7 @@ func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Nam
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, ls, fi, pnames)
+	return props(ctx, ls, name, fi, pnames)
 }
 
 // Patch patches the properties of resource name. The return values are
@@ -283,7 +359,7 @@ func patch(ctx context.Context, ls LockSystem, name string, patches []Proppatch)
 loop:
 	for _, patch := range patches {
 		for _, p := range patch.Props {
-			if _, ok := liveProps[p.XMLName]; ok {
+			if _, ok := liveProps[p.XMLName]; ok || quotaProps[p.XMLName] {
 				conflict = true
 				break loop
 			}
@@ -299,7 +375,7 @@ loop:
 		}
 		for _, patch := range patches {
 			for _, p := range patch.Props {
-				if _, ok := liveProps[p.XMLName]; ok {
+				if _, ok := liveProps[p.XMLName]; ok || quotaProps[p.XMLName] {
 					pstatForbidden.Props = append(pstatForbidden.Props, Property{XMLName: p.XMLName})
 				} else {
 					pstatFailedDep.Props = append(pstatFailedDep.Props, Property{XMLName: p.XMLName})
@@ -309,36 +385,54 @@ loop:
 		return makePropstats(pstatForbidden, pstatFailedDep), nil
 	}
 
-	// ------------------------------------------------------------
-	//f, err := fs.OpenFile(ctx, name, os.O_RDWR, 0)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//defer f.Close()
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	ret, err := dph.Patch(patches)
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//	// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
-	//	// "The contents of the prop XML element must only list the names of
-	//	// properties to which the result in the status element applies."
-	//	for _, pstat := range ret {
-	//		for i, p := range pstat.Props {
-	//			pstat.Props[i] = Property{XMLName: p.XMLName}
-	//		}
-	//	}
-	//	return ret, nil
-	//}
-	// ------------------------------------------------------------
-
-	// The file doesn't implement the optional DeadPropsHolder interface, so
-	// all patches are forbidden.
-	pstat := Propstat{Status: http.StatusForbidden}
+	deadProps, err := findDeadProps(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	pstat := Propstat{Status: http.StatusOK}
 	for _, patch := range patches {
 		for _, p := range patch.Props {
+			// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
+			// "The contents of the prop XML element must only list the names of
+			// properties to which the result in the status element applies."
 			pstat.Props = append(pstat.Props, Property{XMLName: p.XMLName})
+			if patch.Remove {
+				delete(deadProps, p.XMLName)
+				continue
+			}
+			if p.XMLName == win32LastModifiedTime {
+				if t, err := http.ParseTime(strings.TrimSpace(string(p.InnerXML))); err == nil {
+					err = fs.SetModified(ctx, name, t)
+					if err == nil {
+						// the modification time is a live property now
+						delete(deadProps, p.XMLName)
+						continue
+					}
+					if !errs.IsNotImplement(err) {
+						return nil, err
+					}
+				}
+			}
+			deadProps[p.XMLName] = p
+		}
+	}
+	pnames := make([]xml.Name, 0, len(deadProps))
+	for pn := range deadProps {
+		pnames = append(pnames, pn)
+	}
+	sort.Slice(pnames, func(i, j int) bool {
+		if pnames[i].Space != pnames[j].Space {
+			return pnames[i].Space < pnames[j].Space
 		}
+		return pnames[i].Local < pnames[j].Local
+	})
+	props := make([]model.WebdavProp, 0, len(pnames))
+	for _, pn := range pnames {
+		dp := deadProps[pn]
+		props = append(props, model.WebdavProp{Space: pn.Space, Local: pn.Local, Lang: dp.Lang, InnerXML: string(dp.InnerXML)})
+	}
+	if err = op.SaveWebdavProps(name, props); err != nil {
+		return nil, err
 	}
 	return []Propstat{pstat}, nil
 }
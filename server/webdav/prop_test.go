@@ -0,0 +1,64 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestDeadProps(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 1})
+	fi := &model.Object{Name: "a.txt", Size: 1}
+	tag := xml.Name{Space: "http://example.com/ns", Local: "tag"}
+	pstats, err := patch(ctx, nil, "/a.txt", []Proppatch{{
+		Props: []Property{{XMLName: tag, InnerXML: []byte("red")}},
+	}})
+	if err != nil {
+		t.Fatalf("failed patch: %+v", err)
+	}
+	if len(pstats) != 1 || pstats[0].Status != http.StatusOK {
+		t.Fatalf("expect the patch to succeed, got %+v", pstats)
+	}
+	pstats, err = patch(ctx, nil, "/a.txt", []Proppatch{{
+		Props: []Property{{XMLName: xml.Name{Space: "DAV:", Local: "quota-used-bytes"}}},
+	}})
+	if err != nil {
+		t.Fatalf("failed patch: %+v", err)
+	}
+	if len(pstats) != 1 || pstats[0].Status != http.StatusForbidden {
+		t.Errorf("expect the quota property to be protected, got %+v", pstats)
+	}
+
+	pstats, err = props(ctx, nil, "/a.txt", fi, []xml.Name{tag})
+	if err != nil {
+		t.Fatalf("failed props: %+v", err)
+	}
+	if len(pstats) != 1 || pstats[0].Status != http.StatusOK || string(pstats[0].Props[0].InnerXML) != "red" {
+		t.Errorf("expect the dead property to be stored, got %+v", pstats)
+	}
+	pnames, err := propnames(ctx, nil, "/a.txt", fi)
+	if err != nil {
+		t.Fatalf("failed propnames: %+v", err)
+	}
+	found := false
+	for _, pn := range pnames {
+		found = found || pn == tag
+	}
+	if !found {
+		t.Errorf("expect the dead property in the names")
+	}
+
+	if _, err = patch(ctx, nil, "/a.txt", []Proppatch{{Remove: true, Props: []Property{{XMLName: tag}}}}); err != nil {
+		t.Fatalf("failed patch: %+v", err)
+	}
+	pstats, err = props(ctx, nil, "/a.txt", fi, []xml.Name{tag})
+	if err != nil {
+		t.Fatalf("failed props: %+v", err)
+	}
+	if len(pstats) != 1 || pstats[0].Status != http.StatusNotFound {
+		t.Errorf("expect the dead property to be removed, got %+v", pstats)
+	}
+}
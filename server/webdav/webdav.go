@@ -18,9 +18,11 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/stream"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -99,7 +101,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) lock(now time.Time, root string) (token string, status int, err error) {
 	token, err = h.LockSystem.Create(now, LockDetails{
 		Root:      root,
-		Duration:  infiniteTimeout,
+		Duration:  temporaryTimeout,
 		ZeroDepth: true,
 	})
 	if err != nil {
@@ -111,6 +113,30 @@ func (h *Handler) lock(now time.Time, root string) (token string, status int, er
 	return token, 0, nil
 }
 
+// keepLocks refreshes the temporary locks of a long request, e.g. a large upload, until stop is called
+func (h *Handler) keepLocks(tokens ...string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(temporaryTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				for _, token := range tokens {
+					if token != "" {
+						_, _ = h.LockSystem.Refresh(now, token, temporaryTimeout)
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
+
 func (h *Handler) confirmLocks(r *http.Request, src, dst string) (release func(), status int, err error) {
 	hdr := r.Header.Get("If")
 	if hdr == "" {
@@ -136,7 +162,9 @@ func (h *Handler) confirmLocks(r *http.Request, src, dst string) (release func()
 			}
 		}
 
+		stop := h.keepLocks(srcToken, dstToken)
 		return func() {
+			stop()
 			if dstToken != "" {
 				h.LockSystem.Unlock(now, dstToken)
 			}
@@ -167,6 +195,12 @@ func (h *Handler) confirmLocks(r *http.Request, src, dst string) (release func()
 			if err != nil {
 				return nil, status, err
 			}
+			// the locks are created with the full path
+			user := r.Context().Value("user").(*model.User)
+			lsrc, err = user.JoinPath(lsrc)
+			if err != nil {
+				return nil, http.StatusForbidden, err
+			}
 		}
 		release, err = h.LockSystem.Confirm(time.Now(), lsrc, dst, l.conditions...)
 		if err == ErrConfirmationFailed {
@@ -223,6 +257,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if err != nil {
 		return http.StatusForbidden, err
 	}
+	if !aclAllowed(user, reqPath, model.AclRead) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
 	if err != nil {
 		return http.StatusNotFound, err
@@ -244,9 +281,11 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	downProxyUrl := storage.GetStorage().DownProxyUrl
 	if storage.GetStorage().WebdavNative() || (storage.GetStorage().WebdavProxy() && downProxyUrl == "") {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{Header: r.Header, HttpReq: r})
+		audit.Download(ctx, reqPath, fi, err)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
+		r = r.WithContext(stream.WithDownloadLimiter(ctx, op.DownloadLimiter(ctx, storage)))
 		err = common.Proxy(w, r, link, fi)
 		if err != nil {
 			log.Errorf("webdav proxy error: %+v", err)
@@ -261,6 +300,7 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		http.Redirect(w, r, u, http.StatusFound)
 	} else {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{IP: utils.ClientIP(r), Header: r.Header, HttpReq: r})
+		audit.Download(ctx, reqPath, fi, err)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -274,17 +314,20 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status i
 	if err != nil {
 		return status, err
 	}
+	ctx := r.Context()
+	user := ctx.Value("user").(*model.User)
+	reqPath, err = user.JoinPath(reqPath)
+	if err != nil {
+		return 403, err
+	}
 	release, status, err := h.confirmLocks(r, reqPath, "")
 	if err != nil {
 		return status, err
 	}
 	defer release()
 
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath, err = user.JoinPath(reqPath)
-	if err != nil {
-		return 403, err
+	if !aclAllowed(user, reqPath, model.AclDelete) {
+		return http.StatusForbidden, errs.PermissionDenied
 	}
 	// TODO: return MultiStatus where appropriate.
 
@@ -312,6 +355,12 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if reqPath == "" {
 		return http.StatusMethodNotAllowed, nil
 	}
+	ctx := r.Context()
+	user := ctx.Value("user").(*model.User)
+	reqPath, err = user.JoinPath(reqPath)
+	if err != nil {
+		return http.StatusForbidden, err
+	}
 	release, status, err := h.confirmLocks(r, reqPath, "")
 	if err != nil {
 		return status, err
@@ -319,11 +368,8 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	defer release()
 	// TODO(rost): Support the If-Match, If-None-Match headers? See bradfitz'
 	// comments in http.checkEtag.
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath, err = user.JoinPath(reqPath)
-	if err != nil {
-		return http.StatusForbidden, err
+	if !aclAllowed(user, reqPath, model.AclWrite) {
+		return http.StatusForbidden, errs.PermissionDenied
 	}
 	obj := model.Object{
 		Name:     path.Base(reqPath),
@@ -346,6 +392,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 
 	_ = r.Body.Close()
 	_ = stream.Close()
+	if errors.Is(err, errs.QuotaExceeded) {
+		return http.StatusInsufficientStorage, err
+	}
 	// TODO(rost): Returning 405 Method Not Allowed might not be appropriate.
 	if err != nil {
 		return http.StatusMethodNotAllowed, err
@@ -367,17 +416,20 @@ func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status in
 	if err != nil {
 		return status, err
 	}
+	ctx := r.Context()
+	user := ctx.Value("user").(*model.User)
+	reqPath, err = user.JoinPath(reqPath)
+	if err != nil {
+		return 403, err
+	}
 	release, status, err := h.confirmLocks(r, reqPath, "")
 	if err != nil {
 		return status, err
 	}
 	defer release()
 
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath, err = user.JoinPath(reqPath)
-	if err != nil {
-		return 403, err
+	if !aclAllowed(user, reqPath, model.AclWrite) {
+		return http.StatusForbidden, errs.PermissionDenied
 	}
 
 	if r.ContentLength > 0 {
@@ -447,6 +499,13 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return 403, err
 	}
+	srcPerm := model.AclRead
+	if r.Method == "MOVE" {
+		srcPerm = model.AclDelete
+	}
+	if !aclAllowed(user, src, srcPerm) || !aclAllowed(user, dst, model.AclWrite) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 
 	if r.Method == "COPY" {
 		// Section 7.5.1 says that a COPY only needs to lock the destination,
@@ -627,6 +686,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return 403, err
 	}
+	if !aclAllowed(user, reqPath, 0) || !op.AclVisible(user, reqPath) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
 	if err != nil {
 		if errs.IsNotFoundError(err) {
@@ -645,6 +707,14 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return status, err
 	}
+	if depth != 0 {
+		// load the dead properties of all the resources to be walked at once
+		deadProps, err := op.GetWebdavPropsUnder(reqPath)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		ctx = context.WithValue(ctx, "deadProps", deadProps)
+	}
 
 	mw := multistatusWriter{w: w}
 
@@ -654,7 +724,7 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 		}
 		var pstats []Propstat
 		if pf.Propname != nil {
-			pnames, err := propnames(ctx, h.LockSystem, info)
+			pnames, err := propnames(ctx, h.LockSystem, reqPath, info)
 			if err != nil {
 				return err
 			}
@@ -664,9 +734,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		}
 		if err != nil {
 			return err
@@ -694,18 +764,21 @@ func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (statu
 	if err != nil {
 		return status, err
 	}
-	release, status, err := h.confirmLocks(r, reqPath, "")
-	if err != nil {
-		return status, err
-	}
-	defer release()
-
 	ctx := r.Context()
 	user := ctx.Value("user").(*model.User)
 	reqPath, err = user.JoinPath(reqPath)
 	if err != nil {
 		return 403, err
 	}
+	if !aclAllowed(user, reqPath, model.AclWrite) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
+	release, status, err := h.confirmLocks(r, reqPath, "")
+	if err != nil {
+		return status, err
+	}
+	defer release()
+
 	if _, err := fs.Get(ctx, reqPath, &fs.GetArgs{}); err != nil {
 		if errs.IsObjectNotFound(err) {
 			return http.StatusNotFound, err
@@ -824,3 +897,9 @@ var (
 	errUnsupportedLockInfo     = errors.New("webdav: unsupported lock info")
 	errUnsupportedMethod       = errors.New("webdav: unsupported method")
 )
+
+// aclAllowed checks the acl rules of the user's groups on the path,
+// the webdav permission is required besides perm once a rule applies
+func aclAllowed(user *model.User, reqPath string, perm int32) bool {
+	return op.AclAllowed(user, reqPath, model.AclWebdav|perm, true)
+}